@@ -0,0 +1,100 @@
+// Package calendar writes iCalendar (RFC 5545) feeds that calendar apps can
+// subscribe to.
+package calendar
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType of a feed written by Write.
+const ContentType = "text/calendar; charset=utf-8"
+
+// Event is an entry of a feed. Calendar apps match events by UID, so it must
+// not change between fetches.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	// Cancelled events are kept in the feed so subscribers remove them.
+	Cancelled bool
+	// Updated is when the event last changed; Sequence counts the changes
+	// so subscribers replace their copy.
+	Updated  time.Time
+	Sequence int
+}
+
+// Write writes a calendar named name with the events.
+func Write(w io.Writer, name string, events []Event) error {
+	b := bufio.NewWriter(w)
+	line := func(property, value string) {
+		writeLine(b, property+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Stand//Stand API//PT")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escape(name))
+	for _, event := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(event.UID))
+		line("DTSTAMP", formatTime(event.Updated))
+		line("LAST-MODIFIED", formatTime(event.Updated))
+		line("SEQUENCE", strconv.Itoa(event.Sequence))
+		line("DTSTART", formatTime(event.Start))
+		line("DTEND", formatTime(event.End))
+		line("SUMMARY", escape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escape(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION", escape(event.Location))
+		}
+		if event.Cancelled {
+			line("STATUS", "CANCELLED")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.Flush()
+}
+
+// formatTime writes a time in UTC, e.g. 20260315T143000Z.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes the characters with a meaning in TEXT values.
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// writeLine ends a content line with CRLF, folding it so no line is longer
+// than 75 octets without splitting a UTF-8 sequence.
+func writeLine(b *bufio.Writer, content string) {
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// Continuation lines start with a space.
+		limit = 74
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/Stand/catalog"
+	"github.com/Stand/config"
+	"github.com/Stand/db"
+	"github.com/Stand/sqlstore"
+)
+
+const catalogUsage = "usage: stand_api catalog normalize [-dry-run]"
+
+// runCatalog implements the "catalog" command, printing the normalisation
+// report to stdout as JSON.
+func runCatalog(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "normalize" {
+		return errors.New(catalogUsage)
+	}
+
+	fs := flag.NewFlagSet("catalog normalize", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report the changes without updating vehicles")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New(catalogUsage)
+	}
+
+	if cfg.Database.Driver == "memory" {
+		return errors.New("the in-memory store has no stored vehicles to normalise")
+	}
+
+	conn, dialect := db.InitDB(cfg.Database, cfg.AutoMigrate)
+	defer conn.Close()
+	repos := sqlstore.New(conn, dialect).Repositories()
+
+	report, err := catalog.Normalize(context.Background(), repos, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
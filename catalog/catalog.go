@@ -0,0 +1,34 @@
+// Package catalog holds the bundled brand and model catalogue and the
+// normalisation of vehicles stored before the catalogue existed.
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Stand/models"
+)
+
+// bundledCatalog seeds the in-memory store; migration 0010 and the
+// catalogue additions generated after it insert the same entries into the
+// database (see BundledSeed).
+//
+//go:embed catalog.json
+var bundledCatalog []byte
+
+// Entry is a brand of the bundled catalogue with its models.
+type Entry struct {
+	models.Brand
+	Models []models.BrandModel `json:"models"`
+}
+
+// Bundled returns the bundled catalogue. It panics if the embedded file is
+// invalid, which is a build error rather than a runtime condition.
+func Bundled() []Entry {
+	var entries []Entry
+	if err := json.Unmarshal(bundledCatalog, &entries); err != nil {
+		panic(fmt.Sprintf("invalid bundled catalogue: %v", err))
+	}
+	return entries
+}
@@ -0,0 +1,66 @@
+//go:build ignore
+
+// gen_seed writes a new migration for every dialect that seeds what
+// catalog.json has and the existing migrations do not. Migrations that have
+// shipped are never rewritten, so databases that already ran them get the
+// additions too.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Stand/catalog"
+	"github.com/Stand/db"
+	"github.com/Stand/migrations"
+)
+
+func main() {
+	bundled := catalog.BundledSeed()
+	dialects := []string{"postgres", "sqlite"}
+	missing := map[string]catalog.Seed{}
+	var next int64
+	for _, dialect := range dialects {
+		fsys, err := migrations.For(dialect)
+		if err != nil {
+			log.Fatal(err)
+		}
+		list, err := db.LoadMigrations(fsys)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		seeded := catalog.Seed{}
+		for _, migration := range list {
+			next = max(next, migration.Version+1)
+			seed, err := catalog.ParseSeed(migration.Up)
+			if err != nil {
+				log.Fatalf("%s migration %d: %v", dialect, migration.Version, err)
+			}
+			seeded.Merge(seed)
+		}
+
+		if removed := seeded.Minus(bundled); !removed.Empty() {
+			log.Fatalf("%s: the migrations seed rows that catalog.json no longer has, which only a hand-written migration can remove: %v", dialect, removed)
+		}
+		missing[dialect] = bundled.Minus(seeded)
+	}
+
+	for _, dialect := range dialects {
+		seed := missing[dialect]
+		if seed.Empty() {
+			fmt.Printf("%s: the migrations already seed catalog.json\n", dialect)
+			continue
+		}
+		base := filepath.Join("..", "migrations", dialect, fmt.Sprintf("%04d_catalog_additions", next))
+		if err := os.WriteFile(base+".up.sql", []byte(seed.UpSQL()), 0o644); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(base+".down.sql", []byte(seed.DownSQL()), 0o644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("wrote %s.up.sql and %s.down.sql\n", base, base)
+	}
+}
@@ -0,0 +1,93 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/Stand/models"
+)
+
+// Report is the outcome of Normalize.
+type Report struct {
+	Vehicles  int         `json:"vehicles"`
+	Linked    int         `json:"linked"`
+	Unchanged int         `json:"unchanged"`
+	Unmatched []Unmatched `json:"unmatched"`
+	DryRun    bool        `json:"dry_run"`
+}
+
+// Unmatched is a brand and model pair that is not in the catalogue, with the
+// vehicles that carry it.
+type Unmatched struct {
+	Brand      string `json:"brand"`
+	Model      string `json:"model"`
+	Reason     string `json:"reason"`
+	VehicleIDs []int  `json:"vehicle_ids"`
+}
+
+// Normalize links the stored vehicles to the catalogue, replacing aliases
+// with the catalogue names. Vehicles whose brand or model is not in the
+// catalogue are left as they are and reported, so that the catalogue can be
+// extended and the command run again. With dryRun nothing is written.
+func Normalize(ctx context.Context, repos models.Repositories, dryRun bool) (*Report, error) {
+	catalog, err := repos.Catalog.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vehicles, err := repos.Vehicles.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Vehicles: len(vehicles), Unmatched: []Unmatched{}, DryRun: dryRun}
+	unmatched := map[[2]string]*Unmatched{}
+	for _, vehicle := range vehicles {
+		resolved := vehicle
+		resolved.BrandID, resolved.ModelID = nil, nil
+		if err := catalog.Resolve(&resolved); err != nil {
+			key := [2]string{models.CatalogKey(vehicle.Brand), models.CatalogKey(vehicle.Model)}
+			entry := unmatched[key]
+			if entry == nil {
+				entry = &Unmatched{Brand: vehicle.Brand, Model: vehicle.Model, Reason: err.Error()}
+				unmatched[key] = entry
+			}
+			entry.VehicleIDs = append(entry.VehicleIDs, vehicle.ID)
+			continue
+		}
+
+		if sameLink(&vehicle, &resolved) {
+			report.Unchanged++
+			continue
+		}
+		if !dryRun {
+			err := repos.Vehicles.Update(ctx, &resolved)
+			if errors.Is(err, models.ErrNotFound) {
+				// Deleted since it was read.
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		report.Linked++
+	}
+
+	for _, entry := range unmatched {
+		report.Unmatched = append(report.Unmatched, *entry)
+	}
+	sort.Slice(report.Unmatched, func(i, j int) bool {
+		a, b := report.Unmatched[i], report.Unmatched[j]
+		if a.Brand != b.Brand {
+			return a.Brand < b.Brand
+		}
+		return a.Model < b.Model
+	})
+	return report, nil
+}
+
+func sameLink(stored, resolved *models.Vehicle) bool {
+	return stored.BrandID != nil && stored.ModelID != nil &&
+		*stored.BrandID == *resolved.BrandID && *stored.ModelID == *resolved.ModelID &&
+		stored.Brand == resolved.Brand && stored.Model == resolved.Model
+}
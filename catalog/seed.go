@@ -0,0 +1,202 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//go:generate go run gen_seed.go
+
+// SeedHeader starts the migrations that go generate writes for additions to
+// catalog.json.
+const SeedHeader = "-- Catalogue additions, generated from catalog/catalog.json by `go generate ./catalog`.\n"
+
+// Seed holds rows of the catalogue tables keyed by table. Rows name their
+// brand and model rather than hold IDs, so they work with either kind of
+// ID column: brands (name), brand_aliases (brand, alias), vehicle_models
+// (brand, model) and vehicle_model_aliases (brand, model, alias).
+type Seed map[string][][]string
+
+// seedColumns is the number of names in a row of each catalogue table, in
+// the order the tables are seeded.
+var seedColumns = []struct {
+	table   string
+	columns int
+}{
+	{"brands", 1},
+	{"brand_aliases", 2},
+	{"vehicle_models", 2},
+	{"vehicle_model_aliases", 3},
+}
+
+// BundledSeed returns the rows of catalog.json.
+func BundledSeed() Seed {
+	seed := Seed{}
+	for _, entry := range Bundled() {
+		seed.add("brands", entry.Name)
+		for _, alias := range entry.Aliases {
+			seed.add("brand_aliases", entry.Name, alias)
+		}
+		for _, model := range entry.Models {
+			seed.add("vehicle_models", entry.Name, model.Name)
+			for _, alias := range model.Aliases {
+				seed.add("vehicle_model_aliases", entry.Name, model.Name, alias)
+			}
+		}
+	}
+	return seed
+}
+
+func (s Seed) add(table string, names ...string) {
+	s[table] = append(s[table], names)
+}
+
+var (
+	insertRe = regexp.MustCompile(`INSERT INTO (\w+)`)
+	rowRe    = regexp.MustCompile(`\(\s*'(?:[^']|'')*'(?:\s*,\s*'(?:[^']|'')*')*\s*\)`)
+	quotedRe = regexp.MustCompile(`'((?:[^']|'')*)'`)
+)
+
+// ParseSeed returns the catalogue rows that the SQL of a migration inserts:
+// the rows of string literals following an INSERT INTO a catalogue table.
+func ParseSeed(sql string) (Seed, error) {
+	inserts := insertRe.FindAllStringSubmatchIndex(sql, -1)
+	seed := Seed{}
+	for _, row := range rowRe.FindAllStringIndex(sql, -1) {
+		table := ""
+		for _, insert := range inserts {
+			if insert[0] < row[0] {
+				table = sql[insert[2]:insert[3]]
+			}
+		}
+
+		columns := 0
+		for _, seeded := range seedColumns {
+			if seeded.table == table {
+				columns = seeded.columns
+			}
+		}
+		if columns == 0 {
+			continue
+		}
+
+		var names []string
+		for _, quoted := range quotedRe.FindAllStringSubmatch(sql[row[0]:row[1]], -1) {
+			names = append(names, strings.ReplaceAll(quoted[1], "''", "'"))
+		}
+		if len(names) != columns {
+			return nil, fmt.Errorf("%s row %s has %d values, want %d", table, sql[row[0]:row[1]], len(names), columns)
+		}
+		seed.add(table, names...)
+	}
+	return seed, nil
+}
+
+// Merge adds the rows of other to s.
+func (s Seed) Merge(other Seed) {
+	for table, rows := range other {
+		s[table] = append(s[table], rows...)
+	}
+}
+
+// Minus returns the rows of s that are not in other, in their order in s.
+func (s Seed) Minus(other Seed) Seed {
+	diff := Seed{}
+	for table, rows := range s {
+		have := map[string]bool{}
+		for _, row := range other[table] {
+			have[strings.Join(row, "\x00")] = true
+		}
+		for _, row := range rows {
+			if !have[strings.Join(row, "\x00")] {
+				diff.add(table, row...)
+			}
+		}
+	}
+	return diff
+}
+
+// Empty reports whether s has no rows.
+func (s Seed) Empty() bool {
+	for _, rows := range s {
+		if len(rows) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// UpSQL returns the statements inserting the rows, which work on every
+// dialect. Aliases and models are inserted for brands and models matched by
+// name, so they may belong to ones seeded by an earlier migration.
+func (s Seed) UpSQL() string {
+	var sql strings.Builder
+	sql.WriteString(SeedHeader)
+	if rows := s["brands"]; len(rows) > 0 {
+		fmt.Fprintf(&sql, "INSERT INTO brands (name) VALUES\n%s;\n", valuesList(rows, "    "))
+	}
+	if rows := s["brand_aliases"]; len(rows) > 0 {
+		fmt.Fprintf(&sql, "\nINSERT INTO brand_aliases (brand_id, alias)\n"+
+			"SELECT b.id, a.column2 FROM brands b JOIN (VALUES\n%s\n) a ON a.column1 = b.name;\n", valuesList(rows, "    "))
+	}
+	if rows := s["vehicle_models"]; len(rows) > 0 {
+		fmt.Fprintf(&sql, "\nINSERT INTO vehicle_models (brand_id, name)\n"+
+			"SELECT b.id, m.column2 FROM brands b JOIN (VALUES\n%s\n) m ON m.column1 = b.name;\n", valuesList(rows, "    "))
+	}
+	if rows := s["vehicle_model_aliases"]; len(rows) > 0 {
+		fmt.Fprintf(&sql, "\nINSERT INTO vehicle_model_aliases (model_id, alias)\n"+
+			"SELECT m.id, a.column3 FROM vehicle_models m JOIN brands b ON b.id = m.brand_id JOIN (VALUES\n%s\n"+
+			") a ON a.column1 = b.name AND a.column2 = m.name;\n", valuesList(rows, "    "))
+	}
+	return sql.String()
+}
+
+// DownSQL returns the statements deleting the rows UpSQL inserts, in the
+// reverse order.
+func (s Seed) DownSQL() string {
+	var statements []string
+	if rows := s["vehicle_model_aliases"]; len(rows) > 0 {
+		statements = append(statements, fmt.Sprintf("DELETE FROM vehicle_model_aliases WHERE EXISTS (\n"+
+			"    SELECT 1 FROM vehicle_models m JOIN brands b ON b.id = m.brand_id JOIN (VALUES\n%s\n"+
+			"    ) a ON a.column1 = b.name AND a.column2 = m.name\n"+
+			"    WHERE m.id = vehicle_model_aliases.model_id AND a.column3 = vehicle_model_aliases.alias\n);\n", valuesList(rows, "        ")))
+	}
+	if rows := s["vehicle_models"]; len(rows) > 0 {
+		statements = append(statements, fmt.Sprintf("DELETE FROM vehicle_models WHERE EXISTS (\n"+
+			"    SELECT 1 FROM brands b JOIN (VALUES\n%s\n"+
+			"    ) m ON m.column1 = b.name\n"+
+			"    WHERE b.id = vehicle_models.brand_id AND m.column2 = vehicle_models.name\n);\n", valuesList(rows, "        ")))
+	}
+	if rows := s["brand_aliases"]; len(rows) > 0 {
+		statements = append(statements, fmt.Sprintf("DELETE FROM brand_aliases WHERE EXISTS (\n"+
+			"    SELECT 1 FROM brands b JOIN (VALUES\n%s\n"+
+			"    ) a ON a.column1 = b.name\n"+
+			"    WHERE b.id = brand_aliases.brand_id AND a.column2 = brand_aliases.alias\n);\n", valuesList(rows, "        ")))
+	}
+	if rows := s["brands"]; len(rows) > 0 {
+		var names []string
+		for _, row := range rows {
+			names = append(names, quote(row[0]))
+		}
+		statements = append(statements, fmt.Sprintf("DELETE FROM brands WHERE name IN (%s);\n", strings.Join(names, ", ")))
+	}
+	return strings.Join(statements, "\n")
+}
+
+// valuesList formats rows as the rows of a VALUES list, one per line.
+func valuesList(rows [][]string, indent string) string {
+	formatted := make([]string, len(rows))
+	for i, row := range rows {
+		quoted := make([]string, len(row))
+		for j, name := range row {
+			quoted[j] = quote(name)
+		}
+		formatted[i] = "(" + strings.Join(quoted, ", ") + ")"
+	}
+	return indent + strings.Join(formatted, ",\n"+indent)
+}
+
+func quote(name string) string {
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
@@ -0,0 +1,61 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/Stand/db"
+	"github.com/Stand/migrations"
+)
+
+// TestMigrationSeed checks that the migrations of every dialect together
+// seed exactly catalog.json, so the database and the in-memory store start
+// from the same catalogue.
+func TestMigrationSeed(t *testing.T) {
+	bundled := BundledSeed()
+	for _, dialect := range []string{"postgres", "sqlite"} {
+		fsys, err := migrations.For(dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list, err := db.LoadMigrations(fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seeded := Seed{}
+		for _, migration := range list {
+			seed, err := ParseSeed(migration.Up)
+			if err != nil {
+				t.Fatalf("%s migration %d: %v", dialect, migration.Version, err)
+			}
+			seeded.Merge(seed)
+		}
+
+		if missing := bundled.Minus(seeded); !missing.Empty() {
+			t.Errorf("%s: catalog.json has rows no migration seeds; run go generate ./catalog: %v", dialect, missing)
+		}
+		if removed := seeded.Minus(bundled); !removed.Empty() {
+			t.Errorf("%s: the migrations seed rows catalog.json does not have: %v", dialect, removed)
+		}
+	}
+}
+
+func TestSeedSQL(t *testing.T) {
+	seed := Seed{
+		"brands":                {{"Lynk & Co"}},
+		"brand_aliases":         {{"Lynk & Co", "lynk"}},
+		"vehicle_models":        {{"Lynk & Co", "01"}, {"Peugeot", "e-2008"}},
+		"vehicle_model_aliases": {{"Peugeot", "e-2008", "e2008"}, {"Peugeot", "208", "2'08"}},
+	}
+
+	parsed, err := ParseSeed(seed.UpSQL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := seed.Minus(parsed); !diff.Empty() {
+		t.Errorf("ParseSeed(UpSQL()) lost %v", diff)
+	}
+	if diff := parsed.Minus(seed); !diff.Empty() {
+		t.Errorf("ParseSeed(UpSQL()) added %v", diff)
+	}
+}
@@ -0,0 +1,656 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Config is the typed application configuration. Values are resolved with the
+// precedence defaults < config file < environment variables < command-line flags.
+type Config struct {
+	ListenAddr string `yaml:"listen_addr" toml:"listen_addr"`
+	// LogLevel is the least severe message the API server logs: routine
+	// messages and the request log are info, failed background jobs and
+	// media storage errors warn or error. At debug gin runs in debug mode.
+	LogLevel    string    `yaml:"log_level" toml:"log_level"`
+	AutoMigrate bool      `yaml:"auto_migrate" toml:"auto_migrate"`
+	Database    Database  `yaml:"database" toml:"database"`
+	Timeouts    Timeouts  `yaml:"timeouts" toml:"timeouts"`
+	Jobs        Jobs      `yaml:"jobs" toml:"jobs"`
+	Pricing     Pricing   `yaml:"pricing" toml:"pricing"`
+	Media       Media     `yaml:"media" toml:"media"`
+	Feed        Feed      `yaml:"feed" toml:"feed"`
+	Documents   Documents `yaml:"documents" toml:"documents"`
+	// VINTable is an optional JSON file extending the bundled VIN lookup table.
+	VINTable string `yaml:"vin_table" toml:"vin_table"`
+
+	// Args holds the positional arguments left after flag parsing (e.g. "migrate up").
+	Args []string `yaml:"-" toml:"-"`
+}
+
+type Database struct {
+	Driver          string   `yaml:"driver" toml:"driver"`
+	Path            string   `yaml:"path" toml:"path"`
+	Host            string   `yaml:"host" toml:"host"`
+	Port            int      `yaml:"port" toml:"port"`
+	User            string   `yaml:"user" toml:"user"`
+	Password        string   `yaml:"password" toml:"password"`
+	PasswordFile    string   `yaml:"password_file" toml:"password_file"`
+	Name            string   `yaml:"name" toml:"name"`
+	SSLMode         string   `yaml:"sslmode" toml:"sslmode"`
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+}
+
+// Timeouts are the deadlines applied to each repository call made while
+// serving a request, on top of cancellation when the client disconnects.
+type Timeouts struct {
+	Read  Duration `yaml:"read" toml:"read"`
+	Write Duration `yaml:"write" toml:"write"`
+}
+
+// Jobs configures the background workers started with the API.
+type Jobs struct {
+	// ReservationExpiry is how often expired reservations are released.
+	ReservationExpiry Duration `yaml:"reservation_expiry" toml:"reservation_expiry"`
+	// PriceRules is how often the price rules are applied to the stock.
+	PriceRules Duration `yaml:"price_rules" toml:"price_rules"`
+	// FeedExport is how often the syndication feed is written to Feed.Dir.
+	FeedExport Duration `yaml:"feed_export" toml:"feed_export"`
+	// DocumentExpiry is how often expiring vehicle documents are flagged.
+	DocumentExpiry Duration `yaml:"document_expiry" toml:"document_expiry"`
+}
+
+// Pricing configures the automatic asking price reductions. With no rules
+// prices are never changed automatically.
+type Pricing struct {
+	Rules []PriceRule `yaml:"rules" toml:"rules"`
+	// FloorMarkup is the percentage over a vehicle's cost below which its
+	// price is never reduced.
+	FloorMarkup float64 `yaml:"floor_markup" toml:"floor_markup"`
+}
+
+// PriceRule lowers the asking price by Percent once a vehicle has been in
+// stock for AfterDays.
+type PriceRule struct {
+	AfterDays int     `yaml:"after_days" toml:"after_days"`
+	Percent   float64 `yaml:"percent" toml:"percent"`
+}
+
+// Media configures where vehicle photos and their thumbnails are stored.
+type Media struct {
+	// Storage is "local" (files under Path) or "s3" (an S3-compatible bucket).
+	Storage string `yaml:"storage" toml:"storage"`
+	Path    string `yaml:"path" toml:"path"`
+	// MaxUploadSize is the largest accepted upload request, in bytes.
+	MaxUploadSize int64 `yaml:"max_upload_size" toml:"max_upload_size"`
+	S3            S3    `yaml:"s3" toml:"s3"`
+}
+
+// S3 locates the bucket of an S3-compatible service such as MinIO.
+type S3 struct {
+	Endpoint      string `yaml:"endpoint" toml:"endpoint"`
+	Bucket        string `yaml:"bucket" toml:"bucket"`
+	Region        string `yaml:"region" toml:"region"`
+	AccessKey     string `yaml:"access_key" toml:"access_key"`
+	SecretKey     string `yaml:"secret_key" toml:"secret_key"`
+	SecretKeyFile string `yaml:"secret_key_file" toml:"secret_key_file"`
+	UseSSL        bool   `yaml:"use_ssl" toml:"use_ssl"`
+}
+
+// Feed configures the syndication feed of the available vehicles.
+type Feed struct {
+	// Dir receives the scheduled exports; empty disables them.
+	Dir string `yaml:"dir" toml:"dir"`
+	// Formats are the registered feed formats exported to Dir.
+	Formats []string `yaml:"formats" toml:"formats"`
+	// BaseURL is the public address of the API, used to make the photo URLs
+	// absolute (e.g. "https://stand.example.pt").
+	BaseURL string `yaml:"base_url" toml:"base_url"`
+}
+
+// Documents configures the vehicle document vault.
+type Documents struct {
+	// ExpiryWindow is how long before they expire documents are flagged.
+	ExpiryWindow Duration `yaml:"expiry_window" toml:"expiry_window"`
+}
+
+// Duration is a time.Duration that can be read from strings such as "5m".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+func Default() Config {
+	return Config{
+		ListenAddr:  ":8080",
+		LogLevel:    "info",
+		AutoMigrate: true,
+		Database: Database{
+			Driver:          "postgres",
+			Path:            "stand_automovel.db",
+			Host:            "db",
+			Port:            5432,
+			User:            "postgres",
+			Password:        "postgres",
+			Name:            "stand_automovel",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration{30 * time.Minute},
+		},
+		Timeouts: Timeouts{
+			Read:  Duration{5 * time.Second},
+			Write: Duration{10 * time.Second},
+		},
+		Media: Media{
+			Storage:       "local",
+			Path:          "uploads",
+			MaxUploadSize: 32 << 20,
+			S3: S3{
+				Region: "us-east-1",
+				UseSSL: true,
+			},
+		},
+		Feed: Feed{
+			Dir:     "feeds",
+			Formats: []string{"xml", "json"},
+		},
+		Jobs: Jobs{
+			ReservationExpiry: Duration{time.Minute},
+			PriceRules:        Duration{24 * time.Hour},
+			FeedExport:        Duration{time.Hour},
+			DocumentExpiry:    Duration{24 * time.Hour},
+		},
+		Documents: Documents{
+			ExpiryWindow: Duration{30 * 24 * time.Hour},
+		},
+	}
+}
+
+// setting binds one configuration value to its environment variable and flag.
+// Secret settings can also be read from a file named by <ENV>_FILE or -<flag>-file.
+type setting struct {
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	apply  func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{env: "LISTEN_ADDR", flag: "listen", usage: "HTTP listen address", apply: func(c *Config, v string) error {
+		c.ListenAddr = v
+		return nil
+	}},
+	{env: "LOG_LEVEL", flag: "log-level", usage: "least severe message the server logs (debug, info, warn, error)", apply: func(c *Config, v string) error {
+		c.LogLevel = strings.ToLower(v)
+		return nil
+	}},
+	{env: "AUTO_MIGRATE", flag: "auto-migrate", usage: "apply pending migrations at startup", apply: func(c *Config, v string) error {
+		return parseBool(&c.AutoMigrate, v)
+	}},
+	{env: "DB_DRIVER", flag: "db-driver", usage: "storage backend (postgres, sqlite, memory)", apply: func(c *Config, v string) error {
+		c.Database.Driver = strings.ToLower(v)
+		return nil
+	}},
+	{env: "DB_PATH", flag: "db-path", usage: "SQLite database file", apply: func(c *Config, v string) error {
+		c.Database.Path = v
+		return nil
+	}},
+	{env: "DB_HOST", flag: "db-host", usage: "database host", apply: func(c *Config, v string) error {
+		c.Database.Host = v
+		return nil
+	}},
+	{env: "DB_PORT", flag: "db-port", usage: "database port", apply: func(c *Config, v string) error {
+		return parseInt(&c.Database.Port, v)
+	}},
+	{env: "DB_USER", flag: "db-user", usage: "database user", apply: func(c *Config, v string) error {
+		c.Database.User = v
+		return nil
+	}},
+	{env: "DB_PASSWORD", flag: "db-password", usage: "database password", secret: true, apply: func(c *Config, v string) error {
+		c.Database.Password = v
+		return nil
+	}},
+	{env: "DB_NAME", flag: "db-name", usage: "database name", apply: func(c *Config, v string) error {
+		c.Database.Name = v
+		return nil
+	}},
+	{env: "DB_SSLMODE", flag: "db-sslmode", usage: "database sslmode", apply: func(c *Config, v string) error {
+		c.Database.SSLMode = v
+		return nil
+	}},
+	{env: "DB_MAX_OPEN_CONNS", flag: "db-max-open-conns", usage: "maximum open database connections", apply: func(c *Config, v string) error {
+		return parseInt(&c.Database.MaxOpenConns, v)
+	}},
+	{env: "DB_MAX_IDLE_CONNS", flag: "db-max-idle-conns", usage: "maximum idle database connections", apply: func(c *Config, v string) error {
+		return parseInt(&c.Database.MaxIdleConns, v)
+	}},
+	{env: "DB_CONN_MAX_LIFETIME", flag: "db-conn-max-lifetime", usage: "maximum lifetime of a database connection", apply: func(c *Config, v string) error {
+		return c.Database.ConnMaxLifetime.UnmarshalText([]byte(v))
+	}},
+	{env: "VIN_TABLE", flag: "vin-table", usage: "JSON file extending the bundled VIN lookup table", apply: func(c *Config, v string) error {
+		c.VINTable = v
+		return nil
+	}},
+	{env: "MEDIA_STORAGE", flag: "media-storage", usage: "media storage backend (local, s3)", apply: func(c *Config, v string) error {
+		c.Media.Storage = strings.ToLower(v)
+		return nil
+	}},
+	{env: "MEDIA_PATH", flag: "media-path", usage: "directory of the local media storage", apply: func(c *Config, v string) error {
+		c.Media.Path = v
+		return nil
+	}},
+	{env: "MEDIA_MAX_UPLOAD_SIZE", flag: "media-max-upload-size", usage: "largest media upload request in bytes", apply: func(c *Config, v string) error {
+		return parseInt64(&c.Media.MaxUploadSize, v)
+	}},
+	{env: "MEDIA_S3_ENDPOINT", flag: "media-s3-endpoint", usage: "S3 endpoint host[:port]", apply: func(c *Config, v string) error {
+		c.Media.S3.Endpoint = v
+		return nil
+	}},
+	{env: "MEDIA_S3_BUCKET", flag: "media-s3-bucket", usage: "S3 bucket for media", apply: func(c *Config, v string) error {
+		c.Media.S3.Bucket = v
+		return nil
+	}},
+	{env: "MEDIA_S3_REGION", flag: "media-s3-region", usage: "S3 region", apply: func(c *Config, v string) error {
+		c.Media.S3.Region = v
+		return nil
+	}},
+	{env: "MEDIA_S3_ACCESS_KEY", flag: "media-s3-access-key", usage: "S3 access key", apply: func(c *Config, v string) error {
+		c.Media.S3.AccessKey = v
+		return nil
+	}},
+	{env: "MEDIA_S3_SECRET_KEY", flag: "media-s3-secret-key", usage: "S3 secret key", secret: true, apply: func(c *Config, v string) error {
+		c.Media.S3.SecretKey = v
+		return nil
+	}},
+	{env: "MEDIA_S3_USE_SSL", flag: "media-s3-use-ssl", usage: "connect to S3 over HTTPS", apply: func(c *Config, v string) error {
+		return parseBool(&c.Media.S3.UseSSL, v)
+	}},
+	{env: "TIMEOUT_READ", flag: "timeout-read", usage: "deadline for read operations", apply: func(c *Config, v string) error {
+		return c.Timeouts.Read.UnmarshalText([]byte(v))
+	}},
+	{env: "TIMEOUT_WRITE", flag: "timeout-write", usage: "deadline for write operations", apply: func(c *Config, v string) error {
+		return c.Timeouts.Write.UnmarshalText([]byte(v))
+	}},
+	{env: "JOB_RESERVATION_EXPIRY", flag: "job-reservation-expiry", usage: "interval between releases of expired reservations", apply: func(c *Config, v string) error {
+		return c.Jobs.ReservationExpiry.UnmarshalText([]byte(v))
+	}},
+	{env: "JOB_PRICE_RULES", flag: "job-price-rules", usage: "interval between applications of the price rules", apply: func(c *Config, v string) error {
+		return c.Jobs.PriceRules.UnmarshalText([]byte(v))
+	}},
+	{env: "JOB_FEED_EXPORT", flag: "job-feed-export", usage: "interval between exports of the syndication feed", apply: func(c *Config, v string) error {
+		return c.Jobs.FeedExport.UnmarshalText([]byte(v))
+	}},
+	{env: "JOB_DOCUMENT_EXPIRY", flag: "job-document-expiry", usage: "interval between checks for expiring vehicle documents", apply: func(c *Config, v string) error {
+		return c.Jobs.DocumentExpiry.UnmarshalText([]byte(v))
+	}},
+	{env: "DOCUMENT_EXPIRY_WINDOW", flag: "document-expiry-window", usage: "how long before they expire vehicle documents are flagged (e.g. 720h)", apply: func(c *Config, v string) error {
+		return c.Documents.ExpiryWindow.UnmarshalText([]byte(v))
+	}},
+	{env: "PRICE_RULES", flag: "price-rules", usage: "price reductions as DAYS:PERCENT pairs (e.g. 30:5,60:10)", apply: func(c *Config, v string) error {
+		return parsePriceRules(&c.Pricing.Rules, v)
+	}},
+	{env: "PRICE_FLOOR_MARKUP", flag: "price-floor-markup", usage: "percentage over cost below which prices are never reduced", apply: func(c *Config, v string) error {
+		return parseFloat(&c.Pricing.FloorMarkup, v)
+	}},
+	{env: "FEED_DIR", flag: "feed-dir", usage: "directory of the scheduled feed exports (empty disables them)", apply: func(c *Config, v string) error {
+		c.Feed.Dir = v
+		return nil
+	}},
+	{env: "FEED_FORMATS", flag: "feed-formats", usage: "comma-separated feed formats to export (e.g. xml,json)", apply: func(c *Config, v string) error {
+		c.Feed.Formats = nil
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				c.Feed.Formats = append(c.Feed.Formats, name)
+			}
+		}
+		return nil
+	}},
+	{env: "FEED_BASE_URL", flag: "feed-base-url", usage: "public URL of the API used in the feed photo links", apply: func(c *Config, v string) error {
+		c.Feed.BaseURL = v
+		return nil
+	}},
+}
+
+// Load builds the configuration from defaults, the optional config file
+// (-config or STAND_CONFIG, .yaml/.yml/.toml), environment variables and args.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("stand_api", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("STAND_CONFIG"), "path to a YAML or TOML config file")
+
+	flagValues := map[string]*string{}
+	for _, s := range settings {
+		flagValues[s.flag] = fs.String(s.flag, "", s.usage)
+		if s.secret {
+			flagValues[s.flag+"-file"] = fs.String(s.flag+"-file", "", "file containing the "+s.usage)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	if *configPath != "" {
+		if err := loadFile(&cfg, *configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Secret files set in the config file take precedence over inline secrets.
+	if cfg.Database.PasswordFile != "" {
+		secret, err := readSecret(cfg.Database.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Database.Password = secret
+	}
+	if cfg.Media.S3.SecretKeyFile != "" {
+		secret, err := readSecret(cfg.Media.S3.SecretKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Media.S3.SecretKey = secret
+	}
+
+	for _, s := range settings {
+		if err := applyEnv(&cfg, s); err != nil {
+			return nil, err
+		}
+	}
+
+	visited := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { visited[f.Name] = true })
+
+	for _, s := range settings {
+		if visited[s.flag] {
+			if err := s.apply(&cfg, *flagValues[s.flag]); err != nil {
+				return nil, fmt.Errorf("invalid value for -%s: %w", s.flag, err)
+			}
+		}
+		if s.secret && visited[s.flag+"-file"] {
+			secret, err := readSecret(*flagValues[s.flag+"-file"])
+			if err != nil {
+				return nil, err
+			}
+			if err := s.apply(&cfg, secret); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	cfg.Args = fs.Args()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.ListenAddr == "" {
+		errs = append(errs, errors.New("listen address must not be empty"))
+	}
+
+	validLevel := false
+	for _, level := range logLevels {
+		if c.LogLevel == level {
+			validLevel = true
+		}
+	}
+	if !validLevel {
+		errs = append(errs, fmt.Errorf("log level must be one of %s", strings.Join(logLevels, ", ")))
+	}
+
+	switch c.Database.Driver {
+	case "postgres":
+		if c.Database.Host == "" {
+			errs = append(errs, errors.New("database host must not be empty"))
+		}
+		if c.Database.Port <= 0 || c.Database.Port > 65535 {
+			errs = append(errs, fmt.Errorf("database port %d is out of range", c.Database.Port))
+		}
+		if c.Database.User == "" {
+			errs = append(errs, errors.New("database user must not be empty"))
+		}
+		if c.Database.Name == "" {
+			errs = append(errs, errors.New("database name must not be empty"))
+		}
+	case "sqlite":
+		if c.Database.Path == "" {
+			errs = append(errs, errors.New("database path must not be empty"))
+		}
+	case "memory":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported database driver %q", c.Database.Driver))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database pool sizes must not be negative"))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("database max idle connections must not exceed max open connections"))
+	}
+	if c.Database.ConnMaxLifetime.Duration < 0 {
+		errs = append(errs, errors.New("database connection max lifetime must not be negative"))
+	}
+
+	switch c.Media.Storage {
+	case "local":
+		if c.Media.Path == "" {
+			errs = append(errs, errors.New("media path must not be empty"))
+		}
+	case "s3":
+		if c.Media.S3.Endpoint == "" || c.Media.S3.Bucket == "" {
+			errs = append(errs, errors.New("media S3 endpoint and bucket must not be empty"))
+		}
+		if c.Media.S3.AccessKey == "" || c.Media.S3.SecretKey == "" {
+			errs = append(errs, errors.New("media S3 access and secret keys must not be empty"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported media storage %q", c.Media.Storage))
+	}
+	if c.Media.MaxUploadSize <= 0 {
+		errs = append(errs, errors.New("media max upload size must be positive"))
+	}
+
+	if c.Timeouts.Read.Duration <= 0 || c.Timeouts.Write.Duration <= 0 {
+		errs = append(errs, errors.New("read and write timeouts must be positive"))
+	}
+
+	if c.Jobs.ReservationExpiry.Duration <= 0 {
+		errs = append(errs, errors.New("reservation expiry interval must be positive"))
+	}
+	if c.Jobs.PriceRules.Duration <= 0 {
+		errs = append(errs, errors.New("price rules interval must be positive"))
+	}
+	if c.Jobs.FeedExport.Duration <= 0 {
+		errs = append(errs, errors.New("feed export interval must be positive"))
+	}
+	if c.Jobs.DocumentExpiry.Duration <= 0 {
+		errs = append(errs, errors.New("document expiry interval must be positive"))
+	}
+	if c.Documents.ExpiryWindow.Duration <= 0 {
+		errs = append(errs, errors.New("document expiry window must be positive"))
+	}
+
+	if c.Feed.Dir != "" && len(c.Feed.Formats) == 0 {
+		errs = append(errs, errors.New("feed export needs at least one format"))
+	}
+	if c.Feed.BaseURL != "" {
+		if u, err := url.Parse(c.Feed.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("feed base URL %q must be an absolute http or https URL", c.Feed.BaseURL))
+		}
+	}
+
+	for _, rule := range c.Pricing.Rules {
+		if rule.AfterDays <= 0 {
+			errs = append(errs, fmt.Errorf("price rule after %d days: days must be positive", rule.AfterDays))
+		}
+		if rule.Percent <= 0 || rule.Percent >= 100 {
+			errs = append(errs, fmt.Errorf("price rule after %d days: percentage must be between 0 and 100", rule.AfterDays))
+		}
+	}
+	if c.Pricing.FloorMarkup < 0 {
+		errs = append(errs, errors.New("price floor markup must not be negative"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// DSN returns the lib/pq connection string for the Postgres settings.
+func (d Database) DSN() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     fmt.Sprintf("%s:%d", d.Host, d.Port),
+		Path:     "/" + d.Name,
+		RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+func loadFile(cfg *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, cfg)
+	case ".toml":
+		err = toml.Unmarshal(content, cfg)
+	default:
+		return fmt.Errorf("unsupported config file format %q", filepath.Ext(path))
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config, s setting) error {
+	if value, ok := os.LookupEnv(s.env); ok {
+		if err := s.apply(cfg, value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", s.env, err)
+		}
+	}
+
+	if !s.secret {
+		return nil
+	}
+
+	if path, ok := os.LookupEnv(s.env + "_FILE"); ok {
+		secret, err := readSecret(path)
+		if err != nil {
+			return err
+		}
+		return s.apply(cfg, secret)
+	}
+	return nil
+}
+
+func readSecret(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read secret file: %w", err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func parseInt(dst *int, value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*dst = parsed
+	return nil
+}
+
+func parseBool(dst *bool, value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*dst = parsed
+	return nil
+}
+
+func parseInt64(dst *int64, value string) error {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+	*dst = parsed
+	return nil
+}
+
+func parseFloat(dst *float64, value string) error {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*dst = parsed
+	return nil
+}
+
+// parsePriceRules reads a comma-separated list of DAYS:PERCENT pairs.
+func parsePriceRules(dst *[]PriceRule, value string) error {
+	rules := []PriceRule{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		days, percent, ok := strings.Cut(pair, ":")
+		if !ok {
+			return fmt.Errorf("price rule %q is not DAYS:PERCENT", pair)
+		}
+		var rule PriceRule
+		if err := parseInt(&rule.AfterDays, strings.TrimSpace(days)); err != nil {
+			return fmt.Errorf("price rule %q: %w", pair, err)
+		}
+		if err := parseFloat(&rule.Percent, strings.TrimSpace(percent)); err != nil {
+			return fmt.Errorf("price rule %q: %w", pair, err)
+		}
+		rules = append(rules, rule)
+	}
+	*dst = rules
+	return nil
+}
@@ -2,74 +2,65 @@ package db
 
 import (
 	"database/sql"
-	"fmt"
 	"log"
+	"log/slog"
 
-	_ "github.com/lib/pq"
+	"github.com/Stand/config"
+	"github.com/Stand/migrations"
 )
 
-var DB *sql.DB
+func InitDB(cfg config.Database, autoMigrate bool) (*sql.DB, Dialect) {
+	conn, dialect := Connect(cfg)
 
-func InitDB() {
-	var err error
+	if !autoMigrate {
+		return conn, dialect
+	}
 
-	connStr := "host=db user=postgres password=postgres dbname=stand_automovel port=5432 sslmode=disable"
-	DB, err = sql.Open("postgres", connStr)
+	migrator, err := NewMigrator(conn, dialect)
 	if err != nil {
-		log.Fatal("Erro ao conectar à base de dados:", err)
+		log.Fatal("Erro ao carregar as migrações:", err)
 	}
 
-	err = DB.Ping()
+	err = migrator.Up()
 	if err != nil {
-		log.Fatal("Erro ao fazer ping à base de dados:", err)
+		log.Fatal("Erro ao aplicar as migrações:", err)
 	}
 
-	fmt.Println("Conexão à base de dados PostgreSQL estabelecida com sucesso!")
-
-	createTables()
+	return conn, dialect
 }
 
-func createTables() {
-	createVehiclesTable := `
-    CREATE TABLE IF NOT EXISTS vehicles (
-        id SERIAL PRIMARY KEY,
-        type TEXT NOT NULL,
-        brand TEXT NOT NULL,
-        model TEXT NOT NULL,
-        year INTEGER NOT NULL,
-        motor TEXT NOT NULL,
-        status TEXT NOT NULL
-    )`
+// Connect opens and pings the configured database without touching the schema.
+func Connect(cfg config.Database) (*sql.DB, Dialect) {
+	dialect, err := DialectFor(cfg.Driver)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	_, err := DB.Exec(createVehiclesTable)
+	conn, err := sql.Open(dialect.DriverName(), dialect.DSN(cfg))
 	if err != nil {
-		panic("Could not create vehicle table: " + err.Error())
+		log.Fatal("Erro ao conectar à base de dados:", err)
 	}
 
-	createClientTable := `
-    CREATE TABLE IF NOT EXISTS clients (
-        id SERIAL PRIMARY KEY,
-        name TEXT NOT NULL,
-        email TEXT NOT NULL,
-        phone BIGINT NOT NULL
-    )`
+	conn.SetMaxOpenConns(cfg.MaxOpenConns)
+	conn.SetMaxIdleConns(cfg.MaxIdleConns)
+	conn.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration)
 
-	_, err = DB.Exec(createClientTable)
+	err = conn.Ping()
 	if err != nil {
-		panic("Could not create client table: " + err.Error())
+		log.Fatal("Erro ao fazer ping à base de dados:", err)
 	}
 
-	createSalesTable := `
-    CREATE TABLE IF NOT EXISTS sales (
-        id SERIAL PRIMARY KEY,
-        client_id INTEGER NOT NULL REFERENCES clients(id),
-        vehicle_id INTEGER NOT NULL REFERENCES vehicles(id) UNIQUE,
-        price REAL NOT NULL,
-        sale_date TIMESTAMP NOT NULL
-    )`
+	slog.Info("Conexão à base de dados estabelecida com sucesso", "dialect", dialect.Name())
 
-	_, err = DB.Exec(createSalesTable)
+	return conn, dialect
+}
+
+// NewMigrator returns a Migrator for the migrations bundled for dialect.
+func NewMigrator(conn *sql.DB, dialect Dialect) (*Migrator, error) {
+	fsys, err := migrations.For(dialect.Name())
 	if err != nil {
-		panic("Could not create sales table: " + err.Error())
+		return nil, err
 	}
+
+	return NewMigratorFS(conn, dialect, fsys)
 }
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/Stand/config"
+	"github.com/Stand/models"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect hides the differences between the supported storage backends.
+// Queries are written with Postgres-style $N placeholders and rebound per
+// dialect; RETURNING and ON CONFLICT upserts share the same syntax in both.
+type Dialect interface {
+	// Name is the config value selecting the dialect ("postgres", "sqlite").
+	Name() string
+	// DriverName is the database/sql driver used to open connections.
+	DriverName() string
+	DSN(cfg config.Database) string
+	// Rebind rewrites $N placeholders into the dialect's own syntax.
+	Rebind(query string) string
+	// Lock and Unlock take an exclusive lock identified by key for the
+	// lifetime of conn, used to serialise migrations across replicas.
+	Lock(ctx context.Context, conn *sql.Conn, key int64) error
+	Unlock(ctx context.Context, conn *sql.Conn, key int64) error
+	// Fold wraps a text expression so that it compares like models.Fold,
+	// lower-cased and without accents.
+	Fold(expr string) string
+	// LockInTx returns a statement taking one $1 text argument that, run
+	// inside a transaction, holds an exclusive lock on that key until the
+	// transaction ends. It is empty where write transactions are serialised
+	// anyway.
+	LockInTx() string
+	// ForUpdate is appended to a SELECT inside a transaction to lock the
+	// selected rows until the transaction ends.
+	ForUpdate() string
+	// IsForeignKeyViolation reports whether err was caused by a foreign key constraint.
+	IsForeignKeyViolation(err error) bool
+	// IsUniqueViolation reports whether err was caused by a unique constraint.
+	IsUniqueViolation(err error) bool
+}
+
+var placeholderRe = regexp.MustCompile(`\$(\d+)`)
+
+// SQLite's lower() only knows ASCII, so folding is done by a Go function.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("stand_fold", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch text := args[0].(type) {
+		case string:
+			return models.Fold(text), nil
+		case nil:
+			return nil, nil
+		default:
+			return models.Fold(fmt.Sprint(text)), nil
+		}
+	})
+}
+
+func DialectFor(name string) (Dialect, error) {
+	switch name {
+	case "postgres":
+		return postgresDialect{}, nil
+	case "sqlite":
+		return sqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", name)
+	}
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string       { return "postgres" }
+func (postgresDialect) DriverName() string { return "postgres" }
+
+func (postgresDialect) DSN(cfg config.Database) string {
+	return cfg.DSN()
+}
+
+func (postgresDialect) Rebind(query string) string {
+	return query
+}
+
+func (postgresDialect) Lock(ctx context.Context, conn *sql.Conn, key int64) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key)
+	return err
+}
+
+func (postgresDialect) Unlock(ctx context.Context, conn *sql.Conn, key int64) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key)
+	return err
+}
+
+func (postgresDialect) Fold(expr string) string {
+	return "translate(lower(" + expr + "), '" + models.FoldFrom + "', '" + models.FoldTo + "')"
+}
+
+func (postgresDialect) LockInTx() string {
+	return "SELECT pg_advisory_xact_lock(hashtext($1))"
+}
+
+func (postgresDialect) ForUpdate() string {
+	return " FOR UPDATE"
+}
+
+func (postgresDialect) IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+func (postgresDialect) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string       { return "sqlite" }
+func (sqliteDialect) DriverName() string { return "sqlite" }
+
+// DSN enables foreign keys (off by default in SQLite), waits on a busy
+// database instead of failing, and starts write transactions immediately.
+func (sqliteDialect) DSN(cfg config.Database) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+	return "file:" + cfg.Path + "?" + params.Encode()
+}
+
+func (sqliteDialect) Rebind(query string) string {
+	return placeholderRe.ReplaceAllString(query, "?$1")
+}
+
+// Lock is a no-op: a SQLite database is owned by a single process and the
+// file lock taken by each migration transaction is enough.
+func (sqliteDialect) Lock(ctx context.Context, conn *sql.Conn, key int64) error {
+	return nil
+}
+
+func (sqliteDialect) Unlock(ctx context.Context, conn *sql.Conn, key int64) error {
+	return nil
+}
+
+func (sqliteDialect) Fold(expr string) string {
+	return "stand_fold(" + expr + ")"
+}
+
+// LockInTx is empty for the same reason as ForUpdate.
+func (sqliteDialect) LockInTx() string {
+	return ""
+}
+
+// ForUpdate is empty: SQLite has no row locks, but transactions are opened
+// with BEGIN IMMEDIATE (see DSN) so concurrent writers are serialised.
+func (sqliteDialect) ForUpdate() string {
+	return ""
+}
+
+func (sqliteDialect) IsForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+func (sqliteDialect) IsUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/Stand/config"
+	"github.com/Stand/db"
+	"github.com/Stand/engine"
+	"github.com/Stand/sqlstore"
+)
+
+const engineUsage = "usage: stand_api engine migrate [-dry-run]"
+
+// runEngine implements the "engine" command, printing the migration report
+// to stdout as JSON.
+func runEngine(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "migrate" {
+		return errors.New(engineUsage)
+	}
+
+	fs := flag.NewFlagSet("engine migrate", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report the changes without updating vehicles")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New(engineUsage)
+	}
+
+	if cfg.Database.Driver == "memory" {
+		return errors.New("the in-memory store has no stored vehicles to migrate")
+	}
+
+	conn, dialect := db.InitDB(cfg.Database, cfg.AutoMigrate)
+	defer conn.Close()
+	repos := sqlstore.New(conn, dialect).Repositories()
+
+	report, err := engine.Migrate(context.Background(), repos.Vehicles, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
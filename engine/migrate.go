@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Stand/models"
+)
+
+// Report is the outcome of Migrate.
+type Report struct {
+	Vehicles  int        `json:"vehicles"`
+	Updated   int        `json:"updated"`
+	Unchanged int        `json:"unchanged"`
+	Unparsed  []Unparsed `json:"unparsed"`
+	DryRun    bool       `json:"dry_run"`
+}
+
+// Unparsed is a motor description that was not fully understood, with the
+// vehicles that carry it. Whatever was recognised is still filled in unless
+// Reason says why the vehicle could not be updated.
+type Unparsed struct {
+	Motor        string   `json:"motor"`
+	Unrecognised []string `json:"unrecognised"`
+	Reason       string   `json:"reason,omitempty"`
+	VehicleIDs   []int    `json:"vehicle_ids"`
+}
+
+// Migrate fills in the engine data of the stored vehicles from their motor
+// descriptions. Only fields left empty are filled in, so data entered by
+// hand is kept and the command can be run again after fixing descriptions.
+// With dryRun nothing is written, so vehicles the store would refuse, such
+// as ones whose model is not in the catalogue, are not reported.
+func Migrate(ctx context.Context, vehicles models.VehicleRepository, dryRun bool) (*Report, error) {
+	stored, err := vehicles.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Vehicles: len(stored), Unparsed: []Unparsed{}, DryRun: dryRun}
+	unparsed := map[[2]string]*Unparsed{}
+	addUnparsed := func(vehicle *models.Vehicle, parsed *Parsed, reason string) {
+		key := [2]string{vehicle.Motor, reason}
+		entry := unparsed[key]
+		if entry == nil {
+			entry = &Unparsed{Motor: vehicle.Motor, Unrecognised: parsed.Unrecognised, Reason: reason}
+			if entry.Unrecognised == nil {
+				entry.Unrecognised = []string{}
+			}
+			unparsed[key] = entry
+		}
+		entry.VehicleIDs = append(entry.VehicleIDs, vehicle.ID)
+	}
+
+	for _, vehicle := range stored {
+		parsed := Parse(vehicle.Motor)
+		filled := vehicle
+		fill(&filled, &parsed)
+
+		// Parsed values may not suit the vehicle, e.g. a displacement on an
+		// electric car; stored vehicles that were invalid already are not
+		// held back by it.
+		var reason string
+		invalid := filled.Validate()
+		switch {
+		case sameEngine(&vehicle, &filled):
+			report.Unchanged++
+		case invalid != nil && vehicle.Validate() == nil:
+			reason = invalid.Error()
+		case dryRun:
+			report.Updated++
+		default:
+			err := vehicles.Update(ctx, &filled)
+			var refused models.ValidationErrors
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				// Deleted since it was read.
+				continue
+			case errors.As(err, &refused):
+				reason = refused.Error()
+			case err != nil:
+				return nil, err
+			default:
+				report.Updated++
+			}
+		}
+
+		if len(parsed.Unrecognised) > 0 || reason != "" || (parsed.Empty() && strings.TrimSpace(vehicle.Motor) != "") {
+			addUnparsed(&vehicle, &parsed, reason)
+		}
+	}
+
+	for _, entry := range unparsed {
+		report.Unparsed = append(report.Unparsed, *entry)
+	}
+	sort.Slice(report.Unparsed, func(i, j int) bool {
+		a, b := report.Unparsed[i], report.Unparsed[j]
+		if a.Motor != b.Motor {
+			return a.Motor < b.Motor
+		}
+		return a.Reason < b.Reason
+	})
+	return report, nil
+}
+
+// Complete fills in the engine fields of a normalised vehicle that were left
+// empty from its motor description, as Migrate does for stored vehicles.
+// Nothing is filled in if the parsed values do not suit the vehicle, so
+// that only the data actually submitted is reported as invalid.
+func Complete(vehicle *models.Vehicle) {
+	parsed := Parse(vehicle.Motor)
+	filled := *vehicle
+	fill(&filled, &parsed)
+	if sameEngine(vehicle, &filled) || !engineValid(&filled) {
+		return
+	}
+	*vehicle = filled
+}
+
+// engineFields are the Vehicle fields that Parse can fill in.
+var engineFields = []string{"FuelType", "EngineCC", "PowerKW", "PowerHP", "CO2", "EmissionStandard", "BatteryKWh"}
+
+// engineValid reports whether the vehicle has no validation errors on its
+// engine fields.
+func engineValid(vehicle *models.Vehicle) bool {
+	errs, _ := vehicle.Validate().(models.ValidationErrors)
+	for _, err := range errs {
+		if slices.Contains(engineFields, err.Field) {
+			return false
+		}
+	}
+	return true
+}
+
+// fill sets the engine fields of the vehicle that are empty to the parsed
+// values. Power is only taken when neither figure was given.
+func fill(vehicle *models.Vehicle, parsed *Parsed) {
+	if vehicle.FuelType == "" {
+		vehicle.FuelType = parsed.FuelType
+	}
+	if vehicle.EngineCC == 0 && vehicle.FuelType != models.FuelElectric {
+		vehicle.EngineCC = parsed.EngineCC
+	}
+	if vehicle.PowerKW == 0 && vehicle.PowerHP == 0 {
+		vehicle.PowerKW, vehicle.PowerHP = parsed.PowerKW, parsed.PowerHP
+	}
+	if vehicle.CO2 == 0 {
+		vehicle.CO2 = parsed.CO2
+	}
+	if vehicle.EmissionStandard == "" {
+		vehicle.EmissionStandard = parsed.EmissionStandard
+	}
+	if vehicle.BatteryKWh == 0 {
+		vehicle.BatteryKWh = parsed.BatteryKWh
+	}
+}
+
+func sameEngine(a, b *models.Vehicle) bool {
+	return a.FuelType == b.FuelType && a.EngineCC == b.EngineCC && a.PowerKW == b.PowerKW &&
+		a.PowerHP == b.PowerHP && a.CO2 == b.CO2 && a.EmissionStandard == b.EmissionStandard &&
+		a.BatteryKWh == b.BatteryKWh
+}
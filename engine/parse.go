@@ -0,0 +1,194 @@
+// Package engine reads structured engine data out of free-text motor
+// descriptions such as "1.6 TDI 115cv" and fills it in on stored vehicles.
+package engine
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Stand/models"
+)
+
+// Parsed is the engine data recognised in a motor description; zero or
+// empty means not found.
+type Parsed struct {
+	FuelType         models.FuelType
+	EngineCC         int
+	PowerKW          int
+	PowerHP          int
+	CO2              int
+	EmissionStandard string
+	BatteryKWh       float64
+	// Unrecognised are the words that were not understood, in order.
+	Unrecognised []string
+}
+
+// Empty reports whether nothing was recognised.
+func (p *Parsed) Empty() bool {
+	return p.FuelType == "" && p.EngineCC == 0 && p.PowerKW == 0 && p.CO2 == 0 &&
+		p.EmissionStandard == "" && p.BatteryKWh == 0
+}
+
+// The quantities with a unit, matched on the folded description in this
+// order; kWh comes before kW and cc before litres.
+var (
+	batteryPattern = regexp.MustCompile(`\b(\d+(?:[.,]\d+)?)\s*kwh\b`)
+	kwPattern      = regexp.MustCompile(`\b(\d+)\s*kw\b`)
+	hpPattern      = regexp.MustCompile(`\b(\d+)\s*(?:cv|hp|ps|bhp|ch)\b`)
+	co2Pattern     = regexp.MustCompile(`\b(\d+)\s*g(?:\s*co2)?\s*/\s*km\b`)
+	euroPattern    = regexp.MustCompile(`\b(euro\s*-?\s*[1-6][a-d]?(?:-?temp)?)\b`)
+	ccPattern      = regexp.MustCompile(`\b(\d{2,5})\s*(?:cc|cm3)\b`)
+	litresPattern  = regexp.MustCompile(`\b(\d{1,2}[.,]\d)\s*l?\b`)
+	wholeLitres    = regexp.MustCompile(`\b(\d{1,2})\s*l\b`)
+	valvesPattern  = regexp.MustCompile(`^(\d{1,2}v|v\d{1,2})$`)
+	separators     = ",;/()+|"
+)
+
+// fuelWords maps the words and engine badges that give away the fuel type.
+var fuelWords = map[string]models.FuelType{
+	"diesel": models.FuelDiesel, "d": models.FuelDiesel, "gasoleo": models.FuelDiesel, "tdi": models.FuelDiesel, "tdci": models.FuelDiesel,
+	"cdi": models.FuelDiesel, "crdi": models.FuelDiesel, "hdi": models.FuelDiesel, "bluehdi": models.FuelDiesel,
+	"dci": models.FuelDiesel, "jtd": models.FuelDiesel, "jtdm": models.FuelDiesel, "multijet": models.FuelDiesel,
+	"cdti": models.FuelDiesel, "d4d": models.FuelDiesel, "d-4d": models.FuelDiesel, "dtec": models.FuelDiesel,
+	"i-dtec": models.FuelDiesel, "bluetec": models.FuelDiesel, "ecoblue": models.FuelDiesel, "skyactiv-d": models.FuelDiesel,
+
+	"gasolina": models.FuelPetrol, "petrol": models.FuelPetrol, "gasoline": models.FuelPetrol, "tsi": models.FuelPetrol,
+	"tfsi": models.FuelPetrol, "fsi": models.FuelPetrol, "tce": models.FuelPetrol, "puretech": models.FuelPetrol,
+	"ecoboost": models.FuelPetrol, "vti": models.FuelPetrol, "thp": models.FuelPetrol, "mpi": models.FuelPetrol,
+	"gdi": models.FuelPetrol, "t-gdi": models.FuelPetrol, "vvt-i": models.FuelPetrol, "vtec": models.FuelPetrol,
+	"i-vtec": models.FuelPetrol, "t-jet": models.FuelPetrol, "multiair": models.FuelPetrol, "skyactiv-g": models.FuelPetrol,
+
+	"hybrid": models.FuelHybrid, "hibrido": models.FuelHybrid, "hev": models.FuelHybrid, "mhev": models.FuelHybrid,
+	"e:hev": models.FuelHybrid, "phev": models.FuelPlugInHybrid, "plug-in": models.FuelPlugInHybrid, "e-hybrid": models.FuelPlugInHybrid,
+	"electric": models.FuelElectric, "eletrico": models.FuelElectric, "electrico": models.FuelElectric,
+	"ev": models.FuelElectric, "bev": models.FuelElectric,
+	"gpl": models.FuelLPG, "lpg": models.FuelLPG,
+	"gnc": models.FuelCNG, "cng": models.FuelCNG, "gnv": models.FuelCNG, "tgi": models.FuelCNG,
+}
+
+// ignoredWords carry no engine data but are common in descriptions.
+var ignoredWords = map[string]bool{
+	"turbo": true, "biturbo": true, "bi-turbo": true, "4x4": true, "4x2": true, "awd": true, "4wd": true,
+	"4matic": true, "xdrive": true, "quattro": true, "auto": true, "automatico": true, "manual": true,
+	"mild": true, "full": true, "l": true,
+}
+
+// Parse recognises the fuel type, displacement, power, CO2 emissions,
+// emission standard and battery capacity in a motor description. Only the
+// first value of each kind is used; repeated ones are left unrecognised.
+// Power given only in kW or hp is converted to the other.
+func Parse(motor string) Parsed {
+	var p Parsed
+	text := strings.ReplaceAll(models.Fold(motor), "³", "3")
+
+	text = extract(text, batteryPattern, func(value string) bool {
+		p.BatteryKWh, _ = strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		return p.BatteryKWh > 0
+	})
+	text = extract(text, kwPattern, func(value string) bool {
+		p.PowerKW, _ = strconv.Atoi(value)
+		return p.PowerKW > 0
+	})
+	text = extract(text, hpPattern, func(value string) bool {
+		p.PowerHP, _ = strconv.Atoi(value)
+		return p.PowerHP > 0
+	})
+	text = extract(text, co2Pattern, func(value string) bool {
+		p.CO2, _ = strconv.Atoi(value)
+		return true
+	})
+	text = extract(text, euroPattern, func(value string) bool {
+		p.EmissionStandard, _ = models.NormalizeEmissionStandard(value)
+		return p.EmissionStandard != ""
+	})
+	text = extract(text, ccPattern, func(value string) bool {
+		p.EngineCC, _ = strconv.Atoi(value)
+		return p.EngineCC >= 49
+	})
+	if p.EngineCC == 0 {
+		text = extract(text, litresPattern, func(value string) bool {
+			litres, _ := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+			p.EngineCC = int(litres * 1000)
+			return p.EngineCC > 0
+		})
+	}
+	if p.EngineCC == 0 {
+		text = extract(text, wholeLitres, func(value string) bool {
+			litres, _ := strconv.Atoi(value)
+			p.EngineCC = litres * 1000
+			return p.EngineCC > 0
+		})
+	}
+
+	switch {
+	case p.PowerKW == 0 && p.PowerHP > 0:
+		p.PowerKW = models.HPToKW(p.PowerHP)
+	case p.PowerHP == 0 && p.PowerKW > 0:
+		p.PowerHP = models.KWToHP(p.PowerKW)
+	}
+
+	fuels := map[models.FuelType]bool{}
+	var fuelTokens []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '\t' || strings.ContainsRune(separators, r)
+	}) {
+		if fuel, ok := fuelWords[word]; ok {
+			fuels[fuel] = true
+			fuelTokens = append(fuelTokens, word)
+			continue
+		}
+		if ignoredWords[word] || valvesPattern.MatchString(word) {
+			continue
+		}
+		p.Unrecognised = append(p.Unrecognised, word)
+	}
+
+	fuel, ok := combineFuels(fuels)
+	if !ok {
+		// Contradicting badges, such as "TDI TSI": report them instead.
+		p.Unrecognised = append(p.Unrecognised, fuelTokens...)
+	}
+	if fuel == "" && p.BatteryKWh > 0 && p.EngineCC == 0 {
+		fuel = models.FuelElectric
+	}
+	p.FuelType = fuel
+	return p
+}
+
+// extract applies set to the first match of pattern in text and, if set
+// accepts the value, removes the match from the text that is left.
+func extract(text string, pattern *regexp.Regexp, set func(value string) bool) string {
+	match := pattern.FindStringSubmatchIndex(text)
+	if match == nil || !set(text[match[2]:match[3]]) {
+		return text
+	}
+	return text[:match[0]] + " " + text[match[1]:]
+}
+
+// combineFuels decides the fuel type given by the words found: a hybrid
+// badge wins over the fuel of its engine or motor ("hybrid electric"), and
+// LPG or CNG over petrol. It fails when the words contradict each other.
+func combineFuels(fuels map[models.FuelType]bool) (models.FuelType, bool) {
+	switch {
+	case len(fuels) == 0:
+		return "", true
+	case fuels[models.FuelPlugInHybrid]:
+		return models.FuelPlugInHybrid, true
+	case fuels[models.FuelHybrid]:
+		return models.FuelHybrid, true
+	case fuels[models.FuelElectric]:
+		return models.FuelElectric, len(fuels) == 1
+	case fuels[models.FuelLPG] || fuels[models.FuelCNG]:
+		fuel := models.FuelLPG
+		if fuels[models.FuelCNG] {
+			fuel = models.FuelCNG
+		}
+		return fuel, !fuels[models.FuelDiesel] && !(fuels[models.FuelLPG] && fuels[models.FuelCNG])
+	case len(fuels) == 1:
+		for fuel := range fuels {
+			return fuel, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Stand/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		motor string
+		want  Parsed
+	}{
+		{"", Parsed{}},
+		{"1.6 TDI 115cv", Parsed{FuelType: models.FuelDiesel, EngineCC: 1600, PowerKW: 85, PowerHP: 115}},
+		{"2,0 TDI 150 CV Euro 6", Parsed{FuelType: models.FuelDiesel, EngineCC: 2000, PowerKW: 110, PowerHP: 150, EmissionStandard: "Euro 6"}},
+		{"1.0 TSI 81kW", Parsed{FuelType: models.FuelPetrol, EngineCC: 1000, PowerKW: 81, PowerHP: 110}},
+		{"1998cc 140kW 190hp", Parsed{EngineCC: 1998, PowerKW: 140, PowerHP: 190}},
+		{"1.5 dCi 115cv 109 g/km EURO6D-TEMP", Parsed{FuelType: models.FuelDiesel, EngineCC: 1500, PowerKW: 85, PowerHP: 115, CO2: 109, EmissionStandard: "Euro 6d-TEMP"}},
+		{"2.0 D 16V 4x4", Parsed{FuelType: models.FuelDiesel, EngineCC: 2000}},
+		{"16L Diesel", Parsed{FuelType: models.FuelDiesel, EngineCC: 16000}},
+		{"Elétrico 150kW 77 kWh", Parsed{FuelType: models.FuelElectric, PowerKW: 150, PowerHP: 204, BatteryKWh: 77}},
+		{"58kWh 150kW", Parsed{FuelType: models.FuelElectric, PowerKW: 150, PowerHP: 204, BatteryKWh: 58}},
+		{"1.8 Hybrid 122cv", Parsed{FuelType: models.FuelHybrid, EngineCC: 1800, PowerKW: 90, PowerHP: 122}},
+		{"1.4 TSI PHEV 13,1 kWh", Parsed{FuelType: models.FuelPlugInHybrid, EngineCC: 1400, BatteryKWh: 13.1}},
+		{"1.0 TCe GPL 100cv", Parsed{FuelType: models.FuelLPG, EngineCC: 1000, PowerKW: 74, PowerHP: 100}},
+		{"1.5 TGI 130 cv", Parsed{FuelType: models.FuelCNG, EngineCC: 1500, PowerKW: 96, PowerHP: 130}},
+		{"2.0 TDI TSI", Parsed{EngineCC: 2000, Unrecognised: []string{"tdi", "tsi"}}},
+		{"V8 Biturbo 5.0 special", Parsed{EngineCC: 5000, Unrecognised: []string{"special"}}},
+		{"1.6 110cv 120cv", Parsed{EngineCC: 1600, PowerKW: 81, PowerHP: 110, Unrecognised: []string{"120cv"}}},
+		{"Motor desconhecido", Parsed{Unrecognised: []string{"motor", "desconhecido"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.motor, func(t *testing.T) {
+			got := Parse(test.motor)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", test.motor, got, test.want)
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name    string
+		vehicle models.Vehicle
+		want    models.Vehicle
+	}{
+		{
+			name:    "fills empty fields",
+			vehicle: models.Vehicle{Type: "Car", Motor: "1.6 TDI 115cv"},
+			want:    models.Vehicle{Type: "Car", Motor: "1.6 TDI 115cv", FuelType: models.FuelDiesel, EngineCC: 1600, PowerKW: 85, PowerHP: 115},
+		},
+		{
+			name:    "keeps submitted fields",
+			vehicle: models.Vehicle{Type: "Car", Motor: "1.6 TDI 115cv", FuelType: models.FuelPetrol, PowerKW: 90, PowerHP: 122},
+			want:    models.Vehicle{Type: "Car", Motor: "1.6 TDI 115cv", FuelType: models.FuelPetrol, EngineCC: 1600, PowerKW: 90, PowerHP: 122},
+		},
+		{
+			name:    "skips values that do not suit the vehicle",
+			vehicle: models.Vehicle{Type: "Car", Motor: "2.0 77kWh"},
+			want:    models.Vehicle{Type: "Car", Motor: "2.0 77kWh"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.vehicle
+			Complete(&got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Complete() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
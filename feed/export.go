@@ -0,0 +1,104 @@
+package feed
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Stand/models"
+)
+
+// Exporter writes the full and incremental feeds to a directory in every
+// format, recording each run so that the next incremental feed starts
+// where this one ended.
+type Exporter struct {
+	Builder *Builder
+	Exports models.FeedRepository
+	Dir     string
+	Formats []Format
+}
+
+// Export writes the feeds and records the run. The first run has no previous
+// export, so its incremental feed is the full feed.
+func (e *Exporter) Export(ctx context.Context) (*models.FeedExport, error) {
+	if err := os.MkdirAll(e.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create feed directory: %w", err)
+	}
+
+	var since *models.FeedExport
+	last, err := e.Exports.Last(ctx)
+	if err == nil {
+		since = last
+	} else if !errors.Is(err, models.ErrNotFound) {
+		return nil, err
+	}
+
+	// The incremental feed is built first: its GeneratedAt is the earliest
+	// read of this run and becomes the start of the next one.
+	var changes *Feed
+	if since != nil {
+		changes, err = e.Builder.Build(ctx, &since.ExportedAt)
+	} else {
+		changes, err = e.Builder.Build(ctx, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	full := changes
+	if since != nil {
+		if full, err = e.Builder.Build(ctx, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, format := range e.Formats {
+		if err := e.write(format, FileName(format, false), full); err != nil {
+			return nil, err
+		}
+		if err := e.write(format, FileName(format, true), changes); err != nil {
+			return nil, err
+		}
+	}
+
+	export := &models.FeedExport{
+		ExportedAt: changes.GeneratedAt,
+		Since:      changes.Since,
+		Listings:   len(changes.Listings),
+		Removed:    len(changes.Removed),
+	}
+	if err := e.Exports.Record(ctx, export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// write replaces the file atomically so that portals never fetch a partial feed.
+func (e *Exporter) write(format Format, name string, feed *Feed) error {
+	tmp, err := os.CreateTemp(e.Dir, ".feed-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	buffered := bufio.NewWriter(tmp)
+	if err := format.Encode(buffered, feed); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not encode the %s feed: %w", format.Name(), err)
+	}
+	if err := buffered.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(e.Dir, name))
+}
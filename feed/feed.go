@@ -0,0 +1,168 @@
+// Package feed builds the syndication feed of the vehicles for sale, which
+// listing portals fetch from the API or pick up from the export directory.
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Stand/media"
+	"github.com/Stand/models"
+)
+
+// Currency of every price in the feed.
+const Currency = "EUR"
+
+// Feed lists the available vehicles. A full feed has every available
+// vehicle; an incremental feed (Since set) only those changed since then,
+// plus the vehicles changed since then that are no longer available in
+// Removed, which may include vehicles that were never listed. Vehicles
+// deleted outright only disappear from the full feed.
+type Feed struct {
+	XMLName     xml.Name   `json:"-" xml:"feed"`
+	GeneratedAt time.Time  `json:"generated_at" xml:"generated_at,attr"`
+	Since       *time.Time `json:"since,omitempty" xml:"since,attr,omitempty"`
+	Listings    []Listing  `json:"listings" xml:"listings>listing"`
+	Removed     []int      `json:"removed,omitempty" xml:"removed,omitempty"`
+}
+
+// Listing is a vehicle for sale. Acquisition costs and the licence plate are
+// never published.
+type Listing struct {
+	ID               int                 `json:"id" xml:"id,attr"`
+	Type             string              `json:"type" xml:"type"`
+	Brand            string              `json:"brand" xml:"brand"`
+	Model            string              `json:"model" xml:"model"`
+	Year             int                 `json:"year" xml:"year"`
+	Motor            string              `json:"motor" xml:"motor"`
+	VIN              string              `json:"vin,omitempty" xml:"vin,omitempty"`
+	Mileage          int                 `json:"mileage" xml:"mileage"`
+	Colour           string              `json:"colour,omitempty" xml:"colour,omitempty"`
+	Transmission     models.Transmission `json:"transmission,omitempty" xml:"transmission,omitempty"`
+	Doors            int                 `json:"doors,omitempty" xml:"doors,omitempty"`
+	Seats            int                 `json:"seats,omitempty" xml:"seats,omitempty"`
+	EngineCC         int                 `json:"engine_cc,omitempty" xml:"engine_cc,omitempty"`
+	LicenceCategory  string              `json:"licence_category,omitempty" xml:"licence_category,omitempty"`
+	PayloadKg        int                 `json:"payload_kg,omitempty" xml:"payload_kg,omitempty"`
+	Axles            int                 `json:"axles,omitempty" xml:"axles,omitempty"`
+	FuelType         models.FuelType     `json:"fuel_type,omitempty" xml:"fuel_type,omitempty"`
+	PowerKW          int                 `json:"power_kw,omitempty" xml:"power_kw,omitempty"`
+	PowerHP          int                 `json:"power_hp,omitempty" xml:"power_hp,omitempty"`
+	CO2              int                 `json:"co2,omitempty" xml:"co2,omitempty"`
+	EmissionStandard string              `json:"emission_standard,omitempty" xml:"emission_standard,omitempty"`
+	BatteryKWh       float64             `json:"battery_kwh,omitempty" xml:"battery_kwh,omitempty"`
+	Price            float64             `json:"price" xml:"price"`
+	Currency         string              `json:"currency" xml:"price_currency"`
+	Photos           []Photo             `json:"photos" xml:"photos>photo"`
+	UpdatedAt        time.Time           `json:"updated_at" xml:"updated_at"`
+}
+
+// Photo is an image of the gallery, in gallery order.
+type Photo struct {
+	Position   int         `json:"position" xml:"position,attr"`
+	Cover      bool        `json:"cover" xml:"cover,attr"`
+	Width      int         `json:"width" xml:"width,attr"`
+	Height     int         `json:"height" xml:"height,attr"`
+	URL        string      `json:"url" xml:"url"`
+	Thumbnails []Thumbnail `json:"thumbnails" xml:"thumbnail"`
+}
+
+// Thumbnail is a scaled JPEG rendition of a photo.
+type Thumbnail struct {
+	Size string `json:"size" xml:"size,attr"`
+	URL  string `json:"url" xml:",chardata"`
+}
+
+// Builder reads the feed from the repositories.
+type Builder struct {
+	Vehicles models.VehicleRepository
+	Media    models.MediaRepository
+	// BaseURL is prepended to the photo paths, e.g. "https://stand.example.pt";
+	// when empty the URLs are relative to the API.
+	BaseURL string
+}
+
+// Build returns the full feed, or the incremental feed of the changes after
+// since. GeneratedAt is taken before reading, so passing it as since to the
+// next build does not miss changes made meanwhile.
+func (b *Builder) Build(ctx context.Context, since *time.Time) (*Feed, error) {
+	feed := &Feed{GeneratedAt: time.Now().UTC(), Since: since, Listings: []Listing{}}
+
+	query := models.VehicleQuery{Filter: models.VehicleFilter{Statuses: []models.VehicleStatus{models.StatusAvailable}}}
+	if since != nil {
+		query.Filter = models.VehicleFilter{UpdatedSince: since}
+	}
+	page, err := b.Vehicles.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range page.Items {
+		vehicle := &page.Items[i]
+		if vehicle.Status != models.StatusAvailable {
+			feed.Removed = append(feed.Removed, vehicle.ID)
+			continue
+		}
+
+		gallery, err := b.Media.ListByVehicle(ctx, int64(vehicle.ID))
+		if errors.Is(err, models.ErrNotFound) {
+			// Deleted since it was read.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		feed.Listings = append(feed.Listings, b.listing(vehicle, gallery))
+	}
+	return feed, nil
+}
+
+func (b *Builder) listing(vehicle *models.Vehicle, gallery []models.VehicleMedia) Listing {
+	listing := Listing{
+		ID:               vehicle.ID,
+		Type:             vehicle.Type,
+		Brand:            vehicle.Brand,
+		Model:            vehicle.Model,
+		Year:             vehicle.Year,
+		Motor:            vehicle.Motor,
+		VIN:              vehicle.VIN,
+		Mileage:          vehicle.Mileage,
+		Colour:           vehicle.Colour,
+		Transmission:     vehicle.Transmission,
+		Doors:            vehicle.Doors,
+		Seats:            vehicle.Seats,
+		EngineCC:         vehicle.EngineCC,
+		LicenceCategory:  vehicle.LicenceCategory,
+		PayloadKg:        vehicle.PayloadKg,
+		Axles:            vehicle.Axles,
+		FuelType:         vehicle.FuelType,
+		PowerKW:          vehicle.PowerKW,
+		PowerHP:          vehicle.PowerHP,
+		CO2:              vehicle.CO2,
+		EmissionStandard: vehicle.EmissionStandard,
+		BatteryKWh:       vehicle.BatteryKWh,
+		Price:            vehicle.AskingPrice,
+		Currency:         Currency,
+		Photos:           []Photo{},
+		UpdatedAt:        vehicle.UpdatedAt,
+	}
+
+	base := strings.TrimSuffix(b.BaseURL, "/")
+	for _, item := range gallery {
+		photo := Photo{
+			Position: item.Position,
+			Cover:    item.Cover,
+			Width:    item.Width,
+			Height:   item.Height,
+			URL:      base + media.URL(item.VehicleID, item.ID, media.Original),
+		}
+		for _, size := range media.ThumbnailSizes {
+			photo.Thumbnails = append(photo.Thumbnails, Thumbnail{Size: size.Name, URL: base + media.URL(item.VehicleID, item.ID, size.Name)})
+		}
+		listing.Photos = append(listing.Photos, photo)
+	}
+	return listing
+}
@@ -0,0 +1,105 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Format encodes the feed in the schema of its consumers. The generic XML
+// and JSON formats are built in; portal-specific schemas implement Format
+// and are added with Register.
+type Format interface {
+	// Name identifies the format in the configuration.
+	Name() string
+	// Extension is the file extension of the format, without the dot.
+	Extension() string
+	ContentType() string
+	Encode(w io.Writer, feed *Feed) error
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]Format{}
+)
+
+// Register makes a format available under its name, replacing any format
+// registered with the same name.
+func Register(format Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[format.Name()] = format
+}
+
+// Lookup returns the format registered under name.
+func Lookup(name string) (Format, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	format, ok := formats[name]
+	return format, ok
+}
+
+// Formats returns the registered formats ordered by name.
+func Formats() []Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	list := make([]Format, 0, len(formats))
+	for _, format := range formats {
+		list = append(list, format)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// FileName is where a format is served and exported: "vehicles.xml" for the
+// generic formats and e.g. "vehicles-portal.xml" for the others.
+// Incremental exports are written to "vehicles-changes.xml" and so on.
+func FileName(format Format, incremental bool) string {
+	name := "vehicles"
+	if format.Name() != format.Extension() {
+		name += "-" + format.Name()
+	}
+	if incremental {
+		name += "-changes"
+	}
+	return name + "." + format.Extension()
+}
+
+func init() {
+	Register(jsonFormat{})
+	Register(xmlFormat{})
+}
+
+type jsonFormat struct{}
+
+func (jsonFormat) Name() string        { return "json" }
+func (jsonFormat) Extension() string   { return "json" }
+func (jsonFormat) ContentType() string { return "application/json; charset=utf-8" }
+
+func (jsonFormat) Encode(w io.Writer, feed *Feed) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(feed)
+}
+
+type xmlFormat struct{}
+
+func (xmlFormat) Name() string        { return "xml" }
+func (xmlFormat) Extension() string   { return "xml" }
+func (xmlFormat) ContentType() string { return "application/xml; charset=utf-8" }
+
+func (xmlFormat) Encode(w io.Writer, feed *Feed) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
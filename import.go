@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Stand/config"
+	"github.com/Stand/db"
+	"github.com/Stand/importer"
+	"github.com/Stand/sqlstore"
+)
+
+const importUsage = "usage: stand_api import [-dry-run] [-map HEADER:FIELD,...] <file.csv|file.xlsx>"
+
+// runImport implements the "import" command, printing the row errors and a
+// summary to stdout.
+func runImport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "validate the rows without creating vehicles")
+	mappingText := fs.String("map", "", "map unrecognised headers to vehicle fields, e.g. Marca:Brand,Kms:Mileage")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New(importUsage)
+	}
+	path := fs.Arg(0)
+
+	if cfg.Database.Driver == "memory" {
+		return errors.New("vehicles imported into the in-memory store would be lost on exit")
+	}
+
+	format, err := importer.FormatOf(path)
+	if err != nil {
+		return err
+	}
+	mapping, err := importer.ParseMapping(*mappingText)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	table, err := importer.ReadTable(data, format)
+	if err != nil {
+		return err
+	}
+
+	conn, dialect := db.InitDB(cfg.Database, cfg.AutoMigrate)
+	defer conn.Close()
+	repos := sqlstore.New(conn, dialect).Repositories()
+
+	report, err := importer.Import(context.Background(), repos, table, importer.Options{Mapping: mapping, DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	for _, header := range table.Header {
+		if field, ok := report.Columns[header]; ok {
+			fmt.Fprintf(os.Stdout, "column %q -> %s\n", header, field)
+		}
+	}
+	for _, header := range report.IgnoredColumns {
+		fmt.Fprintf(os.Stdout, "column %q ignored\n", header)
+	}
+	for _, rowErr := range report.Errors {
+		fmt.Fprintf(os.Stdout, "row %d: %v\n", rowErr.Row, rowErr.Errors)
+	}
+
+	if *dryRun {
+		fmt.Fprintf(os.Stdout, "dry run: %d rows, %d valid, %d invalid\n", report.Rows, report.Valid, report.Invalid)
+	} else {
+		fmt.Fprintf(os.Stdout, "%d rows, %d imported, %d invalid\n", report.Rows, report.Imported, report.Invalid)
+	}
+	return nil
+}
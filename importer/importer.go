@@ -0,0 +1,187 @@
+// Package importer creates vehicles in bulk from CSV and XLSX files.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Stand/models"
+)
+
+// Options control an import.
+type Options struct {
+	// Mapping overrides the columns recognised from the header.
+	Mapping Mapping
+	// DryRun validates every row without creating any vehicle.
+	DryRun bool
+}
+
+// RowError lists the problems of one data row. Row is the line in the
+// file, counting the header as line 1.
+type RowError struct {
+	Row    int                     `json:"row"`
+	Errors models.ValidationErrors `json:"errors"`
+}
+
+// Report is the outcome of an import.
+type Report struct {
+	DryRun   bool `json:"dry_run"`
+	Rows     int  `json:"rows"`
+	Valid    int  `json:"valid"`
+	Invalid  int  `json:"invalid"`
+	Imported int  `json:"imported"`
+	// Columns maps each used header to its vehicle field.
+	Columns        map[string]string `json:"columns"`
+	IgnoredColumns []string          `json:"ignored_columns"`
+	Errors         []RowError        `json:"errors"`
+	VehicleIDs     []int             `json:"vehicle_ids,omitempty"`
+}
+
+// ErrMapping wraps problems with the header or column mapping, which make
+// the whole file unusable.
+var ErrMapping = errors.New("invalid column mapping")
+
+// Import validates every row of table and, unless it is a dry run, creates
+// the valid ones in a single transaction. Rows whose brand or model is not
+// in the catalogue, or with a VIN or plate already in the inventory or
+// earlier in the file, are invalid. If storing fails,
+// nothing is imported and the error names the row.
+func Import(ctx context.Context, repos models.Repositories, table *Table, options Options) (*Report, error) {
+	columns, err := resolveColumns(table.Header, options.Mapping)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMapping, err)
+	}
+
+	report := &Report{DryRun: options.DryRun, Columns: map[string]string{}, IgnoredColumns: []string{}, Errors: []RowError{}}
+	for i, field := range columns {
+		if field == "" {
+			report.IgnoredColumns = append(report.IgnoredColumns, table.Header[i])
+		} else {
+			report.Columns[table.Header[i]] = field
+		}
+	}
+	if !hasMapped(columns, "Model") {
+		return nil, fmt.Errorf("%w: no column maps to Model", ErrMapping)
+	}
+
+	catalog, err := repos.Catalog.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type parsedRow struct {
+		row     int
+		vehicle models.Vehicle
+		errs    models.ValidationErrors
+	}
+	var parsed []parsedRow
+	var vins, plates []string
+
+	for i, record := range table.Rows {
+		if blank(record) {
+			continue
+		}
+		report.Rows++
+
+		vehicle, errs := vehicleFromRow(columns, record)
+		if len(errs) == 0 {
+			var invalid models.ValidationErrors
+			if errors.As(catalog.Resolve(&vehicle), &invalid) {
+				errs = append(errs, invalid...)
+			}
+		}
+		parsed = append(parsed, parsedRow{row: i + 2, vehicle: vehicle, errs: errs})
+		if vehicle.VIN != "" {
+			vins = append(vins, vehicle.VIN)
+		}
+		if vehicle.LicensePlate != "" {
+			plates = append(plates, vehicle.LicensePlate)
+		}
+	}
+
+	// The VINs and plates already in the inventory, read in one query.
+	existing, err := repos.Vehicles.FindByIdentity(ctx, vins, plates)
+	if err != nil {
+		return nil, err
+	}
+	existingVIN := map[string]int{}
+	existingPlate := map[string]int{}
+	for _, vehicle := range existing {
+		existingVIN[vehicle.VIN] = vehicle.ID
+		existingPlate[vehicle.LicensePlate] = vehicle.ID
+	}
+
+	var valid []models.Vehicle
+	var validRows []int
+	seenVIN := map[string]int{}
+	seenPlate := map[string]int{}
+
+	for _, p := range parsed {
+		errs := append(p.errs, checkDuplicate("VIN", p.vehicle.VIN, p.row, seenVIN, existingVIN)...)
+		errs = append(errs, checkDuplicate("LicensePlate", p.vehicle.LicensePlate, p.row, seenPlate, existingPlate)...)
+
+		if len(errs) > 0 {
+			report.Invalid++
+			report.Errors = append(report.Errors, RowError{Row: p.row, Errors: errs})
+			continue
+		}
+		report.Valid++
+		valid = append(valid, p.vehicle)
+		validRows = append(validRows, p.row)
+	}
+
+	if options.DryRun || len(valid) == 0 {
+		return report, nil
+	}
+
+	if err := repos.Vehicles.CreateBatch(ctx, valid); err != nil {
+		var batchErr *models.BatchError
+		if errors.As(err, &batchErr) {
+			return nil, fmt.Errorf("row %d: %w", validRows[batchErr.Index], batchErr.Err)
+		}
+		return nil, err
+	}
+
+	report.Imported = len(valid)
+	for _, vehicle := range valid {
+		report.VehicleIDs = append(report.VehicleIDs, vehicle.ID)
+	}
+	return report, nil
+}
+
+func hasMapped(columns []string, field string) bool {
+	for _, column := range columns {
+		if column == field {
+			return true
+		}
+	}
+	return false
+}
+
+func blank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDuplicate reports a VIN or plate that an earlier row (seen) or a
+// vehicle in the inventory (existing) already has.
+func checkDuplicate(field, value string, row int, seen, existing map[string]int) models.ValidationErrors {
+	if value == "" {
+		return nil
+	}
+	if earlier, ok := seen[value]; ok {
+		return models.ValidationErrors{{Field: field, Message: fmt.Sprintf("duplicates row %d", earlier)}}
+	}
+	seen[value] = row
+
+	if id, ok := existing[value]; ok {
+		return models.ValidationErrors{{Field: field, Message: fmt.Sprintf("already belongs to vehicle %d", id)}}
+	}
+	return nil
+}
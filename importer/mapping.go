@@ -0,0 +1,310 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Stand/engine"
+	"github.com/Stand/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// fieldAliases lists the Vehicle fields a column can be mapped to, with the
+// headers recognised for each besides the field name itself. Headers are
+// compared ignoring case, accents, spaces and punctuation.
+var fieldAliases = map[string][]string{
+	"Type":             {"tipo"},
+	"Brand":            {"marca", "make"},
+	"Model":            {"modelo"},
+	"Year":             {"ano", "model year"},
+	"Motor":            {"engine", "motorizacao"},
+	"Status":           {"estado"},
+	"VIN":              {"chassis", "numero de chassis", "quadro"},
+	"LicensePlate":     {"matricula", "plate", "licence plate"},
+	"Mileage":          {"quilometros", "quilometragem", "km", "kms", "odometer"},
+	"Colour":           {"cor", "color"},
+	"Transmission":     {"caixa", "transmissao", "gearbox"},
+	"Doors":            {"portas"},
+	"Seats":            {"lugares"},
+	"EngineCC":         {"cilindrada", "cc"},
+	"LicenceCategory":  {"categoria", "categoria de carta", "licence category"},
+	"PayloadKg":        {"carga", "carga util", "payload"},
+	"Axles":            {"eixos"},
+	"FuelType":         {"combustivel", "fuel"},
+	"PowerKW":          {"kw", "potencia kw"},
+	"PowerHP":          {"cv", "hp", "potencia", "potencia cv"},
+	"CO2":              {"emissoes", "emissoes co2", "co2 g/km"},
+	"EmissionStandard": {"norma euro", "norma de emissoes", "euro"},
+	"BatteryKWh":       {"bateria", "battery", "kwh"},
+	"AskingPrice":      {"preco", "preco de venda", "price"},
+	"PurchasePrice":    {"preco de compra", "custo", "cost"},
+	"Supplier":         {"fornecedor"},
+	"AcquisitionDate":  {"data de aquisicao", "data de compra"},
+}
+
+// headerKey folds a header for comparison.
+func headerKey(header string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, models.Fold(header))
+}
+
+var fieldsByKey = func() map[string]string {
+	fields := map[string]string{}
+	for field, aliases := range fieldAliases {
+		fields[headerKey(field)] = field
+		for _, alias := range aliases {
+			fields[headerKey(alias)] = field
+		}
+	}
+	return fields
+}()
+
+// Mapping maps file headers to Vehicle field names.
+type Mapping map[string]string
+
+// ParseMapping reads a comma-separated list of HEADER:FIELD pairs such as
+// "Marca:Brand,Kms:Mileage".
+func ParseMapping(text string) (Mapping, error) {
+	mapping := Mapping{}
+	for _, pair := range strings.Split(text, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		header, field, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("column mapping %q is not HEADER:FIELD", pair)
+		}
+		mapping[strings.TrimSpace(header)] = strings.TrimSpace(field)
+	}
+	return mapping, nil
+}
+
+// resolveColumns returns the field of each column, "" for ignored ones.
+// Explicit mappings take precedence over recognised headers.
+func resolveColumns(header []string, explicit Mapping) ([]string, error) {
+	explicitByKey := map[string]string{}
+	for name, field := range explicit {
+		if _, ok := fieldAliases[field]; !ok {
+			return nil, fmt.Errorf("unknown vehicle field %q in the column mapping", field)
+		}
+		explicitByKey[headerKey(name)] = field
+	}
+
+	columns := make([]string, len(header))
+	mappedBy := map[string]string{}
+	for i, name := range header {
+		key := headerKey(name)
+		field, ok := explicitByKey[key]
+		if ok {
+			delete(explicitByKey, key)
+		} else {
+			field = fieldsByKey[key]
+		}
+		if field == "" {
+			continue
+		}
+
+		if previous, ok := mappedBy[field]; ok {
+			return nil, fmt.Errorf("columns %q and %q both map to %s", previous, name, field)
+		}
+		mappedBy[field] = name
+		columns[i] = field
+	}
+
+	for name := range explicit {
+		if _, missing := explicitByKey[headerKey(name)]; missing {
+			return nil, fmt.Errorf("the file has no column %q", name)
+		}
+	}
+	return columns, nil
+}
+
+// requiredFields must have a value in every row.
+var requiredFields = []string{"Type", "Model", "Motor"}
+
+// vehicleFromRow converts one data row. Every value is checked so that all
+// the problems of a row are reported together.
+func vehicleFromRow(columns []string, record []string) (models.Vehicle, models.ValidationErrors) {
+	vehicle := models.Vehicle{Status: models.StatusIncoming}
+	var errs models.ValidationErrors
+
+	for i, field := range columns {
+		if field == "" || i >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+		if err := setField(&vehicle, field, value); err != nil {
+			errs = append(errs, models.ValidationError{Field: field, Message: err.Error()})
+		}
+	}
+
+	for _, field := range requiredFields {
+		if !hasValue(columns, record, field) {
+			errs = append(errs, models.ValidationError{Field: field, Message: "is required"})
+		}
+	}
+
+	vehicle.Normalize()
+	engine.Complete(&vehicle)
+	if err := vehicle.Validate(); err != nil {
+		for _, fieldErr := range err.(models.ValidationErrors) {
+			if !hasError(errs, fieldErr.Field) {
+				errs = append(errs, fieldErr)
+			}
+		}
+	}
+	if !hasError(errs, "Status") {
+		switch {
+		case !vehicle.Status.Valid():
+			errs = append(errs, models.ValidationError{Field: "Status", Message: "is not a vehicle status"})
+		case !vehicle.Status.Initial():
+			errs = append(errs, models.ValidationError{Field: "Status", Message: "must be incoming, in_preparation or available"})
+		}
+	}
+	return vehicle, errs
+}
+
+func hasValue(columns []string, record []string, field string) bool {
+	for i, column := range columns {
+		if column == field && i < len(record) && strings.TrimSpace(record[i]) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasError(errs models.ValidationErrors, field string) bool {
+	for _, err := range errs {
+		if err.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func setField(v *models.Vehicle, field, value string) error {
+	var err error
+	switch field {
+	case "Type":
+		v.Type = value
+	case "Brand":
+		v.Brand = value
+	case "Model":
+		v.Model = value
+	case "Year":
+		v.Year, err = parseInteger(value)
+	case "Motor":
+		v.Motor = value
+	case "Status":
+		v.Status = models.VehicleStatus(strings.ToLower(value))
+	case "VIN":
+		v.VIN = value
+	case "LicensePlate":
+		v.LicensePlate = value
+	case "Mileage":
+		v.Mileage, err = parseInteger(strings.TrimSuffix(strings.ToLower(value), "km"))
+	case "Colour":
+		v.Colour = value
+	case "Transmission":
+		v.Transmission = models.Transmission(value)
+	case "Doors":
+		v.Doors, err = parseInteger(value)
+	case "Seats":
+		v.Seats, err = parseInteger(value)
+	case "EngineCC":
+		v.EngineCC, err = parseInteger(strings.TrimSuffix(strings.ToLower(value), "cc"))
+	case "LicenceCategory":
+		v.LicenceCategory = value
+	case "PayloadKg":
+		v.PayloadKg, err = parseInteger(strings.TrimSuffix(strings.ToLower(value), "kg"))
+	case "Axles":
+		v.Axles, err = parseInteger(value)
+	case "FuelType":
+		v.FuelType = models.FuelType(value)
+	case "PowerKW":
+		v.PowerKW, err = parseInteger(strings.TrimSuffix(strings.ToLower(value), "kw"))
+	case "PowerHP":
+		v.PowerHP, err = parseInteger(strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(value), "hp"), "cv"))
+	case "CO2":
+		v.CO2, err = parseInteger(strings.TrimSuffix(strings.ToLower(value), "g/km"))
+	case "EmissionStandard":
+		v.EmissionStandard = value
+	case "BatteryKWh":
+		v.BatteryKWh, err = parseAmount(strings.TrimSuffix(strings.ToLower(value), "kwh"))
+	case "AskingPrice":
+		v.AskingPrice, err = parseAmount(value)
+	case "PurchasePrice":
+		v.PurchasePrice, err = parseAmount(value)
+	case "Supplier":
+		v.Supplier = value
+	case "AcquisitionDate":
+		var date time.Time
+		date, err = parseDate(value)
+		v.AcquisitionDate = &date
+	}
+	return err
+}
+
+// parseInteger accepts thousands separators, as in "120.000" or "120 000".
+func parseInteger(value string) (int, error) {
+	cleaned := strings.NewReplacer(".", "", ",", "", " ", "", " ", "").Replace(strings.TrimSpace(value))
+	parsed, err := strconv.Atoi(cleaned)
+	if err != nil {
+		return 0, errors.New("is not a whole number")
+	}
+	return parsed, nil
+}
+
+// parseAmount accepts a euro sign and both decimal conventions: the last
+// of "." and "," is the decimal separator when both appear, a lone "," is
+// always decimal and a lone "." followed by three digits groups thousands.
+func parseAmount(value string) (float64, error) {
+	cleaned := strings.NewReplacer("€", "", " ", "", " ", "").Replace(strings.TrimSpace(value))
+
+	dot, comma := strings.LastIndex(cleaned, "."), strings.LastIndex(cleaned, ",")
+	switch {
+	case dot >= 0 && comma >= 0 && comma > dot:
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	case dot >= 0 && comma >= 0:
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	case comma >= 0:
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	case dot >= 0 && (strings.Count(cleaned, ".") > 1 || len(cleaned)-dot == 4):
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+	}
+
+	parsed, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, errors.New("is not an amount")
+	}
+	return parsed, nil
+}
+
+var dateLayouts = []string{"2006-01-02", "02/01/2006", "02-01-2006", "2/1/2006"}
+
+// parseDate accepts ISO and day-first dates, and spreadsheet serial numbers.
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		if date, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return date.Truncate(24 * time.Hour), nil
+		}
+	}
+	return time.Time{}, errors.New("is not a date (use YYYY-MM-DD or DD/MM/YYYY)")
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+// FlagExpiringDocuments flags the documents of vehicles in stock that expire
+// within window every interval until ctx is done, starting immediately. Each
+// document is reported once; renewing it with a later-expiring upload of the
+// same type clears the alert.
+func FlagExpiringDocuments(ctx context.Context, documents models.DocumentRepository, window, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		flagged, err := documents.FlagExpiring(ctx, now.Add(window), now)
+		if err != nil {
+			slog.Error("[jobs] Could not flag expiring documents", "err", err)
+		}
+		for _, document := range flagged {
+			log.Printf("[jobs] Vehicle %d (%s): %s document %d expires on %s",
+				document.VehicleID, document.Vehicle.LicensePlate, document.Type, document.ID, document.ExpiresOn.Format(time.DateOnly))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
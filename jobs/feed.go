@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/Stand/feed"
+)
+
+// ExportFeed writes the syndication feed to disk every interval until ctx is
+// done, starting immediately.
+func ExportFeed(ctx context.Context, exporter *feed.Exporter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		export, err := exporter.Export(ctx)
+		if err != nil {
+			slog.Error("[jobs] Could not export the feed", "err", err)
+		} else {
+			log.Printf("[jobs] Feed exported to %s: %d listings changed, %d removed", exporter.Dir, export.Listings, export.Removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
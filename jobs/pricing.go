@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+// ApplyPriceRules lowers the asking prices of the stock according to policy
+// every interval until ctx is done, starting immediately.
+func ApplyPriceRules(ctx context.Context, vehicles models.VehicleRepository, policy models.PricePolicy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := applyPriceRules(ctx, vehicles, policy, time.Now()); err != nil {
+			slog.Error("[jobs] Could not apply price rules", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func applyPriceRules(ctx context.Context, vehicles models.VehicleRepository, policy models.PricePolicy, now time.Time) error {
+	stock, err := vehicles.Stock(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range stock {
+		item := &stock[i]
+		if item.Vehicle.Status != models.StatusAvailable {
+			continue
+		}
+
+		history, err := vehicles.PriceHistory(ctx, int64(item.Vehicle.ID))
+		if err != nil {
+			return err
+		}
+
+		for _, change := range policy.Due(item, history, now) {
+			err := vehicles.Reprice(ctx, &change)
+			if errors.Is(err, models.ErrVehicleChanged) {
+				// Sold, reserved or repriced by hand meanwhile; retried next run.
+				break
+			}
+			if err != nil {
+				return err
+			}
+			log.Printf("[jobs] Vehicle %d repriced from %.2f to %.2f (%s)", change.VehicleID, change.OldPrice, change.NewPrice, change.Rule)
+		}
+	}
+	return nil
+}
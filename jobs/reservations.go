@@ -0,0 +1,35 @@
+// Package jobs holds the background workers that run alongside the API.
+package jobs
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+// ExpireReservations releases expired reservations every interval until ctx
+// is done, starting immediately so reservations that expired while the API
+// was down are released on startup.
+func ExpireReservations(ctx context.Context, reservations models.ReservationRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := reservations.ExpireDue(ctx, time.Now())
+		if err != nil {
+			slog.Error("[jobs] Could not expire reservations", "err", err)
+		}
+		for _, reservation := range expired {
+			log.Printf("[jobs] Reservation %d expired; vehicle %d released", reservation.ID, reservation.VehicleID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
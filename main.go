@@ -1,18 +1,150 @@
 package main
 
 import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/Stand/config"
 	"github.com/Stand/db"
+	"github.com/Stand/feed"
+	"github.com/Stand/jobs"
+	"github.com/Stand/media"
+	"github.com/Stand/memstore"
+	"github.com/Stand/models"
 	"github.com/Stand/routes"
+	"github.com/Stand/sqlstore"
+	"github.com/Stand/vin"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Initialize DB
-	db.InitDB()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(cfg.Args) > 0 && cfg.Args[0] == "migrate" {
+		if err := runMigrate(cfg, cfg.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(cfg.Args) > 0 && cfg.Args[0] == "import" {
+		if err := runImport(cfg, cfg.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(cfg.Args) > 0 && cfg.Args[0] == "catalog" {
+		if err := runCatalog(cfg, cfg.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(cfg.Args) > 0 && cfg.Args[0] == "engine" {
+		if err := runEngine(cfg, cfg.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var repos models.Repositories
+	if cfg.Database.Driver == "memory" {
+		log.Println("Using the in-memory store; data is lost on restart")
+		repos = memstore.New().Repositories()
+	} else {
+		// Initialize DB and apply pending migrations
+		conn, dialect := db.InitDB(cfg.Database, cfg.AutoMigrate)
+		defer conn.Close()
+
+		repos = sqlstore.New(conn, dialect).Repositories()
+	}
+
+	vinDecoder, err := vin.New(cfg.VINTable)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mediaStorage, err := media.NewStorage(cfg.Media)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	server := gin.Default()
+	var exporter *feed.Exporter
+	if cfg.Feed.Dir != "" {
+		exporter = &feed.Exporter{
+			Builder: &feed.Builder{Vehicles: repos.Vehicles, Media: repos.Media, BaseURL: cfg.Feed.BaseURL},
+			Exports: repos.Feeds,
+			Dir:     cfg.Feed.Dir,
+		}
+		for _, name := range cfg.Feed.Formats {
+			format, ok := feed.Lookup(name)
+			if !ok {
+				log.Fatalf("unknown feed format %q", name)
+			}
+			exporter.Formats = append(exporter.Formats, format)
+		}
+	}
+
+	// From here on nothing exits through log.Fatal, whose message the log
+	// level could hide.
+	level := setupLogging(cfg.LogLevel)
+
+	go jobs.ExpireReservations(context.Background(), repos.Reservations, cfg.Jobs.ReservationExpiry.Duration)
+
+	go jobs.FlagExpiringDocuments(context.Background(), repos.Documents, cfg.Documents.ExpiryWindow.Duration, cfg.Jobs.DocumentExpiry.Duration)
+
+	if len(cfg.Pricing.Rules) > 0 {
+		policy := models.PricePolicy{FloorMarkup: cfg.Pricing.FloorMarkup}
+		for _, rule := range cfg.Pricing.Rules {
+			policy.Rules = append(policy.Rules, models.PriceRule{AfterDays: rule.AfterDays, Percent: rule.Percent})
+		}
+		go jobs.ApplyPriceRules(context.Background(), repos.Vehicles, policy, cfg.Jobs.PriceRules.Duration)
+	}
+
+	if exporter != nil {
+		go jobs.ExportFeed(context.Background(), exporter, cfg.Jobs.FeedExport.Duration)
+	}
+
+	server := gin.New()
+	if level <= slog.LevelInfo {
+		server.Use(gin.Logger())
+	}
+	server.Use(gin.Recovery())
+
+	routes.RegisterRoutes(server, routes.Dependencies{
+		Repos:                repos,
+		Timeouts:             cfg.Timeouts,
+		VINDecoder:           vinDecoder,
+		MediaStorage:         mediaStorage,
+		MaxUploadSize:        cfg.Media.MaxUploadSize,
+		FeedBaseURL:          cfg.Feed.BaseURL,
+		DocumentExpiryWindow: cfg.Documents.ExpiryWindow.Duration,
+	})
+
+	server.Run(cfg.ListenAddr)
+}
 
-	routes.RegisterRoutes(server)
+// setupLogging sends the application log, including what is written with
+// the log package at info level, through slog at the configured level. The
+// request log is info as well, and gin runs in debug mode at debug level.
+func setupLogging(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		// Rejected by config.Validate already.
+		level = slog.LevelInfo
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
 
-	server.Run(":8080")
+	if level == slog.LevelDebug {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	return level
 }
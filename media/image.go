@@ -0,0 +1,132 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Original is the rendition name of the uploaded file.
+const Original = "original"
+
+// ThumbnailSize is a thumbnail rendition, scaled so that its longest side
+// is at most MaxSide pixels.
+type ThumbnailSize struct {
+	Name    string
+	MaxSide int
+}
+
+// ThumbnailSizes are the thumbnails generated for every uploaded image.
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", MaxSide: 160},
+	{Name: "medium", MaxSide: 480},
+	{Name: "large", MaxSide: 1280},
+}
+
+// ThumbnailContentType is the format of every thumbnail.
+const ThumbnailContentType = "image/jpeg"
+
+// maxPixels guards against images that are small files but decode into
+// huge bitmaps.
+const maxPixels = 50_000_000
+
+// ErrUnsupportedImage is returned for files that are not JPEG, PNG, GIF or
+// WebP images, or are too large to process.
+var ErrUnsupportedImage = errors.New("file is not a supported image")
+
+// Renditions lists the original and the thumbnail names.
+func Renditions() []string {
+	names := []string{Original}
+	for _, size := range ThumbnailSizes {
+		names = append(names, size.Name)
+	}
+	return names
+}
+
+// ValidRendition reports whether name is the original or a thumbnail.
+func ValidRendition(name string) bool {
+	for _, rendition := range Renditions() {
+		if rendition == name {
+			return true
+		}
+	}
+	return false
+}
+
+// URL is the path at which the API serves a rendition of a vehicle's media.
+func URL(vehicleID, mediaID int64, rendition string) string {
+	return fmt.Sprintf("/vehicles/%d/media/%d/%s", vehicleID, mediaID, rendition)
+}
+
+// DocumentURL is the path at which the API serves a vehicle's document.
+func DocumentURL(vehicleID, documentID int64) string {
+	return fmt.Sprintf("/vehicles/%d/documents/%d", vehicleID, documentID)
+}
+
+// Image is a decoded upload with its thumbnails encoded as JPEG.
+type Image struct {
+	ContentType string
+	Width       int
+	Height      int
+	Thumbnails  map[string][]byte
+}
+
+// Process decodes an uploaded image and renders its thumbnails. Images
+// smaller than a thumbnail size are not enlarged.
+func Process(data []byte) (*Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width*config.Height > maxPixels {
+		return nil, ErrUnsupportedImage
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	result := &Image{
+		ContentType: http.DetectContentType(data),
+		Width:       config.Width,
+		Height:      config.Height,
+		Thumbnails:  map[string][]byte{},
+	}
+
+	for _, size := range ThumbnailSizes {
+		thumbnail, err := thumbnail(src, size.MaxSide)
+		if err != nil {
+			return nil, err
+		}
+		result.Thumbnails[size.Name] = thumbnail
+	}
+	return result, nil
+}
+
+func thumbnail(src image.Image, maxSide int) ([]byte, error) {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > maxSide {
+		width = max(width*maxSide/longest, 1)
+		height = max(height*maxSide/longest, 1)
+	}
+
+	// JPEG has no transparency, so transparent pixels become white.
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,74 @@
+// Package media stores vehicle photos and generates their thumbnails.
+package media
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/Stand/config"
+)
+
+// ErrNotFound is returned when a key does not exist in the storage.
+var ErrNotFound = errors.New("media object not found")
+
+// Storage keeps media files under slash-separated keys.
+type Storage interface {
+	// Put stores size bytes read from data under key, replacing any
+	// existing object.
+	Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key; the caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key; a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStorage returns the storage selected by cfg.Storage.
+func NewStorage(cfg config.Media) (Storage, error) {
+	switch cfg.Storage {
+	case "local":
+		return NewLocalStorage(cfg.Path)
+	case "s3":
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported media storage %q", cfg.Storage)
+	}
+}
+
+// NewPrefix returns a new, unique key prefix for a media item of a vehicle.
+// The original and each thumbnail are stored under the prefix, see Key.
+func NewPrefix(vehicleID int64) string {
+	var random [12]byte
+	rand.Read(random[:])
+	return fmt.Sprintf("vehicles/%d/%s", vehicleID, hex.EncodeToString(random[:]))
+}
+
+// NewDocumentKey returns a new, unique key for a document of a vehicle.
+// Documents are stored as uploaded, without renditions.
+func NewDocumentKey(vehicleID int64) string {
+	var random [12]byte
+	rand.Read(random[:])
+	return fmt.Sprintf("vehicles/%d/documents/%s", vehicleID, hex.EncodeToString(random[:]))
+}
+
+// Key is the key of one rendition ("original" or a thumbnail name) of the
+// media item stored under prefix.
+func Key(prefix, rendition string) string {
+	return path.Join(prefix, rendition)
+}
+
+// DeleteAll removes the original and every thumbnail stored under prefix,
+// returning the first error but attempting every rendition.
+func DeleteAll(ctx context.Context, storage Storage, prefix string) error {
+	var first error
+	for _, rendition := range Renditions() {
+		if err := storage.Delete(ctx, Key(prefix, rendition)); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,74 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/Stand/models"
+)
+
+type catalogRepository struct {
+	*Store
+}
+
+func (r *catalogRepository) Catalog(ctx context.Context) (*models.Catalog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.catalog(), nil
+}
+
+func (r *catalogRepository) CreateBrand(ctx context.Context, brand *models.Brand) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := r.catalog().CheckBrand(brand); err != nil {
+		return err
+	}
+
+	r.nextBrandID++
+	brand.ID = r.nextBrandID
+	r.brands[brand.ID] = *brand
+	return nil
+}
+
+func (r *catalogRepository) CreateModel(ctx context.Context, model *models.BrandModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.brands[model.BrandID]; !ok {
+		return models.ErrNotFound
+	}
+	if err := r.catalog().CheckModel(model); err != nil {
+		return err
+	}
+
+	r.nextBrandModelID++
+	model.ID = r.nextBrandModelID
+	r.brandModels[model.ID] = *model
+	return nil
+}
+
+// catalog indexes the brands and models; it must be called with the store
+// lock held.
+func (s *Store) catalog() *models.Catalog {
+	brands := make([]models.Brand, 0, len(s.brands))
+	for _, brand := range s.brands {
+		brands = append(brands, brand)
+	}
+	brandModels := make([]models.BrandModel, 0, len(s.brandModels))
+	for _, model := range s.brandModels {
+		brandModels = append(brandModels, model)
+	}
+	return models.NewCatalog(brands, brandModels)
+}
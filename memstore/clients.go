@@ -0,0 +1,113 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Stand/models"
+)
+
+type clientRepository struct {
+	*Store
+}
+
+func (r *clientRepository) Create(ctx context.Context, client *models.Client) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.nextClientID++
+	client.ID = r.nextClientID
+	r.clients[client.ID] = *client
+	return nil
+}
+
+func (r *clientRepository) GetAll(ctx context.Context) ([]models.Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var clients []models.Client
+	for _, client := range r.clients {
+		clients = append(clients, client)
+	}
+
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+	return clients, nil
+}
+
+func (r *clientRepository) GetByID(ctx context.Context, id int64) (*models.Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	client, ok := r.clients[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &client, nil
+}
+
+func (r *clientRepository) Update(ctx context.Context, client *models.Client) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.clients[client.ID]; !ok {
+		return models.ErrNotFound
+	}
+	r.clients[client.ID] = *client
+	return nil
+}
+
+func (r *clientRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.clients[id]; !ok {
+		return models.ErrNotFound
+	}
+	for _, sale := range r.sales {
+		if sale.ClientID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, reservation := range r.reservations {
+		if reservation.ClientID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, consignment := range r.consignments {
+		if consignment.OwnerID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, drive := range r.testDrives {
+		if drive.ClientID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, vehicle := range r.vehicles {
+		if vehicle.PreviousOwnerID != nil && *vehicle.PreviousOwnerID == id {
+			return models.ErrInUse
+		}
+	}
+	delete(r.clients, id)
+	return nil
+}
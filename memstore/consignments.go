@@ -0,0 +1,162 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+// storedConsignment is a consignment with the amounts fixed when it is sold.
+type storedConsignment struct {
+	models.Consignment
+	commission float64
+	amountOwed float64
+}
+
+type consignmentRepository struct {
+	*Store
+}
+
+func (r *consignmentRepository) Create(ctx context.Context, consignment *models.Consignment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	vehicle, ok := r.vehicles[consignment.VehicleID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if vehicle.Status == models.StatusSold {
+		return &models.VehicleAlreadySoldError{VehicleID: consignment.VehicleID}
+	}
+	if vehicle.PurchasePrice != 0 {
+		return &models.ConsignedPurchasePriceError{VehicleID: consignment.VehicleID}
+	}
+	if existing := r.activeConsignment(consignment.VehicleID); existing != nil {
+		return &models.VehicleConsignedError{VehicleID: consignment.VehicleID, ConsignmentID: existing.ID}
+	}
+	if _, ok := r.clients[consignment.OwnerID]; !ok {
+		return models.ErrNotFound
+	}
+
+	r.nextConsignmentID++
+	consignment.ID = r.nextConsignmentID
+	consignment.Status = models.ConsignmentActive
+	consignment.CreatedAt = time.Now().UTC()
+	consignment.ClosedAt = nil
+	consignment.SaleID = nil
+	r.consignments[consignment.ID] = storedConsignment{Consignment: *consignment}
+	return nil
+}
+
+func (r *consignmentRepository) GetAll(ctx context.Context) ([]models.Consignment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var consignments []models.Consignment
+	for _, stored := range r.consignments {
+		consignments = append(consignments, stored.Consignment)
+	}
+
+	sort.Slice(consignments, func(i, j int) bool { return consignments[i].ID > consignments[j].ID })
+	return consignments, nil
+}
+
+func (r *consignmentRepository) GetByID(ctx context.Context, id int64) (*models.Consignment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stored, ok := r.consignments[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &stored.Consignment, nil
+}
+
+func (r *consignmentRepository) Withdraw(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	stored, ok := r.consignments[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if stored.Status != models.ConsignmentActive {
+		return &models.ConsignmentNotActiveError{ConsignmentID: id, Status: stored.Status}
+	}
+
+	closedAt := time.Now().UTC()
+	stored.Status = models.ConsignmentWithdrawn
+	stored.ClosedAt = &closedAt
+	r.consignments[id] = stored
+	return nil
+}
+
+func (r *consignmentRepository) Settlement(ctx context.Context, id int64) (*models.ConsignmentSettlement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stored, ok := r.consignments[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if stored.Status != models.ConsignmentSold {
+		return nil, &models.ConsignmentNotActiveError{ConsignmentID: id, Status: stored.Status}
+	}
+
+	sale := r.sales[*stored.SaleID]
+	return &models.ConsignmentSettlement{
+		Consignment: stored.Consignment,
+		Owner:       r.clients[stored.OwnerID],
+		Vehicle:     r.vehicles[stored.VehicleID],
+		SaleDate:    sale.SaleDate,
+		SalePrice:   sale.Price,
+		Commission:  stored.commission,
+		AmountOwed:  stored.amountOwed,
+	}, nil
+}
+
+// activeConsignment must be called with the store lock held.
+func (s *Store) activeConsignment(vehicleID int64) *models.Consignment {
+	for _, stored := range s.consignments {
+		if stored.VehicleID == vehicleID && stored.Status == models.ConsignmentActive {
+			return &stored.Consignment
+		}
+	}
+	return nil
+}
+
+// sellConsignment closes a consignment as sold by sale; it must be called
+// with the store lock held.
+func (s *Store) sellConsignment(consignment *models.Consignment, sale *models.Sale) {
+	stored := s.consignments[consignment.ID]
+	closedAt := time.Now().UTC()
+	saleID := sale.ID
+	stored.Status = models.ConsignmentSold
+	stored.ClosedAt = &closedAt
+	stored.SaleID = &saleID
+	stored.commission = consignment.Commission(sale.Price)
+	stored.amountOwed = consignment.AmountOwed(sale.Price)
+	s.consignments[consignment.ID] = stored
+}
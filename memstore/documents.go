@@ -0,0 +1,161 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+type documentRepository struct {
+	*Store
+}
+
+func (r *documentRepository) Create(ctx context.Context, document *models.VehicleDocument) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.vehicles[document.VehicleID]; !ok {
+		return models.ErrNotFound
+	}
+
+	r.nextDocumentID++
+	document.ID = r.nextDocumentID
+	document.CreatedAt = time.Now().UTC()
+	document.FlaggedAt = nil
+	r.documents[document.ID] = *document
+	return nil
+}
+
+func (r *documentRepository) ListByVehicle(ctx context.Context, vehicleID int64) ([]models.VehicleDocument, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.vehicles[vehicleID]; !ok {
+		return nil, models.ErrNotFound
+	}
+
+	documents := []models.VehicleDocument{}
+	for _, document := range r.documents {
+		if document.VehicleID == vehicleID {
+			documents = append(documents, document)
+		}
+	}
+
+	// By type, latest expiry first and documents without expiry last.
+	sort.Slice(documents, func(i, j int) bool {
+		a, b := documents[i], documents[j]
+		switch {
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		case (a.ExpiresOn == nil) != (b.ExpiresOn == nil):
+			return b.ExpiresOn == nil
+		case a.ExpiresOn != nil && !a.ExpiresOn.Equal(*b.ExpiresOn):
+			return a.ExpiresOn.After(*b.ExpiresOn)
+		}
+		return a.ID > b.ID
+	})
+	return documents, nil
+}
+
+func (r *documentRepository) GetByID(ctx context.Context, vehicleID, id int64) (*models.VehicleDocument, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	document, ok := r.documents[id]
+	if !ok || document.VehicleID != vehicleID {
+		return nil, models.ErrNotFound
+	}
+	return &document, nil
+}
+
+func (r *documentRepository) Delete(ctx context.Context, vehicleID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	document, ok := r.documents[id]
+	if !ok || document.VehicleID != vehicleID {
+		return models.ErrNotFound
+	}
+	delete(r.documents, id)
+	return nil
+}
+
+func (r *documentRepository) Expiring(ctx context.Context, by time.Time) ([]models.ExpiringDocument, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.expiringDocuments(by, false), nil
+}
+
+func (r *documentRepository) FlagExpiring(ctx context.Context, by, now time.Time) ([]models.ExpiringDocument, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	flagged := r.expiringDocuments(by, true)
+	flaggedAt := now.UTC()
+	for i := range flagged {
+		flagged[i].FlaggedAt = &flaggedAt
+		r.documents[flagged[i].ID] = flagged[i].VehicleDocument
+	}
+	return flagged, nil
+}
+
+// expiringDocuments must be called with the store lock held.
+func (s *Store) expiringDocuments(by time.Time, unflagged bool) []models.ExpiringDocument {
+	documents := []models.ExpiringDocument{}
+	for _, document := range s.documents {
+		if document.ExpiresOn == nil || document.ExpiresOn.After(by) || (unflagged && document.FlaggedAt != nil) {
+			continue
+		}
+		vehicle := s.vehicles[document.VehicleID]
+		if !slices.Contains(models.InStockStatuses, vehicle.Status) || s.superseded(&document) {
+			continue
+		}
+		documents = append(documents, models.ExpiringDocument{VehicleDocument: document, Vehicle: vehicle})
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		if !documents[i].ExpiresOn.Equal(*documents[j].ExpiresOn) {
+			return documents[i].ExpiresOn.Before(*documents[j].ExpiresOn)
+		}
+		return documents[i].ID < documents[j].ID
+	})
+	return documents
+}
+
+// superseded must be called with the store lock held.
+func (s *Store) superseded(document *models.VehicleDocument) bool {
+	for _, other := range s.documents {
+		if other.Supersedes(document) {
+			return true
+		}
+	}
+	return false
+}
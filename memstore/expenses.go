@@ -0,0 +1,116 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+type expenseRepository struct {
+	*Store
+}
+
+func (r *expenseRepository) Create(ctx context.Context, expense *models.VehicleExpense) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.vehicles[expense.VehicleID]; !ok {
+		return models.ErrNotFound
+	}
+
+	expense.CreatedAt = time.Now()
+	if expense.IncurredOn.IsZero() {
+		expense.IncurredOn = expense.CreatedAt
+	}
+
+	r.nextExpenseID++
+	expense.ID = r.nextExpenseID
+	r.expenses[expense.ID] = *expense
+	return nil
+}
+
+func (r *expenseRepository) ListByVehicle(ctx context.Context, vehicleID int64) ([]models.VehicleExpense, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.vehicles[vehicleID]; !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.vehicleExpenses(vehicleID), nil
+}
+
+func (r *expenseRepository) Delete(ctx context.Context, vehicleID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	expense, ok := r.expenses[id]
+	if !ok || expense.VehicleID != vehicleID {
+		return models.ErrNotFound
+	}
+	delete(r.expenses, id)
+	return nil
+}
+
+func (r *vehicleRepository) Profitability(ctx context.Context, vehicleID int64) (*models.Profitability, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vehicle, ok := r.vehicles[vehicleID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+
+	var salePrice *float64
+	for _, sale := range r.sales {
+		if sale.VehicleID == vehicleID {
+			price := sale.Price
+			salePrice = &price
+		}
+	}
+
+	var consignment *models.Consignment
+	for _, stored := range r.consignments {
+		if stored.VehicleID == vehicleID && stored.Status != models.ConsignmentWithdrawn {
+			consignment = &stored.Consignment
+		}
+	}
+
+	return models.ComputeProfitability(&vehicle, r.vehicleExpenses(vehicleID), salePrice, consignment), nil
+}
+
+// vehicleExpenses returns a vehicle's ledger, oldest first; it must be called
+// with the store lock held.
+func (s *Store) vehicleExpenses(vehicleID int64) []models.VehicleExpense {
+	expenses := []models.VehicleExpense{}
+	for _, expense := range s.expenses {
+		if expense.VehicleID == vehicleID {
+			expenses = append(expenses, expense)
+		}
+	}
+
+	sort.Slice(expenses, func(i, j int) bool {
+		if !expenses[i].IncurredOn.Equal(expenses[j].IncurredOn) {
+			return expenses[i].IncurredOn.Before(expenses[j].IncurredOn)
+		}
+		return expenses[i].ID < expenses[j].ID
+	})
+	return expenses
+}
@@ -0,0 +1,47 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/Stand/models"
+)
+
+type feedRepository struct {
+	*Store
+}
+
+func (r *feedRepository) Record(ctx context.Context, export *models.FeedExport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.nextFeedExportID++
+	export.ID = r.nextFeedExportID
+	r.feedExports = append(r.feedExports, *export)
+	return nil
+}
+
+func (r *feedRepository) Last(ctx context.Context) (*models.FeedExport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var last *models.FeedExport
+	for i := range r.feedExports {
+		export := &r.feedExports[i]
+		if last == nil || !export.ExportedAt.Before(last.ExportedAt) {
+			last = export
+		}
+	}
+	if last == nil {
+		return nil, models.ErrNotFound
+	}
+	result := *last
+	return &result, nil
+}
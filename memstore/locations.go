@@ -0,0 +1,178 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Stand/models"
+)
+
+type locationRepository struct {
+	*Store
+}
+
+func (r *locationRepository) Create(ctx context.Context, location *models.Location) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := r.checkLocationName(location); err != nil {
+		return err
+	}
+
+	r.nextLocationID++
+	location.ID = r.nextLocationID
+	r.locations[location.ID] = *location
+	return nil
+}
+
+func (r *locationRepository) GetAll(ctx context.Context) ([]models.Location, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.sortedLocations(), nil
+}
+
+func (r *locationRepository) GetByID(ctx context.Context, id int64) (*models.Location, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	location, ok := r.locations[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &location, nil
+}
+
+func (r *locationRepository) Update(ctx context.Context, location *models.Location) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.locations[location.ID]; !ok {
+		return models.ErrNotFound
+	}
+	if err := r.checkLocationName(location); err != nil {
+		return err
+	}
+
+	r.locations[location.ID] = *location
+	return nil
+}
+
+func (r *locationRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.locations[id]; !ok {
+		return models.ErrNotFound
+	}
+	for _, vehicle := range r.vehicles {
+		if vehicle.LocationID != nil && *vehicle.LocationID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, transfer := range r.transfers {
+		if transfer.ToLocationID == id || (transfer.FromLocationID != nil && *transfer.FromLocationID == id) {
+			return models.ErrInUse
+		}
+	}
+	for _, drive := range r.testDrives {
+		if drive.LocationID != nil && *drive.LocationID == id {
+			return models.ErrInUse
+		}
+	}
+	delete(r.locations, id)
+	return nil
+}
+
+func (r *locationRepository) Stock(ctx context.Context) ([]models.LocationStock, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		locationID int64
+		assigned   bool
+		status     models.VehicleStatus
+	}
+	totals := map[key]int{}
+	for _, vehicle := range r.vehicles {
+		if !slices.Contains(models.InStockStatuses, vehicle.Status) {
+			continue
+		}
+		k := key{status: vehicle.Status}
+		if vehicle.LocationID != nil {
+			k.locationID, k.assigned = *vehicle.LocationID, true
+		}
+		totals[k]++
+	}
+
+	var counts []models.StockCount
+	for k, total := range totals {
+		count := models.StockCount{Status: k.status, Count: total}
+		if k.assigned {
+			id := k.locationID
+			count.LocationID = &id
+		}
+		counts = append(counts, count)
+	}
+
+	var inTransit []models.VehicleTransfer
+	for _, transfer := range r.transfers {
+		if transfer.Status == models.TransferInTransit {
+			inTransit = append(inTransit, transfer)
+		}
+	}
+
+	return models.CountLocationStock(r.sortedLocations(), counts, inTransit), nil
+}
+
+// sortedLocations returns the locations by name, like the SQL store; it must
+// be called with the store lock held.
+func (s *Store) sortedLocations() []models.Location {
+	locations := []models.Location{}
+	for _, location := range s.locations {
+		locations = append(locations, location)
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].Name != locations[j].Name {
+			return locations[i].Name < locations[j].Name
+		}
+		return locations[i].ID < locations[j].ID
+	})
+	return locations
+}
+
+// checkLocationName mirrors the unique constraint on location names; it must
+// be called with the store lock held.
+func (s *Store) checkLocationName(location *models.Location) error {
+	for _, existing := range s.locations {
+		if existing.ID != location.ID && existing.Name == location.Name {
+			return &models.DuplicateLocationError{Name: location.Name}
+		}
+	}
+	return nil
+}
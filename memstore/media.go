@@ -0,0 +1,168 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+type mediaRepository struct {
+	*Store
+}
+
+func (r *mediaRepository) Create(ctx context.Context, media *models.VehicleMedia) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.vehicles[media.VehicleID]; !ok {
+		return models.ErrNotFound
+	}
+
+	gallery := r.gallery(media.VehicleID)
+	media.Position = len(gallery) + 1
+	media.Cover = true
+	for _, other := range gallery {
+		if other.Cover {
+			media.Cover = false
+		}
+	}
+	media.CreatedAt = time.Now()
+
+	r.nextMediaID++
+	media.ID = r.nextMediaID
+	r.media[media.ID] = *media
+	r.touchGallery(media.VehicleID)
+	return nil
+}
+
+func (r *mediaRepository) ListByVehicle(ctx context.Context, vehicleID int64) ([]models.VehicleMedia, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.vehicles[vehicleID]; !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.gallery(vehicleID), nil
+}
+
+func (r *mediaRepository) GetByID(ctx context.Context, vehicleID, id int64) (*models.VehicleMedia, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	media, ok := r.media[id]
+	if !ok || media.VehicleID != vehicleID {
+		return nil, models.ErrNotFound
+	}
+	return &media, nil
+}
+
+func (r *mediaRepository) Delete(ctx context.Context, vehicleID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	deleted, ok := r.media[id]
+	if !ok || deleted.VehicleID != vehicleID {
+		return models.ErrNotFound
+	}
+	delete(r.media, id)
+
+	for i, media := range r.gallery(vehicleID) {
+		media.Position = i + 1
+		media.Cover = media.Cover || (deleted.Cover && i == 0)
+		r.media[media.ID] = media
+	}
+	r.touchGallery(vehicleID)
+	return nil
+}
+
+func (r *mediaRepository) Reorder(ctx context.Context, vehicleID int64, ids []int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.vehicles[vehicleID]; !ok {
+		return models.ErrNotFound
+	}
+	if err := models.CheckMediaOrder(r.gallery(vehicleID), ids); err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		media := r.media[id]
+		media.Position = i + 1
+		r.media[id] = media
+	}
+	r.touchGallery(vehicleID)
+	return nil
+}
+
+func (r *mediaRepository) SetCover(ctx context.Context, vehicleID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.vehicles[vehicleID]; !ok {
+		return models.ErrNotFound
+	}
+	if media, ok := r.media[id]; !ok || media.VehicleID != vehicleID {
+		return models.ErrNotFound
+	}
+
+	for _, media := range r.gallery(vehicleID) {
+		media.Cover = media.ID == id
+		r.media[media.ID] = media
+	}
+	r.touchGallery(vehicleID)
+	return nil
+}
+
+// touchGallery marks a vehicle whose gallery changed as updated for the
+// incremental feed; it must be called with the store lock held.
+func (s *Store) touchGallery(vehicleID int64) {
+	vehicle := s.vehicles[vehicleID]
+	vehicle.UpdatedAt = time.Now().UTC()
+	s.vehicles[vehicleID] = vehicle
+}
+
+// gallery returns a vehicle's media in order; it must be called with the
+// store lock held.
+func (s *Store) gallery(vehicleID int64) []models.VehicleMedia {
+	gallery := []models.VehicleMedia{}
+	for _, media := range s.media {
+		if media.VehicleID == vehicleID {
+			gallery = append(gallery, media)
+		}
+	}
+
+	sort.Slice(gallery, func(i, j int) bool {
+		if gallery[i].Position != gallery[j].Position {
+			return gallery[i].Position < gallery[j].Position
+		}
+		return gallery[i].ID < gallery[j].ID
+	})
+	return gallery
+}
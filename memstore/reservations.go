@@ -0,0 +1,177 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+type reservationRepository struct {
+	*Store
+}
+
+func (r *reservationRepository) Create(ctx context.Context, reservation *models.Reservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	vehicle, ok := r.vehicles[reservation.VehicleID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if err := models.CheckTransition(vehicle.Status, models.StatusReserved); err != nil {
+		return err
+	}
+	if _, ok := r.clients[reservation.ClientID]; !ok {
+		return models.ErrNotFound
+	}
+
+	r.nextReservationID++
+	reservation.ID = r.nextReservationID
+	reservation.Status = models.ReservationActive
+	reservation.CreatedAt = time.Now().UTC()
+	reservation.ExpiresAt = reservation.ExpiresAt.UTC()
+	reservation.ClosedAt = nil
+	reservation.SaleID = nil
+	r.reservations[reservation.ID] = *reservation
+
+	return r.transition(&models.VehicleStatusChange{
+		VehicleID: reservation.VehicleID,
+		To:        models.StatusReserved,
+		Reason:    fmt.Sprintf("reservation %d", reservation.ID),
+		Actor:     models.SystemActor,
+	})
+}
+
+func (r *reservationRepository) GetAll(ctx context.Context) ([]models.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var reservations []models.Reservation
+	for _, reservation := range r.reservations {
+		reservations = append(reservations, reservation)
+	}
+
+	sort.Slice(reservations, func(i, j int) bool { return reservations[i].ID > reservations[j].ID })
+	return reservations, nil
+}
+
+func (r *reservationRepository) GetByID(ctx context.Context, id int64) (*models.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	reservation, ok := r.reservations[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &reservation, nil
+}
+
+func (r *reservationRepository) Cancel(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	reservation, ok := r.reservations[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if reservation.Status != models.ReservationActive {
+		return &models.ReservationNotActiveError{ReservationID: id, Status: reservation.Status}
+	}
+
+	r.releaseReservation(&reservation, models.ReservationCancelled, fmt.Sprintf("reservation %d cancelled", id))
+	return nil
+}
+
+func (r *reservationRepository) Convert(ctx context.Context, id int64, sale *models.Sale) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	reservation, ok := r.reservations[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+
+	sale.ClientID = reservation.ClientID
+	sale.VehicleID = reservation.VehicleID
+	sale.ReservationID = &reservation.ID
+	return r.createSale(sale)
+}
+
+func (r *reservationRepository) ExpireDue(ctx context.Context, now time.Time) ([]models.Reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var expired []models.Reservation
+	for _, reservation := range r.reservations {
+		if reservation.Status == models.ReservationActive && !reservation.ExpiresAt.After(now) {
+			r.releaseReservation(&reservation, models.ReservationExpired, fmt.Sprintf("reservation %d expired", reservation.ID))
+			expired = append(expired, reservation)
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ID < expired[j].ID })
+	return expired, nil
+}
+
+// activeReservation returns the active reservation on a vehicle, or nil; it
+// must be called with the store lock held.
+func (s *Store) activeReservation(vehicleID int64) *models.Reservation {
+	for _, reservation := range s.reservations {
+		if reservation.VehicleID == vehicleID && reservation.Status == models.ReservationActive {
+			return &reservation
+		}
+	}
+	return nil
+}
+
+// releaseReservation closes an active reservation and makes its vehicle
+// available again; it must be called with the store lock held.
+func (s *Store) releaseReservation(reservation *models.Reservation, status models.ReservationStatus, reason string) {
+	s.closeReservation(reservation, status, nil)
+
+	if s.vehicles[reservation.VehicleID].Status != models.StatusReserved {
+		return
+	}
+	s.transition(&models.VehicleStatusChange{
+		VehicleID: reservation.VehicleID,
+		To:        models.StatusAvailable,
+		Reason:    reason,
+		Actor:     models.SystemActor,
+	})
+}
+
+// closeReservation must be called with the store lock held.
+func (s *Store) closeReservation(reservation *models.Reservation, status models.ReservationStatus, saleID *int64) {
+	closedAt := time.Now().UTC()
+	reservation.Status = status
+	reservation.ClosedAt = &closedAt
+	reservation.SaleID = saleID
+	s.reservations[reservation.ID] = *reservation
+}
@@ -0,0 +1,215 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+type saleRepository struct {
+	*Store
+}
+
+func (r *saleRepository) Create(ctx context.Context, sale *models.Sale) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.createSale(sale)
+}
+
+// createSale records a sale, converting the reservation that holds the
+// vehicle if it belongs to the buyer; it must be called with the store lock
+// held.
+func (s *Store) createSale(sale *models.Sale) error {
+	for _, existing := range s.sales {
+		if existing.VehicleID == sale.VehicleID {
+			return &models.VehicleAlreadySoldError{VehicleID: sale.VehicleID}
+		}
+	}
+
+	vehicle, ok := s.vehicles[sale.VehicleID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if vehicle.Status == models.StatusSold {
+		return &models.VehicleAlreadySoldError{VehicleID: sale.VehicleID}
+	}
+
+	// Validate everything before changing the store, which has no rollback.
+	now := time.Now()
+	reservation := s.activeReservation(sale.VehicleID)
+	status := vehicle.Status
+	expired := reservation != nil && !reservation.Holds(now)
+	if expired {
+		status = models.StatusAvailable
+	}
+
+	holding := reservation
+	if expired {
+		holding = nil
+	}
+	if sale.ReservationID != nil && (holding == nil || holding.ID != *sale.ReservationID) {
+		closed, ok := s.reservations[*sale.ReservationID]
+		if !ok {
+			return models.ErrNotFound
+		}
+		if closed.Status == models.ReservationActive && expired {
+			closed.Status = models.ReservationExpired
+		}
+		return &models.ReservationNotActiveError{ReservationID: closed.ID, Status: closed.Status}
+	}
+	if holding != nil && holding.ClientID != sale.ClientID {
+		return &models.VehicleReservedError{VehicleID: sale.VehicleID, ReservationID: holding.ID, ExpiresAt: holding.ExpiresAt}
+	}
+
+	if err := models.CheckTransition(status, models.StatusSold); err != nil {
+		return err
+	}
+	consignment := s.activeConsignment(sale.VehicleID)
+	if consignment != nil {
+		if err := consignment.CheckPrice(sale.Price); err != nil {
+			return err
+		}
+	}
+	if _, ok := s.clients[sale.ClientID]; !ok {
+		return models.ErrNotFound
+	}
+
+	clientID := sale.ClientID
+	sale.TradeInCredit = 0
+	for i := range sale.TradeIns {
+		tradeIn := &sale.TradeIns[i]
+		vehicle := &tradeIn.Vehicle
+		vehicle.ID = 0
+		vehicle.Status = models.StatusIncoming
+		vehicle.PurchasePrice = tradeIn.Valuation
+		vehicle.PreviousOwnerID = &clientID
+		vehicle.AcquisitionDate = &now
+		sale.TradeInCredit += tradeIn.Valuation
+
+		if err := s.checkVehicle(vehicle); err != nil {
+			return &models.BatchError{Index: i, Err: err}
+		}
+		for _, other := range sale.TradeIns[:i] {
+			if err := checkUnique(vehicle, &other.Vehicle); err != nil {
+				return &models.BatchError{Index: i, Err: err}
+			}
+		}
+	}
+
+	if expired {
+		s.releaseReservation(reservation, models.ReservationExpired, fmt.Sprintf("reservation %d expired", reservation.ID))
+	}
+	if holding != nil {
+		sale.ReservationID = &holding.ID
+		sale.DepositCredited = holding.Deposit
+	}
+
+	s.nextSaleID++
+	sale.ID = s.nextSaleID
+	sale.SaleDate = now
+	sale.AmountDue = sale.Price - sale.DepositCredited - sale.TradeInCredit
+
+	if holding != nil {
+		s.closeReservation(holding, models.ReservationConverted, &sale.ID)
+	}
+	sale.ConsignmentID = nil
+	if consignment != nil {
+		sale.ConsignmentID = &consignment.ID
+		s.sellConsignment(consignment, sale)
+	}
+
+	for i := range sale.TradeIns {
+		s.insertVehicle(&sale.TradeIns[i].Vehicle, fmt.Sprintf("trade-in for sale %d", sale.ID))
+	}
+	stored := *sale
+	stored.TradeIns = append([]models.TradeIn(nil), sale.TradeIns...)
+	s.sales[sale.ID] = stored
+
+	return s.transition(&models.VehicleStatusChange{
+		VehicleID: sale.VehicleID,
+		To:        models.StatusSold,
+		Reason:    fmt.Sprintf("sale %d", sale.ID),
+		Actor:     models.SystemActor,
+	})
+}
+
+func (r *saleRepository) GetAll(ctx context.Context) ([]models.SaleWithDetails, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var sales []models.SaleWithDetails
+	for _, sale := range r.sales {
+		sales = append(sales, r.details(sale))
+	}
+
+	sort.Slice(sales, func(i, j int) bool { return sales[i].SaleDate.After(sales[j].SaleDate) })
+	return sales, nil
+}
+
+func (r *saleRepository) GetByID(ctx context.Context, id int64) (*models.SaleWithDetails, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sale, ok := r.sales[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+
+	details := r.details(sale)
+	return &details, nil
+}
+
+// details must be called with the store lock held.
+func (r *saleRepository) details(sale models.Sale) models.SaleWithDetails {
+	vehicle := r.vehicles[sale.VehicleID]
+	var expenses float64
+	for _, expense := range r.vehicleExpenses(sale.VehicleID) {
+		expenses += expense.Amount
+	}
+	var amountOwed *float64
+	if sale.ConsignmentID != nil {
+		owed := r.consignments[*sale.ConsignmentID].amountOwed
+		amountOwed = &owed
+	}
+	cost := models.VehicleCost(&vehicle, expenses, amountOwed)
+
+	var tradeIns []models.TradeIn
+	for _, tradeIn := range sale.TradeIns {
+		tradeIn.Vehicle = r.vehicles[int64(tradeIn.Vehicle.ID)]
+		tradeIns = append(tradeIns, tradeIn)
+	}
+
+	return models.SaleWithDetails{
+		ID:       sale.ID,
+		Price:    sale.Price,
+		SaleDate: sale.SaleDate,
+		Client:   r.clients[sale.ClientID],
+		Vehicle:  vehicle,
+
+		ReservationID:   sale.ReservationID,
+		DepositCredited: sale.DepositCredited,
+		ConsignmentID:   sale.ConsignmentID,
+		TradeIns:        tradeIns,
+		TradeInCredit:   sale.TradeInCredit,
+		AmountDue:       sale.AmountDue,
+
+		Cost:        cost,
+		GrossMargin: sale.Price - cost,
+	}
+}
@@ -0,0 +1,139 @@
+package memstore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Stand/catalog"
+	"github.com/Stand/models"
+)
+
+// Store is a thread-safe, in-memory implementation of the model repositories.
+// It enforces the same constraints as the SQL schema (one sale per vehicle,
+// one active reservation, consignment and transfer per vehicle, unique location
+// names, no overlapping test drives, foreign keys between sales, reservations,
+// consignments, transfers, test drives, locations, clients and vehicles),
+// which makes it suitable
+// for unit tests and demos that need a Stand API without a database.
+type Store struct {
+	mu sync.RWMutex
+
+	vehicles map[int64]models.Vehicle
+	clients  map[int64]models.Client
+	sales    map[int64]models.Sale
+	history  map[int64][]models.VehicleStatusChange
+	// reservations is keyed by reservation ID.
+	reservations map[int64]models.Reservation
+	expenses     map[int64]models.VehicleExpense
+	prices       map[int64][]models.PriceChange
+	media        map[int64]models.VehicleMedia
+	documents    map[int64]models.VehicleDocument
+	feedExports  []models.FeedExport
+	brands       map[int64]models.Brand
+	brandModels  map[int64]models.BrandModel
+	consignments map[int64]storedConsignment
+	locations    map[int64]models.Location
+	transfers    map[int64]models.VehicleTransfer
+	testDrives   map[int64]models.TestDrive
+
+	nextVehicleID     int64
+	nextClientID      int64
+	nextSaleID        int64
+	nextChangeID      int64
+	nextReservationID int64
+	nextExpenseID     int64
+	nextPriceChangeID int64
+	nextMediaID       int64
+	nextDocumentID    int64
+	nextFeedExportID  int64
+	nextBrandID       int64
+	nextBrandModelID  int64
+	nextConsignmentID int64
+	nextLocationID    int64
+	nextTransferID    int64
+	nextTestDriveID   int64
+}
+
+// New returns an empty store with the bundled catalogue.
+func New() *Store {
+	s := &Store{
+		vehicles: map[int64]models.Vehicle{},
+		clients:  map[int64]models.Client{},
+		sales:    map[int64]models.Sale{},
+		history:  map[int64][]models.VehicleStatusChange{},
+
+		reservations: map[int64]models.Reservation{},
+		expenses:     map[int64]models.VehicleExpense{},
+		prices:       map[int64][]models.PriceChange{},
+		media:        map[int64]models.VehicleMedia{},
+		documents:    map[int64]models.VehicleDocument{},
+		brands:       map[int64]models.Brand{},
+		brandModels:  map[int64]models.BrandModel{},
+		consignments: map[int64]storedConsignment{},
+		locations:    map[int64]models.Location{},
+		transfers:    map[int64]models.VehicleTransfer{},
+		testDrives:   map[int64]models.TestDrive{},
+	}
+
+	for _, entry := range catalog.Bundled() {
+		s.nextBrandID++
+		entry.Brand.ID = s.nextBrandID
+		s.brands[entry.Brand.ID] = entry.Brand
+		for _, model := range entry.Models {
+			s.nextBrandModelID++
+			model.ID = s.nextBrandModelID
+			model.BrandID = entry.Brand.ID
+			s.brandModels[model.ID] = model
+		}
+	}
+	return s
+}
+
+// Repositories returns the model repositories backed by this store.
+func (s *Store) Repositories() models.Repositories {
+	return models.Repositories{
+		Vehicles:     &vehicleRepository{s},
+		Clients:      &clientRepository{s},
+		Sales:        &saleRepository{s},
+		Reservations: &reservationRepository{s},
+		Expenses:     &expenseRepository{s},
+		Media:        &mediaRepository{s},
+		Documents:    &documentRepository{s},
+		Feeds:        &feedRepository{s},
+		Catalog:      &catalogRepository{s},
+		Consignments: &consignmentRepository{s},
+		Locations:    &locationRepository{s},
+		Transfers:    &transferRepository{s},
+		TestDrives:   &testDriveRepository{s},
+	}
+}
+
+// transition checks and applies a status change; it must be called with the
+// store lock held.
+func (s *Store) transition(change *models.VehicleStatusChange) error {
+	vehicle, ok := s.vehicles[change.VehicleID]
+	if !ok {
+		return models.ErrNotFound
+	}
+
+	if err := models.CheckTransition(vehicle.Status, change.To); err != nil {
+		return err
+	}
+
+	from := vehicle.Status
+	vehicle.Status = change.To
+	vehicle.UpdatedAt = time.Now().UTC()
+	s.vehicles[change.VehicleID] = vehicle
+
+	change.From = &from
+	s.recordStatusChange(change)
+	return nil
+}
+
+// recordStatusChange must be called with the store lock held.
+func (s *Store) recordStatusChange(change *models.VehicleStatusChange) {
+	s.nextChangeID++
+	change.ID = s.nextChangeID
+	change.ChangedAt = time.Now()
+	s.history[change.VehicleID] = append(s.history[change.VehicleID], *change)
+}
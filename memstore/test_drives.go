@@ -0,0 +1,140 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+type testDriveRepository struct {
+	*Store
+}
+
+func (r *testDriveRepository) Book(ctx context.Context, drive *models.TestDrive) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	vehicle, ok := r.vehicles[drive.VehicleID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if _, ok := r.clients[drive.ClientID]; !ok {
+		return models.ErrNotFound
+	}
+	if err := models.CheckBookable(drive.VehicleID, vehicle.Status); err != nil {
+		return err
+	}
+	for _, open := range r.transfers {
+		if open.VehicleID == drive.VehicleID && open.Status.Open() {
+			return &models.VehicleInTransferError{VehicleID: drive.VehicleID, TransferID: open.ID}
+		}
+	}
+
+	// Report the earliest conflict, like the SQL store.
+	var conflict *models.TestDrive
+	for _, other := range r.testDrives {
+		if !drive.Conflicts(&other) {
+			continue
+		}
+		if conflict == nil || other.StartsAt.Before(conflict.StartsAt) ||
+			(other.StartsAt.Equal(conflict.StartsAt) && other.ID < conflict.ID) {
+			conflict = &other
+		}
+	}
+	if conflict != nil {
+		return models.NewTestDriveConflictError(drive, conflict)
+	}
+
+	r.nextTestDriveID++
+	drive.ID = r.nextTestDriveID
+	drive.LocationID = vehicle.LocationID
+	drive.Status = models.TestDriveScheduled
+	drive.CreatedAt = time.Now().UTC()
+	drive.CancelledAt = nil
+	r.testDrives[drive.ID] = *drive
+	return nil
+}
+
+func (r *testDriveRepository) Find(ctx context.Context, filter models.TestDriveFilter) ([]models.TestDriveWithDetails, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	drives := []models.TestDriveWithDetails{}
+	for _, drive := range r.testDrives {
+		if filter.Matches(&drive) {
+			drives = append(drives, r.testDriveDetails(drive))
+		}
+	}
+
+	sort.Slice(drives, func(i, j int) bool {
+		if !drives[i].StartsAt.Equal(drives[j].StartsAt) {
+			return drives[i].StartsAt.Before(drives[j].StartsAt)
+		}
+		return drives[i].ID < drives[j].ID
+	})
+	return drives, nil
+}
+
+func (r *testDriveRepository) GetByID(ctx context.Context, id int64) (*models.TestDriveWithDetails, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	drive, ok := r.testDrives[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	details := r.testDriveDetails(drive)
+	return &details, nil
+}
+
+func (r *testDriveRepository) Cancel(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	drive, ok := r.testDrives[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if drive.Status != models.TestDriveScheduled {
+		return &models.TestDriveNotScheduledError{TestDriveID: id, Status: drive.Status}
+	}
+
+	cancelledAt := time.Now().UTC()
+	drive.Status = models.TestDriveCancelled
+	drive.CancelledAt = &cancelledAt
+	r.testDrives[id] = drive
+	return nil
+}
+
+// testDriveDetails must be called with the store lock held.
+func (s *Store) testDriveDetails(drive models.TestDrive) models.TestDriveWithDetails {
+	details := models.TestDriveWithDetails{
+		TestDrive: drive,
+		Client:    s.clients[drive.ClientID],
+		Vehicle:   s.vehicles[drive.VehicleID],
+	}
+	if drive.LocationID != nil {
+		if location, ok := s.locations[*drive.LocationID]; ok {
+			details.Location = &location
+		}
+	}
+	return details
+}
@@ -0,0 +1,168 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+type transferRepository struct {
+	*Store
+}
+
+func (r *transferRepository) Request(ctx context.Context, transfer *models.VehicleTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	vehicle, ok := r.vehicles[transfer.VehicleID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	for _, open := range r.transfers {
+		if open.VehicleID == transfer.VehicleID && open.Status.Open() {
+			return &models.VehicleInTransferError{VehicleID: transfer.VehicleID, TransferID: open.ID}
+		}
+	}
+	if _, ok := r.locations[transfer.ToLocationID]; !ok {
+		return models.ErrNotFound
+	}
+	if err := transfer.Check(vehicle.Status, vehicle.LocationID); err != nil {
+		return err
+	}
+
+	r.nextTransferID++
+	transfer.ID = r.nextTransferID
+	transfer.FromLocationID = vehicle.LocationID
+	transfer.Status = models.TransferRequested
+	transfer.RequestedAt = time.Now().UTC()
+	transfer.DispatchedAt, transfer.ReceivedAt, transfer.CancelledAt = nil, nil, nil
+	r.transfers[transfer.ID] = *transfer
+	return nil
+}
+
+func (r *transferRepository) GetAll(ctx context.Context, vehicleID *int64) ([]models.VehicleTransfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	transfers := []models.VehicleTransfer{}
+	for _, transfer := range r.transfers {
+		if vehicleID == nil || transfer.VehicleID == *vehicleID {
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	sort.Slice(transfers, func(i, j int) bool {
+		if !transfers[i].RequestedAt.Equal(transfers[j].RequestedAt) {
+			return transfers[i].RequestedAt.After(transfers[j].RequestedAt)
+		}
+		return transfers[i].ID > transfers[j].ID
+	})
+	return transfers, nil
+}
+
+func (r *transferRepository) GetByID(ctx context.Context, id int64) (*models.VehicleTransfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	transfer, ok := r.transfers[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) Dispatch(ctx context.Context, id int64, driver string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	transfer, ok := r.transfers[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if transfer.Status != models.TransferRequested {
+		return &models.TransferStatusError{TransferID: id, Status: transfer.Status}
+	}
+	if driver == "" {
+		driver = transfer.Driver
+	}
+	if driver == "" {
+		return models.ValidationErrors{{Field: "driver", Message: "is required"}}
+	}
+
+	dispatchedAt := time.Now().UTC()
+	transfer.Status = models.TransferInTransit
+	transfer.Driver = driver
+	transfer.DispatchedAt = &dispatchedAt
+	r.transfers[id] = transfer
+	return nil
+}
+
+func (r *transferRepository) Receive(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	transfer, ok := r.transfers[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if transfer.Status != models.TransferInTransit {
+		return &models.TransferStatusError{TransferID: id, Status: transfer.Status}
+	}
+
+	receivedAt := time.Now().UTC()
+	transfer.Status = models.TransferReceived
+	transfer.ReceivedAt = &receivedAt
+	r.transfers[id] = transfer
+
+	vehicle := r.vehicles[transfer.VehicleID]
+	locationID := transfer.ToLocationID
+	vehicle.LocationID = &locationID
+	vehicle.UpdatedAt = receivedAt
+	r.vehicles[transfer.VehicleID] = vehicle
+	return nil
+}
+
+func (r *transferRepository) Cancel(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	transfer, ok := r.transfers[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if transfer.Status != models.TransferRequested {
+		return &models.TransferStatusError{TransferID: id, Status: transfer.Status}
+	}
+
+	cancelledAt := time.Now().UTC()
+	transfer.Status = models.TransferCancelled
+	transfer.CancelledAt = &cancelledAt
+	r.transfers[id] = transfer
+	return nil
+}
@@ -0,0 +1,90 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Stand/models"
+)
+
+func (r *vehicleRepository) Facets(ctx context.Context, filter models.VehicleFilter) (*models.VehicleFacets, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	facets := &models.VehicleFacets{}
+	for _, vehicle := range r.vehicles {
+		if filter.Matches(&vehicle) {
+			facets.Total++
+		}
+	}
+
+	// Each facet drops the filter on its own attribute.
+	withoutTypes, withoutBrands, withoutModels := filter, filter, filter
+	withoutTypes.Types = nil
+	withoutBrands.Brands = nil
+	withoutModels.Models = nil
+	withoutYears, withoutStatuses, withoutPrices := filter, filter, filter
+	withoutYears.YearMin, withoutYears.YearMax = nil, nil
+	withoutStatuses.Statuses = nil
+	withoutPrices.PriceMin, withoutPrices.PriceMax = nil, nil
+
+	facets.Types = r.valueFacet(withoutTypes, func(v *models.Vehicle) string { return v.Type })
+	facets.Brands = r.valueFacet(withoutBrands, func(v *models.Vehicle) string { return v.Brand })
+	facets.Models = r.valueFacet(withoutModels, func(v *models.Vehicle) string { return v.Model })
+	facets.Statuses = r.valueFacet(withoutStatuses, func(v *models.Vehicle) string { return string(v.Status) })
+	facets.Years = r.bucketFacet(withoutYears, func(v *models.Vehicle) int { return models.YearBucket(v.Year) }, models.YearBucketFacet)
+	facets.PriceBands = r.bucketFacet(withoutPrices, func(v *models.Vehicle) int { return models.PriceBand(v.AskingPrice) }, models.PriceBandFacet)
+
+	return facets, nil
+}
+
+// valueFacet counts the matching vehicles per folded value, labelled with
+// the smallest original spelling like the SQL store.
+func (r *vehicleRepository) valueFacet(filter models.VehicleFilter, value func(v *models.Vehicle) string) []models.FacetCount {
+	byKey := map[string]*models.FacetCount{}
+	for _, vehicle := range r.vehicles {
+		if !filter.Matches(&vehicle) {
+			continue
+		}
+		label := value(&vehicle)
+		key := models.Fold(label)
+		if facet, ok := byKey[key]; ok {
+			facet.Count++
+			facet.Value = min(facet.Value, label)
+		} else {
+			byKey[key] = &models.FacetCount{Value: label, Count: 1}
+		}
+	}
+
+	counts := []models.FacetCount{}
+	for _, facet := range byKey {
+		counts = append(counts, *facet)
+	}
+	models.SortFacetCounts(counts)
+	return counts
+}
+
+func (r *vehicleRepository) bucketFacet(filter models.VehicleFilter, bucket func(v *models.Vehicle) int, describe func(bucket, count int) models.FacetCount) []models.FacetCount {
+	byBucket := map[int]int{}
+	for _, vehicle := range r.vehicles {
+		if filter.Matches(&vehicle) {
+			byBucket[bucket(&vehicle)]++
+		}
+	}
+
+	buckets := make([]int, 0, len(byBucket))
+	for b := range byBucket {
+		buckets = append(buckets, b)
+	}
+	sort.Ints(buckets)
+
+	counts := []models.FacetCount{}
+	for _, b := range buckets {
+		counts = append(counts, describe(b, byBucket[b]))
+	}
+	return counts
+}
@@ -0,0 +1,83 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+func (r *vehicleRepository) Stock(ctx context.Context) ([]models.StockVehicle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stock := []models.StockVehicle{}
+	for id, vehicle := range r.vehicles {
+		if !slices.Contains(models.InStockStatuses, vehicle.Status) {
+			continue
+		}
+
+		item := models.StockVehicle{Vehicle: vehicle, Since: time.Now()}
+		if vehicle.AcquisitionDate != nil {
+			item.Since = *vehicle.AcquisitionDate
+		} else if history := r.history[id]; len(history) > 0 {
+			item.Since = history[0].ChangedAt
+		}
+		for _, expense := range r.vehicleExpenses(id) {
+			item.Expenses += expense.Amount
+		}
+		stock = append(stock, item)
+	}
+
+	sort.Slice(stock, func(i, j int) bool { return stock[i].Vehicle.ID < stock[j].Vehicle.ID })
+	return stock, nil
+}
+
+func (r *vehicleRepository) PriceHistory(ctx context.Context, vehicleID int64) ([]models.PriceChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.vehicles[vehicleID]; !ok {
+		return nil, models.ErrNotFound
+	}
+
+	return append([]models.PriceChange{}, r.prices[vehicleID]...), nil
+}
+
+func (r *vehicleRepository) Reprice(ctx context.Context, change *models.PriceChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	vehicle, ok := r.vehicles[change.VehicleID]
+	if !ok || vehicle.Status != models.StatusAvailable || vehicle.AskingPrice != change.OldPrice {
+		return models.ErrVehicleChanged
+	}
+
+	vehicle.AskingPrice = change.NewPrice
+	vehicle.UpdatedAt = time.Now().UTC()
+	r.vehicles[change.VehicleID] = vehicle
+	r.recordPriceChange(change)
+	return nil
+}
+
+// recordPriceChange must be called with the store lock held.
+func (s *Store) recordPriceChange(change *models.PriceChange) {
+	s.nextPriceChangeID++
+	change.ID = s.nextPriceChangeID
+	change.ChangedAt = time.Now()
+	s.prices[change.VehicleID] = append(s.prices[change.VehicleID], *change)
+}
@@ -0,0 +1,329 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+type vehicleRepository struct {
+	*Store
+}
+
+func (r *vehicleRepository) Create(ctx context.Context, vehicle *models.Vehicle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := r.checkVehicle(vehicle); err != nil {
+		return err
+	}
+
+	r.insertVehicle(vehicle, "vehicle created")
+	return nil
+}
+
+func (r *vehicleRepository) CreateBatch(ctx context.Context, vehicles []models.Vehicle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for i := range vehicles {
+		if err := r.checkVehicle(&vehicles[i]); err != nil {
+			return &models.BatchError{Index: i, Err: err}
+		}
+		for j := range vehicles[:i] {
+			if err := checkUnique(&vehicles[i], &vehicles[j]); err != nil {
+				return &models.BatchError{Index: i, Err: err}
+			}
+		}
+	}
+
+	for i := range vehicles {
+		r.insertVehicle(&vehicles[i], "vehicle imported")
+	}
+	return nil
+}
+
+func (r *vehicleRepository) GetAll(ctx context.Context) ([]models.Vehicle, error) {
+	page, err := r.Find(ctx, models.VehicleQuery{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+func (r *vehicleRepository) GetByID(ctx context.Context, id int64) (*models.Vehicle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vehicle, ok := r.vehicles[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &vehicle, nil
+}
+
+func (r *vehicleRepository) FindByIdentity(ctx context.Context, vins, plates []string) ([]models.Vehicle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vehicles := []models.Vehicle{}
+	for _, vehicle := range r.vehicles {
+		if (vehicle.VIN != "" && slices.Contains(vins, vehicle.VIN)) ||
+			(vehicle.LicensePlate != "" && slices.Contains(plates, vehicle.LicensePlate)) {
+			vehicles = append(vehicles, vehicle)
+		}
+	}
+	sort.Slice(vehicles, func(i, j int) bool { return vehicles[i].ID < vehicles[j].ID })
+	return vehicles, nil
+}
+
+func (r *vehicleRepository) Find(ctx context.Context, query models.VehicleQuery) (*models.VehiclePage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	matching := []models.Vehicle{}
+	for _, vehicle := range r.vehicles {
+		if query.Filter.Matches(&vehicle) {
+			matching = append(matching, vehicle)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return models.CompareVehicles(&matching[i], &matching[j], query.Sort) < 0
+	})
+
+	page := &models.VehiclePage{Items: []models.Vehicle{}, Total: len(matching), Limit: query.Limit, Offset: query.Offset}
+
+	if query.After != nil {
+		start := sort.Search(len(matching), func(i int) bool {
+			return query.After.After(&matching[i], query.Sort)
+		})
+		matching = matching[start:]
+	}
+	if query.Limit == 0 {
+		page.Items = matching
+		return page, nil
+	}
+
+	matching = matching[min(query.Offset, len(matching)):]
+	if len(matching) > query.Limit {
+		page.Items = matching[:query.Limit]
+		page.NextCursor = models.NewVehicleCursor(&page.Items[query.Limit-1], query.Sort).Encode()
+	} else {
+		page.Items = matching
+	}
+	return page, nil
+}
+
+func (r *vehicleRepository) Update(ctx context.Context, vehicle *models.Vehicle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	id := int64(vehicle.ID)
+	existing, ok := r.vehicles[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	vehicle.LocationID = existing.LocationID
+	if vehicle.PurchasePrice != 0 {
+		for _, stored := range r.consignments {
+			if stored.VehicleID == id && stored.Status != models.ConsignmentWithdrawn {
+				return &models.ConsignedPurchasePriceError{VehicleID: id}
+			}
+		}
+	}
+	if err := r.checkVehicle(vehicle); err != nil {
+		return err
+	}
+
+	vehicle.UpdatedAt = time.Now().UTC()
+	updated := *vehicle
+	updated.Status = existing.Status
+	r.vehicles[id] = updated
+
+	if vehicle.AskingPrice != existing.AskingPrice {
+		r.recordPriceChange(&models.PriceChange{
+			VehicleID: id,
+			OldPrice:  existing.AskingPrice,
+			NewPrice:  vehicle.AskingPrice,
+			Reason:    models.ManualPriceReason,
+		})
+	}
+	return nil
+}
+
+func (r *vehicleRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.vehicles[id]; !ok {
+		return models.ErrNotFound
+	}
+	for _, sale := range r.sales {
+		if sale.VehicleID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, reservation := range r.reservations {
+		if reservation.VehicleID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, consignment := range r.consignments {
+		if consignment.VehicleID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, drive := range r.testDrives {
+		if drive.VehicleID == id {
+			return models.ErrInUse
+		}
+	}
+	for _, sale := range r.sales {
+		for _, tradeIn := range sale.TradeIns {
+			if int64(tradeIn.Vehicle.ID) == id {
+				return models.ErrInUse
+			}
+		}
+	}
+	delete(r.vehicles, id)
+	delete(r.history, id)
+	delete(r.prices, id)
+	for expenseID, expense := range r.expenses {
+		if expense.VehicleID == id {
+			delete(r.expenses, expenseID)
+		}
+	}
+	for mediaID, media := range r.media {
+		if media.VehicleID == id {
+			delete(r.media, mediaID)
+		}
+	}
+	for documentID, document := range r.documents {
+		if document.VehicleID == id {
+			delete(r.documents, documentID)
+		}
+	}
+	for transferID, transfer := range r.transfers {
+		if transfer.VehicleID == id {
+			delete(r.transfers, transferID)
+		}
+	}
+	return nil
+}
+
+func (r *vehicleRepository) Transition(ctx context.Context, change *models.VehicleStatusChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// A reserved vehicle is released by cancelling its reservation.
+	if reservation := r.activeReservation(change.VehicleID); reservation != nil && r.vehicles[change.VehicleID].Status == models.StatusReserved {
+		return &models.VehicleReservedError{VehicleID: change.VehicleID, ReservationID: reservation.ID, ExpiresAt: reservation.ExpiresAt}
+	}
+
+	return r.transition(change)
+}
+
+func (r *vehicleRepository) StatusHistory(ctx context.Context, vehicleID int64) ([]models.VehicleStatusChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.vehicles[vehicleID]; !ok {
+		return nil, models.ErrNotFound
+	}
+
+	return append([]models.VehicleStatusChange(nil), r.history[vehicleID]...), nil
+}
+
+// checkVehicle resolves the brand and model against the catalogue and
+// enforces the unique VIN and licence plate indexes and the previous owner
+// foreign key; it must be called with the store lock held.
+func (s *Store) checkVehicle(vehicle *models.Vehicle) error {
+	if err := s.catalog().Resolve(vehicle); err != nil {
+		return err
+	}
+	if vehicle.PreviousOwnerID != nil {
+		if _, ok := s.clients[*vehicle.PreviousOwnerID]; !ok {
+			return models.ValidationErrors{{Field: "PreviousOwnerID", Message: "client not found"}}
+		}
+	}
+	if vehicle.LocationID != nil {
+		if _, ok := s.locations[*vehicle.LocationID]; !ok {
+			return models.ValidationErrors{{Field: "LocationID", Message: "location not found"}}
+		}
+	}
+
+	for id, other := range s.vehicles {
+		if int(id) == vehicle.ID {
+			continue
+		}
+		if err := checkUnique(vehicle, &other); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkUnique(vehicle, other *models.Vehicle) error {
+	if vehicle.VIN != "" && other.VIN == vehicle.VIN {
+		return &models.DuplicateVehicleError{Field: "VIN"}
+	}
+	if vehicle.LicensePlate != "" && other.LicensePlate == vehicle.LicensePlate {
+		return &models.DuplicateVehicleError{Field: "LicensePlate"}
+	}
+	return nil
+}
+
+// insertVehicle stores a vehicle already checked by checkVehicle and records
+// its initial status with reason; it must be called with the store lock held.
+func (s *Store) insertVehicle(vehicle *models.Vehicle, reason string) {
+	s.nextVehicleID++
+	vehicle.ID = int(s.nextVehicleID)
+	vehicle.UpdatedAt = time.Now().UTC()
+	s.vehicles[s.nextVehicleID] = *vehicle
+
+	s.recordStatusChange(&models.VehicleStatusChange{
+		VehicleID: s.nextVehicleID,
+		To:        vehicle.Status,
+		Reason:    reason,
+		Actor:     models.SystemActor,
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Stand/config"
+	"github.com/Stand/db"
+)
+
+const migrateUsage = "usage: stand_api migrate [up|down|status|to <version>]"
+
+// runMigrate implements the "migrate" command.
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		args = []string{"up"}
+	}
+
+	if cfg.Database.Driver == "memory" {
+		return errors.New("the in-memory store has no schema to migrate")
+	}
+
+	conn, dialect := db.Connect(cfg.Database)
+	defer conn.Close()
+
+	migrator, err := db.NewMigrator(conn, dialect)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up()
+	case "down":
+		return migrator.Down()
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+		return migrator.To(version)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			if status.Applied {
+				fmt.Fprintf(os.Stdout, "%04d_%s\tapplied %s\n", status.Version, status.Name, status.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Fprintf(os.Stdout, "%04d_%s\tpending\n", status.Version, status.Name)
+			}
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
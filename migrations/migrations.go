@@ -0,0 +1,17 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// files holds the numbered up/down SQL files applied by db.Migrator, one
+// directory per storage dialect.
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// For returns the migrations written for the named dialect.
+func For(dialect string) (fs.FS, error) {
+	return fs.Sub(files, dialect)
+}
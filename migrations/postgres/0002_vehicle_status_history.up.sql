@@ -0,0 +1,32 @@
+CREATE TABLE vehicle_status_history (
+    id SERIAL PRIMARY KEY,
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX vehicle_status_history_vehicle_idx ON vehicle_status_history (vehicle_id, changed_at);
+
+-- Map free-text statuses onto the lifecycle: a vehicle with a sale is sold and
+-- anything else unknown becomes available. The original value is kept in the
+-- history.
+UPDATE vehicles SET status = LOWER(TRIM(status));
+
+INSERT INTO vehicle_status_history (vehicle_id, from_status, to_status, reason, actor, changed_at)
+SELECT id, status, 'sold', 'status normalised by migration', 'system', CURRENT_TIMESTAMP
+FROM vehicles
+WHERE status <> 'sold' AND id IN (SELECT vehicle_id FROM sales);
+
+UPDATE vehicles SET status = 'sold'
+WHERE status <> 'sold' AND id IN (SELECT vehicle_id FROM sales);
+
+INSERT INTO vehicle_status_history (vehicle_id, from_status, to_status, reason, actor, changed_at)
+SELECT id, status, 'available', 'status normalised by migration', 'system', CURRENT_TIMESTAMP
+FROM vehicles
+WHERE status NOT IN ('incoming', 'in_preparation', 'available', 'reserved', 'sold', 'returned', 'scrapped');
+
+UPDATE vehicles SET status = 'available'
+WHERE status NOT IN ('incoming', 'in_preparation', 'available', 'reserved', 'sold', 'returned', 'scrapped');
@@ -0,0 +1,20 @@
+CREATE TABLE reservations (
+    id SERIAL PRIMARY KEY,
+    client_id INTEGER NOT NULL REFERENCES clients(id),
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id),
+    deposit REAL NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    closed_at TIMESTAMP,
+    sale_id INTEGER REFERENCES sales(id)
+);
+
+-- A vehicle can be held by one active reservation at a time.
+CREATE UNIQUE INDEX reservations_active_vehicle_idx ON reservations (vehicle_id) WHERE status = 'active';
+CREATE INDEX reservations_expiry_idx ON reservations (status, expires_at);
+
+ALTER TABLE sales ADD COLUMN deposit_credited REAL NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN amount_due REAL NOT NULL DEFAULT 0;
+
+UPDATE sales SET amount_due = price;
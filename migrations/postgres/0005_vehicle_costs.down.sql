@@ -0,0 +1,5 @@
+DROP TABLE vehicle_expenses;
+
+ALTER TABLE vehicles DROP COLUMN acquisition_date;
+ALTER TABLE vehicles DROP COLUMN supplier;
+ALTER TABLE vehicles DROP COLUMN purchase_price;
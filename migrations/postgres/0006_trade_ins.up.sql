@@ -0,0 +1,12 @@
+ALTER TABLE vehicles ADD COLUMN previous_owner_id INTEGER REFERENCES clients(id);
+
+ALTER TABLE sales ADD COLUMN trade_in_credit REAL NOT NULL DEFAULT 0;
+
+CREATE TABLE sale_trade_ins (
+    id SERIAL PRIMARY KEY,
+    sale_id INTEGER NOT NULL REFERENCES sales(id),
+    vehicle_id INTEGER NOT NULL UNIQUE REFERENCES vehicles(id),
+    valuation REAL NOT NULL
+);
+
+CREATE INDEX sale_trade_ins_sale_idx ON sale_trade_ins (sale_id);
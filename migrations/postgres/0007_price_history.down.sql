@@ -0,0 +1,3 @@
+DELETE FROM vehicle_status_history WHERE reason = 'stock date unknown; recorded by migration';
+
+DROP TABLE vehicle_price_history;
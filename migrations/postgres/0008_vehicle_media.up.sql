@@ -0,0 +1,16 @@
+CREATE TABLE vehicle_media (
+    id SERIAL PRIMARY KEY,
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    cover BOOLEAN NOT NULL DEFAULT FALSE,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX vehicle_media_vehicle_idx ON vehicle_media (vehicle_id, position);
+CREATE UNIQUE INDEX vehicle_media_cover_idx ON vehicle_media (vehicle_id) WHERE cover;
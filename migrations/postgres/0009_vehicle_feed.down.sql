@@ -0,0 +1,5 @@
+DROP TABLE feed_exports;
+
+DROP INDEX vehicles_updated_at_idx;
+
+ALTER TABLE vehicles DROP COLUMN updated_at;
@@ -0,0 +1,13 @@
+-- updated_at lets the syndication feed export only what changed since the
+-- last export; existing vehicles count as changed now.
+ALTER TABLE vehicles ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX vehicles_updated_at_idx ON vehicles (updated_at);
+
+CREATE TABLE feed_exports (
+    id SERIAL PRIMARY KEY,
+    exported_at TIMESTAMP NOT NULL,
+    since TIMESTAMP,
+    listings INTEGER NOT NULL,
+    removed INTEGER NOT NULL
+);
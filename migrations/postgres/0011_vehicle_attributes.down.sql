@@ -0,0 +1,4 @@
+ALTER TABLE vehicles DROP COLUMN axles;
+ALTER TABLE vehicles DROP COLUMN payload_kg;
+ALTER TABLE vehicles DROP COLUMN licence_category;
+ALTER TABLE vehicles DROP COLUMN engine_cc;
@@ -0,0 +1,11 @@
+-- Type-specific attributes; which apply to each type is decided by
+-- models.VehicleTypes. Existing types are mapped onto the canonical names.
+ALTER TABLE vehicles ADD COLUMN engine_cc INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN licence_category TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN payload_kg INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN axles INTEGER NOT NULL DEFAULT 0;
+
+UPDATE vehicles SET type = 'Car' WHERE lower(type) IN ('car', 'carro', 'automóvel', 'automovel', 'ligeiro');
+UPDATE vehicles SET type = 'Motorcycle' WHERE lower(type) IN ('motorcycle', 'mota', 'moto', 'motociclo', 'motorbike');
+UPDATE vehicles SET type = 'Van' WHERE lower(type) IN ('van', 'carrinha', 'furgão', 'furgao', 'comercial');
+UPDATE vehicles SET type = 'Truck' WHERE lower(type) IN ('truck', 'camião', 'camiao', 'pesado', 'lorry');
@@ -0,0 +1,7 @@
+DROP TABLE vehicle_transfers;
+
+DROP INDEX vehicles_location_idx;
+
+ALTER TABLE vehicles DROP COLUMN location_id;
+
+DROP TABLE locations;
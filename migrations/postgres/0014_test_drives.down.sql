@@ -0,0 +1 @@
+DROP TABLE test_drives;
@@ -0,0 +1,18 @@
+-- Scans of a vehicle's papers; the files live in the media storage.
+-- flagged_at is set by the job reporting documents about to expire.
+CREATE TABLE vehicle_documents (
+    id SERIAL PRIMARY KEY,
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    issued_on TIMESTAMP,
+    expires_on TIMESTAMP,
+    flagged_at TIMESTAMP,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX vehicle_documents_vehicle_idx ON vehicle_documents (vehicle_id, type);
+CREATE INDEX vehicle_documents_expires_idx ON vehicle_documents (expires_on);
@@ -0,0 +1,7 @@
+DROP INDEX vehicles_fuel_type_idx;
+ALTER TABLE vehicles DROP COLUMN battery_kwh;
+ALTER TABLE vehicles DROP COLUMN emission_standard;
+ALTER TABLE vehicles DROP COLUMN co2;
+ALTER TABLE vehicles DROP COLUMN power_hp;
+ALTER TABLE vehicles DROP COLUMN power_kw;
+ALTER TABLE vehicles DROP COLUMN fuel_type;
@@ -0,0 +1,10 @@
+-- Structured engine data; zero or empty means not given. Existing vehicles
+-- are filled in from their motor description with "stand_api engine migrate".
+ALTER TABLE vehicles ADD COLUMN fuel_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN power_kw INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN power_hp INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN co2 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN emission_standard TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN battery_kwh REAL NOT NULL DEFAULT 0;
+
+CREATE INDEX vehicles_fuel_type_idx ON vehicles (fuel_type);
@@ -0,0 +1 @@
+-- The mapped types are kept; the original spellings were not recorded.
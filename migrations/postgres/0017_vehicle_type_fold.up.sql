@@ -0,0 +1,6 @@
+-- Mirrors the SQLite migration that maps the legacy types its 0011 missed,
+-- matching them ignoring case and accents as the dialect's Fold does.
+UPDATE vehicles SET type = 'Car' WHERE translate(lower(type), 'áàâãäåéèêëíìîïóòôõöúùûüçñýÿÁÀÂÃÄÅÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇÑÝ', 'aaaaaaeeeeiiiiooooouuuucnyyaaaaaaeeeeiiiiooooouuuucny') IN ('car', 'carro', 'automovel', 'ligeiro');
+UPDATE vehicles SET type = 'Motorcycle' WHERE translate(lower(type), 'áàâãäåéèêëíìîïóòôõöúùûüçñýÿÁÀÂÃÄÅÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇÑÝ', 'aaaaaaeeeeiiiiooooouuuucnyyaaaaaaeeeeiiiiooooouuuucny') IN ('motorcycle', 'mota', 'moto', 'motociclo', 'motorbike');
+UPDATE vehicles SET type = 'Van' WHERE translate(lower(type), 'áàâãäåéèêëíìîïóòôõöúùûüçñýÿÁÀÂÃÄÅÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇÑÝ', 'aaaaaaeeeeiiiiooooouuuucnyyaaaaaaeeeeiiiiooooouuuucny') IN ('van', 'carrinha', 'furgao', 'comercial');
+UPDATE vehicles SET type = 'Truck' WHERE translate(lower(type), 'áàâãäåéèêëíìîïóòôõöúùûüçñýÿÁÀÂÃÄÅÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇÑÝ', 'aaaaaaeeeeiiiiooooouuuucnyyaaaaaaeeeeiiiiooooouuuucny') IN ('truck', 'camiao', 'pesado', 'lorry');
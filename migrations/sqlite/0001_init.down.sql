@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sales;
+DROP TABLE IF EXISTS clients;
+DROP TABLE IF EXISTS vehicles;
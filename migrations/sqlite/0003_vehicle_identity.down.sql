@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS vehicles_license_plate_idx;
+DROP INDEX IF EXISTS vehicles_vin_idx;
+
+ALTER TABLE vehicles DROP COLUMN asking_price;
+ALTER TABLE vehicles DROP COLUMN seats;
+ALTER TABLE vehicles DROP COLUMN doors;
+ALTER TABLE vehicles DROP COLUMN transmission;
+ALTER TABLE vehicles DROP COLUMN colour;
+ALTER TABLE vehicles DROP COLUMN mileage;
+ALTER TABLE vehicles DROP COLUMN license_plate;
+ALTER TABLE vehicles DROP COLUMN vin;
@@ -0,0 +1,11 @@
+ALTER TABLE vehicles ADD COLUMN vin TEXT;
+ALTER TABLE vehicles ADD COLUMN license_plate TEXT;
+ALTER TABLE vehicles ADD COLUMN mileage INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN colour TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN transmission TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN doors INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN seats INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN asking_price REAL NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX vehicles_vin_idx ON vehicles (vin);
+CREATE UNIQUE INDEX vehicles_license_plate_idx ON vehicles (license_plate);
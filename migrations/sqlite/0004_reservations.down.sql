@@ -0,0 +1,4 @@
+ALTER TABLE sales DROP COLUMN amount_due;
+ALTER TABLE sales DROP COLUMN deposit_credited;
+
+DROP TABLE reservations;
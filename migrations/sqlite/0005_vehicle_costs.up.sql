@@ -0,0 +1,15 @@
+ALTER TABLE vehicles ADD COLUMN purchase_price REAL NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN supplier TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN acquisition_date DATE;
+
+CREATE TABLE vehicle_expenses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    amount REAL NOT NULL,
+    incurred_on DATE NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX vehicle_expenses_vehicle_idx ON vehicle_expenses (vehicle_id);
@@ -0,0 +1,5 @@
+DROP TABLE sale_trade_ins;
+
+ALTER TABLE sales DROP COLUMN trade_in_credit;
+
+ALTER TABLE vehicles DROP COLUMN previous_owner_id;
@@ -0,0 +1,18 @@
+CREATE TABLE vehicle_price_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    old_price REAL NOT NULL,
+    new_price REAL NOT NULL,
+    rule TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX vehicle_price_history_vehicle_idx ON vehicle_price_history (vehicle_id, changed_at);
+
+-- Vehicles created before the status history existed have no record of when
+-- they entered stock; start counting their days in stock from now.
+INSERT INTO vehicle_status_history (vehicle_id, from_status, to_status, reason, actor, changed_at)
+SELECT id, NULL, status, 'stock date unknown; recorded by migration', 'system', CURRENT_TIMESTAMP
+FROM vehicles
+WHERE id NOT IN (SELECT vehicle_id FROM vehicle_status_history);
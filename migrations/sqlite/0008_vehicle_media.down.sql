@@ -0,0 +1 @@
+DROP TABLE vehicle_media;
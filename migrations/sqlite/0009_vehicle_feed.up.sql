@@ -0,0 +1,16 @@
+-- updated_at lets the syndication feed export only what changed since the
+-- last export; existing vehicles count as changed now. SQLite cannot add a
+-- column with a non-constant default, so it is filled in afterwards.
+ALTER TABLE vehicles ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+UPDATE vehicles SET updated_at = CURRENT_TIMESTAMP;
+
+CREATE INDEX vehicles_updated_at_idx ON vehicles (updated_at);
+
+CREATE TABLE feed_exports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    exported_at TIMESTAMP NOT NULL,
+    since TIMESTAMP,
+    listings INTEGER NOT NULL,
+    removed INTEGER NOT NULL
+);
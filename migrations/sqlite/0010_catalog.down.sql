@@ -0,0 +1,9 @@
+DROP INDEX vehicles_brand_model_idx;
+
+ALTER TABLE vehicles DROP COLUMN model_id;
+ALTER TABLE vehicles DROP COLUMN brand_id;
+
+DROP TABLE vehicle_model_aliases;
+DROP TABLE vehicle_models;
+DROP TABLE brand_aliases;
+DROP TABLE brands;
@@ -0,0 +1,401 @@
+CREATE TABLE brands (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+
+-- Aliases are matched ignoring case, accents, spaces and punctuation; the
+-- API keeps them unique across brands.
+CREATE TABLE brand_aliases (
+    brand_id INTEGER NOT NULL REFERENCES brands(id) ON DELETE CASCADE,
+    alias TEXT NOT NULL,
+    PRIMARY KEY (brand_id, alias)
+);
+
+CREATE TABLE vehicle_models (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    brand_id INTEGER NOT NULL REFERENCES brands(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    UNIQUE (brand_id, name)
+);
+
+CREATE TABLE vehicle_model_aliases (
+    model_id INTEGER NOT NULL REFERENCES vehicle_models(id) ON DELETE CASCADE,
+    alias TEXT NOT NULL,
+    PRIMARY KEY (model_id, alias)
+);
+
+-- Existing vehicles keep NULL until `stand_api catalog normalize` maps them.
+ALTER TABLE vehicles ADD COLUMN brand_id INTEGER REFERENCES brands(id);
+ALTER TABLE vehicles ADD COLUMN model_id INTEGER REFERENCES vehicle_models(id);
+
+CREATE INDEX vehicles_brand_model_idx ON vehicles (brand_id, model_id);
+
+-- The bundled catalogue (catalog/catalog.json at the time of this migration).
+INSERT INTO brands (name) VALUES
+    ('Alfa Romeo'),
+    ('Audi'),
+    ('BMW'),
+    ('Citroen'),
+    ('DAF'),
+    ('Dacia'),
+    ('Ducati'),
+    ('Ferrari'),
+    ('Fiat'),
+    ('Ford'),
+    ('Honda'),
+    ('Hyundai'),
+    ('Iveco'),
+    ('Jaguar'),
+    ('KTM'),
+    ('Kawasaki'),
+    ('Kia'),
+    ('Lamborghini'),
+    ('Land Rover'),
+    ('Lexus'),
+    ('Lotus'),
+    ('MAN'),
+    ('MG'),
+    ('MINI'),
+    ('Mazda'),
+    ('Mercedes-Benz'),
+    ('Mitsubishi'),
+    ('Nissan'),
+    ('Opel'),
+    ('Peugeot'),
+    ('Piaggio'),
+    ('Porsche'),
+    ('Renault'),
+    ('Renault Trucks'),
+    ('SEAT'),
+    ('Saab'),
+    ('Skoda'),
+    ('Subaru'),
+    ('Suzuki'),
+    ('Tesla'),
+    ('Toyota'),
+    ('Volkswagen'),
+    ('Volvo'),
+    ('Volvo Trucks'),
+    ('Yamaha');
+
+INSERT INTO brand_aliases (brand_id, alias)
+SELECT b.id, a.column2 FROM brands b JOIN (VALUES
+    ('Alfa Romeo', 'Alfa'),
+    ('Mercedes-Benz', 'Mercedes'),
+    ('Mercedes-Benz', 'MB'),
+    ('Volkswagen', 'VW')
+) a ON a.column1 = b.name;
+
+INSERT INTO vehicle_models (brand_id, name)
+SELECT b.id, m.column2 FROM brands b JOIN (VALUES
+    ('Alfa Romeo', '159'),
+    ('Alfa Romeo', 'Giulia'),
+    ('Alfa Romeo', 'Giulietta'),
+    ('Alfa Romeo', 'MiTo'),
+    ('Alfa Romeo', 'Stelvio'),
+    ('Alfa Romeo', 'Tonale'),
+    ('Audi', 'A1'),
+    ('Audi', 'A3'),
+    ('Audi', 'A4'),
+    ('Audi', 'A5'),
+    ('Audi', 'A6'),
+    ('Audi', 'A7'),
+    ('Audi', 'A8'),
+    ('Audi', 'e-tron'),
+    ('Audi', 'Q2'),
+    ('Audi', 'Q3'),
+    ('Audi', 'Q5'),
+    ('Audi', 'Q7'),
+    ('Audi', 'Q8'),
+    ('Audi', 'TT'),
+    ('BMW', 'Série 1'),
+    ('BMW', 'Série 2'),
+    ('BMW', 'Série 3'),
+    ('BMW', 'Série 4'),
+    ('BMW', 'Série 5'),
+    ('BMW', 'i3'),
+    ('BMW', 'i4'),
+    ('BMW', 'iX'),
+    ('BMW', 'X1'),
+    ('BMW', 'X2'),
+    ('BMW', 'X3'),
+    ('BMW', 'X5'),
+    ('Citroen', 'Berlingo'),
+    ('Citroen', 'C1'),
+    ('Citroen', 'C3'),
+    ('Citroen', 'C3 Aircross'),
+    ('Citroen', 'C4'),
+    ('Citroen', 'C5 Aircross'),
+    ('DAF', 'CF'),
+    ('DAF', 'LF'),
+    ('DAF', 'XF'),
+    ('DAF', 'XG'),
+    ('Dacia', 'Duster'),
+    ('Dacia', 'Jogger'),
+    ('Dacia', 'Logan'),
+    ('Dacia', 'Sandero'),
+    ('Dacia', 'Spring'),
+    ('Ducati', 'Monster'),
+    ('Ducati', 'Multistrada'),
+    ('Ducati', 'Panigale'),
+    ('Ducati', 'Scrambler'),
+    ('Ferrari', '296'),
+    ('Ferrari', 'F8'),
+    ('Ferrari', 'Purosangue'),
+    ('Ferrari', 'Roma'),
+    ('Ferrari', 'SF90'),
+    ('Fiat', '500'),
+    ('Fiat', 'Doblò'),
+    ('Fiat', 'Ducato'),
+    ('Fiat', 'Panda'),
+    ('Fiat', 'Punto'),
+    ('Fiat', 'Tipo'),
+    ('Ford', 'Fiesta'),
+    ('Ford', 'Focus'),
+    ('Ford', 'Kuga'),
+    ('Ford', 'Mondeo'),
+    ('Ford', 'Mustang'),
+    ('Ford', 'Puma'),
+    ('Ford', 'Transit'),
+    ('Honda', 'Africa Twin'),
+    ('Honda', 'CB500F'),
+    ('Honda', 'CBR650R'),
+    ('Honda', 'Civic'),
+    ('Honda', 'CR-V'),
+    ('Honda', 'HR-V'),
+    ('Honda', 'Jazz'),
+    ('Honda', 'PCX'),
+    ('Hyundai', 'i10'),
+    ('Hyundai', 'i20'),
+    ('Hyundai', 'i30'),
+    ('Hyundai', 'Ioniq 5'),
+    ('Hyundai', 'Kona'),
+    ('Hyundai', 'Tucson'),
+    ('Iveco', 'Daily'),
+    ('Iveco', 'Eurocargo'),
+    ('Iveco', 'S-Way'),
+    ('Jaguar', 'E-Pace'),
+    ('Jaguar', 'F-Pace'),
+    ('Jaguar', 'I-Pace'),
+    ('Jaguar', 'XE'),
+    ('Jaguar', 'XF'),
+    ('KTM', 'Adventure'),
+    ('KTM', 'Duke'),
+    ('KTM', 'RC'),
+    ('Kawasaki', 'Ninja'),
+    ('Kawasaki', 'Versys'),
+    ('Kawasaki', 'Z650'),
+    ('Kawasaki', 'Z900'),
+    ('Kia', 'Ceed'),
+    ('Kia', 'EV6'),
+    ('Kia', 'Niro'),
+    ('Kia', 'Picanto'),
+    ('Kia', 'Rio'),
+    ('Kia', 'Sportage'),
+    ('Lamborghini', 'Huracán'),
+    ('Lamborghini', 'Revuelto'),
+    ('Lamborghini', 'Urus'),
+    ('Land Rover', 'Defender'),
+    ('Land Rover', 'Discovery'),
+    ('Land Rover', 'Discovery Sport'),
+    ('Land Rover', 'Range Rover'),
+    ('Land Rover', 'Range Rover Evoque'),
+    ('Land Rover', 'Range Rover Sport'),
+    ('Land Rover', 'Range Rover Velar'),
+    ('Lexus', 'CT'),
+    ('Lexus', 'IS'),
+    ('Lexus', 'NX'),
+    ('Lexus', 'RX'),
+    ('Lexus', 'UX'),
+    ('Lotus', 'Elise'),
+    ('Lotus', 'Emira'),
+    ('Lotus', 'Exige'),
+    ('MAN', 'TGE'),
+    ('MAN', 'TGL'),
+    ('MAN', 'TGM'),
+    ('MAN', 'TGX'),
+    ('MG', 'HS'),
+    ('MG', 'Marvel R'),
+    ('MG', 'MG4'),
+    ('MG', 'ZS'),
+    ('MINI', 'Clubman'),
+    ('MINI', 'Cooper'),
+    ('MINI', 'Countryman'),
+    ('Mazda', '2'),
+    ('Mazda', '3'),
+    ('Mazda', '6'),
+    ('Mazda', 'CX-30'),
+    ('Mazda', 'CX-5'),
+    ('Mazda', 'MX-5'),
+    ('Mercedes-Benz', 'Classe A'),
+    ('Mercedes-Benz', 'Classe B'),
+    ('Mercedes-Benz', 'Classe C'),
+    ('Mercedes-Benz', 'Classe E'),
+    ('Mercedes-Benz', 'GLA'),
+    ('Mercedes-Benz', 'GLC'),
+    ('Mercedes-Benz', 'Sprinter'),
+    ('Mercedes-Benz', 'Vito'),
+    ('Mitsubishi', 'ASX'),
+    ('Mitsubishi', 'Eclipse Cross'),
+    ('Mitsubishi', 'L200'),
+    ('Mitsubishi', 'Outlander'),
+    ('Mitsubishi', 'Space Star'),
+    ('Nissan', 'Juke'),
+    ('Nissan', 'Leaf'),
+    ('Nissan', 'Micra'),
+    ('Nissan', 'Navara'),
+    ('Nissan', 'Qashqai'),
+    ('Nissan', 'X-Trail'),
+    ('Opel', 'Astra'),
+    ('Opel', 'Corsa'),
+    ('Opel', 'Crossland'),
+    ('Opel', 'Grandland'),
+    ('Opel', 'Mokka'),
+    ('Opel', 'Vivaro'),
+    ('Peugeot', '108'),
+    ('Peugeot', '2008'),
+    ('Peugeot', '208'),
+    ('Peugeot', '3008'),
+    ('Peugeot', '308'),
+    ('Peugeot', '5008'),
+    ('Peugeot', '508'),
+    ('Peugeot', 'Partner'),
+    ('Peugeot', 'Rifter'),
+    ('Piaggio', 'Beverly'),
+    ('Piaggio', 'Liberty'),
+    ('Piaggio', 'MP3'),
+    ('Piaggio', 'Vespa'),
+    ('Porsche', '911'),
+    ('Porsche', 'Cayenne'),
+    ('Porsche', 'Macan'),
+    ('Porsche', 'Panamera'),
+    ('Porsche', 'Taycan'),
+    ('Renault', 'Austral'),
+    ('Renault', 'Captur'),
+    ('Renault', 'Clio'),
+    ('Renault', 'Kadjar'),
+    ('Renault', 'Kangoo'),
+    ('Renault', 'Master'),
+    ('Renault', 'Mégane'),
+    ('Renault', 'Trafic'),
+    ('Renault', 'Twingo'),
+    ('Renault', 'Zoe'),
+    ('Renault Trucks', 'C'),
+    ('Renault Trucks', 'D'),
+    ('Renault Trucks', 'Master'),
+    ('Renault Trucks', 'T'),
+    ('SEAT', 'Arona'),
+    ('SEAT', 'Ateca'),
+    ('SEAT', 'Ibiza'),
+    ('SEAT', 'Leon'),
+    ('SEAT', 'Tarraco'),
+    ('Saab', '9-3'),
+    ('Saab', '9-5'),
+    ('Skoda', 'Enyaq'),
+    ('Skoda', 'Fabia'),
+    ('Skoda', 'Kamiq'),
+    ('Skoda', 'Karoq'),
+    ('Skoda', 'Kodiaq'),
+    ('Skoda', 'Octavia'),
+    ('Skoda', 'Superb'),
+    ('Subaru', 'Forester'),
+    ('Subaru', 'Impreza'),
+    ('Subaru', 'Outback'),
+    ('Subaru', 'XV'),
+    ('Suzuki', 'GSX-R'),
+    ('Suzuki', 'Ignis'),
+    ('Suzuki', 'Jimny'),
+    ('Suzuki', 'S-Cross'),
+    ('Suzuki', 'Swift'),
+    ('Suzuki', 'V-Strom'),
+    ('Suzuki', 'Vitara'),
+    ('Tesla', 'Model 3'),
+    ('Tesla', 'Model S'),
+    ('Tesla', 'Model X'),
+    ('Tesla', 'Model Y'),
+    ('Toyota', 'Aygo'),
+    ('Toyota', 'C-HR'),
+    ('Toyota', 'Corolla'),
+    ('Toyota', 'Hilux'),
+    ('Toyota', 'Land Cruiser'),
+    ('Toyota', 'Prius'),
+    ('Toyota', 'Proace'),
+    ('Toyota', 'RAV4'),
+    ('Toyota', 'Yaris'),
+    ('Toyota', 'Yaris Cross'),
+    ('Volkswagen', 'Caddy'),
+    ('Volkswagen', 'Golf'),
+    ('Volkswagen', 'ID.3'),
+    ('Volkswagen', 'ID.4'),
+    ('Volkswagen', 'Passat'),
+    ('Volkswagen', 'Polo'),
+    ('Volkswagen', 'T-Cross'),
+    ('Volkswagen', 'T-Roc'),
+    ('Volkswagen', 'Tiguan'),
+    ('Volkswagen', 'Touran'),
+    ('Volkswagen', 'Transporter'),
+    ('Volkswagen', 'Up!'),
+    ('Volvo', 'S60'),
+    ('Volvo', 'S90'),
+    ('Volvo', 'V40'),
+    ('Volvo', 'V60'),
+    ('Volvo', 'XC40'),
+    ('Volvo', 'XC60'),
+    ('Volvo', 'XC90'),
+    ('Volvo Trucks', 'FH'),
+    ('Volvo Trucks', 'FH16'),
+    ('Volvo Trucks', 'FM'),
+    ('Volvo Trucks', 'FMX'),
+    ('Yamaha', 'MT-07'),
+    ('Yamaha', 'MT-09'),
+    ('Yamaha', 'NMAX'),
+    ('Yamaha', 'R7'),
+    ('Yamaha', 'Ténéré 700'),
+    ('Yamaha', 'TMAX'),
+    ('Yamaha', 'Tracer 9')
+) m ON m.column1 = b.name;
+
+INSERT INTO vehicle_model_aliases (model_id, alias)
+SELECT m.id, a.column3 FROM vehicle_models m JOIN brands b ON b.id = m.brand_id JOIN (VALUES
+    ('BMW', 'Série 1', '1 Series'),
+    ('BMW', 'Série 1', '116d'),
+    ('BMW', 'Série 1', '118d'),
+    ('BMW', 'Série 1', '118i'),
+    ('BMW', 'Série 1', '120d'),
+    ('BMW', 'Série 2', '2 Series'),
+    ('BMW', 'Série 2', '216d'),
+    ('BMW', 'Série 2', '218d'),
+    ('BMW', 'Série 2', '218i'),
+    ('BMW', 'Série 2', '220d'),
+    ('BMW', 'Série 3', '3 Series'),
+    ('BMW', 'Série 3', '318d'),
+    ('BMW', 'Série 3', '320d'),
+    ('BMW', 'Série 3', '320i'),
+    ('BMW', 'Série 3', '330e'),
+    ('BMW', 'Série 3', '330i'),
+    ('BMW', 'Série 4', '4 Series'),
+    ('BMW', 'Série 4', '420d'),
+    ('BMW', 'Série 4', '420i'),
+    ('BMW', 'Série 5', '5 Series'),
+    ('BMW', 'Série 5', '520d'),
+    ('BMW', 'Série 5', '530d'),
+    ('BMW', 'Série 5', '530e'),
+    ('Land Rover', 'Range Rover Evoque', 'Evoque'),
+    ('Land Rover', 'Range Rover Velar', 'Velar'),
+    ('MINI', 'Cooper', 'One'),
+    ('MINI', 'Cooper', 'Hatch'),
+    ('Mazda', '2', 'Mazda2'),
+    ('Mazda', '3', 'Mazda3'),
+    ('Mazda', '6', 'Mazda6'),
+    ('Mercedes-Benz', 'Classe A', 'A-Class'),
+    ('Mercedes-Benz', 'Classe A', 'A 180'),
+    ('Mercedes-Benz', 'Classe A', 'A 200'),
+    ('Mercedes-Benz', 'Classe B', 'B-Class'),
+    ('Mercedes-Benz', 'Classe B', 'B 180'),
+    ('Mercedes-Benz', 'Classe C', 'C-Class'),
+    ('Mercedes-Benz', 'Classe C', 'C 200'),
+    ('Mercedes-Benz', 'Classe C', 'C 220'),
+    ('Mercedes-Benz', 'Classe E', 'E-Class'),
+    ('Mercedes-Benz', 'Classe E', 'E 220'),
+    ('Mercedes-Benz', 'Classe E', 'E 300')
+) a ON a.column1 = b.name AND a.column2 = m.name;
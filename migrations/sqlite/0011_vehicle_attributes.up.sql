@@ -0,0 +1,11 @@
+-- Type-specific attributes; which apply to each type is decided by
+-- models.VehicleTypes. Existing types are mapped onto the canonical names.
+ALTER TABLE vehicles ADD COLUMN engine_cc INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN licence_category TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN payload_kg INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vehicles ADD COLUMN axles INTEGER NOT NULL DEFAULT 0;
+
+UPDATE vehicles SET type = 'Car' WHERE stand_fold(type) IN ('car', 'carro', 'automovel', 'ligeiro');
+UPDATE vehicles SET type = 'Motorcycle' WHERE stand_fold(type) IN ('motorcycle', 'mota', 'moto', 'motociclo', 'motorbike');
+UPDATE vehicles SET type = 'Van' WHERE stand_fold(type) IN ('van', 'carrinha', 'furgao', 'comercial');
+UPDATE vehicles SET type = 'Truck' WHERE stand_fold(type) IN ('truck', 'camiao', 'pesado', 'lorry');
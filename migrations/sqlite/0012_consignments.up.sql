@@ -0,0 +1,20 @@
+-- Vehicles sold on behalf of their owners. commission and amount_owed are
+-- filled in when the vehicle is sold.
+CREATE TABLE consignments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id),
+    owner_id INTEGER NOT NULL REFERENCES clients(id),
+    minimum_price REAL NOT NULL,
+    commission_type TEXT NOT NULL,
+    commission_value REAL NOT NULL,
+    status TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    closed_at TIMESTAMP,
+    sale_id INTEGER REFERENCES sales(id),
+    commission REAL,
+    amount_owed REAL
+);
+
+-- A vehicle can have one active consignment at a time.
+CREATE UNIQUE INDEX consignments_active_vehicle_idx ON consignments (vehicle_id) WHERE status = 'active';
+CREATE INDEX consignments_sale_idx ON consignments (sale_id);
@@ -0,0 +1,27 @@
+CREATE TABLE locations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    address TEXT NOT NULL DEFAULT ''
+);
+
+-- Existing vehicles have no location until they are transferred to one.
+ALTER TABLE vehicles ADD COLUMN location_id INTEGER REFERENCES locations(id);
+
+CREATE INDEX vehicles_location_idx ON vehicles (location_id);
+
+CREATE TABLE vehicle_transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    from_location_id INTEGER REFERENCES locations(id),
+    to_location_id INTEGER NOT NULL REFERENCES locations(id),
+    driver TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    requested_at TIMESTAMP NOT NULL,
+    dispatched_at TIMESTAMP,
+    received_at TIMESTAMP,
+    cancelled_at TIMESTAMP
+);
+
+-- A vehicle can have one open transfer at a time.
+CREATE UNIQUE INDEX vehicle_transfers_open_idx ON vehicle_transfers (vehicle_id) WHERE status IN ('requested', 'in_transit');
+CREATE INDEX vehicle_transfers_vehicle_idx ON vehicle_transfers (vehicle_id, requested_at);
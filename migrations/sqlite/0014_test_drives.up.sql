@@ -0,0 +1,18 @@
+-- Test drives keep the vehicle's location when booked so each location can
+-- publish its own calendar.
+CREATE TABLE test_drives (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id INTEGER NOT NULL REFERENCES clients(id),
+    vehicle_id INTEGER NOT NULL REFERENCES vehicles(id),
+    salesperson TEXT NOT NULL,
+    location_id INTEGER REFERENCES locations(id),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    cancelled_at TIMESTAMP
+);
+
+CREATE INDEX test_drives_vehicle_idx ON test_drives (vehicle_id, starts_at);
+CREATE INDEX test_drives_starts_idx ON test_drives (starts_at);
@@ -0,0 +1,8 @@
+-- Migration 0011 first matched legacy types with lower() alone, which left
+-- upper-case and accented spellings such as 'CAMIÃO' unmapped. Match them
+-- again, ignoring case and accents with stand_fold(), the Go fold the
+-- SQLite dialect registers, as lower() only knows ASCII.
+UPDATE vehicles SET type = 'Car' WHERE stand_fold(type) IN ('car', 'carro', 'automovel', 'ligeiro');
+UPDATE vehicles SET type = 'Motorcycle' WHERE stand_fold(type) IN ('motorcycle', 'mota', 'moto', 'motociclo', 'motorbike');
+UPDATE vehicles SET type = 'Van' WHERE stand_fold(type) IN ('van', 'carrinha', 'furgao', 'comercial');
+UPDATE vehicles SET type = 'Truck' WHERE stand_fold(type) IN ('truck', 'camiao', 'pesado', 'lorry');
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Brand is a make of the reference catalogue. Aliases are other spellings
+// that resolve to it, such as "VW" for Volkswagen.
+type Brand struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name" binding:"required"`
+	Aliases []string `json:"aliases"`
+}
+
+// BrandModel is a model of a catalogue brand, with aliases such as "320d"
+// for the BMW 3 Series.
+type BrandModel struct {
+	ID      int64    `json:"id"`
+	BrandID int64    `json:"brand_id"`
+	Name    string   `json:"name" binding:"required"`
+	Aliases []string `json:"aliases"`
+}
+
+// CatalogConflictError is returned when adding a catalogue entry whose name
+// or alias already resolves to Existing.
+type CatalogConflictError struct {
+	Name     string
+	Existing string
+}
+
+func (e *CatalogConflictError) Error() string {
+	return fmt.Sprintf("%q is already used by %s", e.Name, e.Existing)
+}
+
+// CatalogKey is the form in which names and aliases are compared: folded
+// and without spaces or punctuation, so that "Mercedes Benz" matches
+// "Mercedes-Benz" and "MT07" matches "MT-07".
+func CatalogKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, Fold(name))
+}
+
+// Catalog resolves free-text brands and models onto the reference tables.
+type Catalog struct {
+	brands      map[int64]*Brand
+	brandKeys   map[string]*Brand
+	models      map[int64]*BrandModel
+	modelKeys   map[int64]map[string]*BrandModel
+	brandModels map[int64][]BrandModel
+}
+
+// NewCatalog indexes the brands and models by ID, name and alias.
+func NewCatalog(brands []Brand, models []BrandModel) *Catalog {
+	c := &Catalog{
+		brands:      map[int64]*Brand{},
+		brandKeys:   map[string]*Brand{},
+		models:      map[int64]*BrandModel{},
+		modelKeys:   map[int64]map[string]*BrandModel{},
+		brandModels: map[int64][]BrandModel{},
+	}
+	for i := range brands {
+		brand := &brands[i]
+		c.brands[brand.ID] = brand
+		for _, name := range append([]string{brand.Name}, brand.Aliases...) {
+			c.brandKeys[CatalogKey(name)] = brand
+		}
+	}
+	for i := range models {
+		model := &models[i]
+		c.models[model.ID] = model
+		c.brandModels[model.BrandID] = append(c.brandModels[model.BrandID], *model)
+		if c.modelKeys[model.BrandID] == nil {
+			c.modelKeys[model.BrandID] = map[string]*BrandModel{}
+		}
+		for _, name := range append([]string{model.Name}, model.Aliases...) {
+			c.modelKeys[model.BrandID][CatalogKey(name)] = model
+		}
+	}
+	return c
+}
+
+// Brands lists the brands ordered by name.
+func (c *Catalog) Brands() []Brand {
+	brands := []Brand{}
+	for _, brand := range c.brands {
+		brands = append(brands, *brand)
+	}
+	sort.Slice(brands, func(i, j int) bool { return Fold(brands[i].Name) < Fold(brands[j].Name) })
+	return brands
+}
+
+// Models lists the models of a brand ordered by name.
+func (c *Catalog) Models(brandID int64) []BrandModel {
+	models := append([]BrandModel{}, c.brandModels[brandID]...)
+	sort.Slice(models, func(i, j int) bool { return Fold(models[i].Name) < Fold(models[j].Name) })
+	return models
+}
+
+// BrandByID returns the brand with the given ID.
+func (c *Catalog) BrandByID(id int64) (*Brand, bool) {
+	brand, ok := c.brands[id]
+	return brand, ok
+}
+
+// LookupBrand finds the brand whose name or alias matches name.
+func (c *Catalog) LookupBrand(name string) (*Brand, bool) {
+	brand, ok := c.brandKeys[CatalogKey(name)]
+	return brand, ok
+}
+
+// LookupModel finds the model of a brand whose name or alias matches name.
+func (c *Catalog) LookupModel(brandID int64, name string) (*BrandModel, bool) {
+	model, ok := c.modelKeys[brandID][CatalogKey(name)]
+	return model, ok
+}
+
+// Resolve points the vehicle at its catalogue brand and model, found by
+// BrandID and ModelID or by name, and replaces the free-text names with the
+// catalogue names. An ID and a name given together must agree.
+func (c *Catalog) Resolve(v *Vehicle) error {
+	var brand *Brand
+	if v.BrandID != nil {
+		if brand = c.brands[*v.BrandID]; brand == nil {
+			return ValidationErrors{{Field: "BrandID", Message: "is not in the catalogue"}}
+		}
+	}
+	if strings.TrimSpace(v.Brand) != "" {
+		named, ok := c.LookupBrand(v.Brand)
+		switch {
+		case !ok:
+			return ValidationErrors{{Field: "Brand", Message: "is not in the catalogue"}}
+		case brand != nil && named != brand:
+			return ValidationErrors{{Field: "Brand", Message: "does not match BrandID"}}
+		}
+		brand = named
+	}
+	if brand == nil {
+		return ValidationErrors{{Field: "Brand", Message: "is required"}}
+	}
+
+	var model *BrandModel
+	if v.ModelID != nil {
+		if model = c.models[*v.ModelID]; model == nil || model.BrandID != brand.ID {
+			return ValidationErrors{{Field: "ModelID", Message: "is not a catalogue model of " + brand.Name}}
+		}
+	}
+	if strings.TrimSpace(v.Model) != "" {
+		named, ok := c.LookupModel(brand.ID, v.Model)
+		switch {
+		case !ok:
+			return ValidationErrors{{Field: "Model", Message: "is not a catalogue model of " + brand.Name}}
+		case model != nil && named != model:
+			return ValidationErrors{{Field: "Model", Message: "does not match ModelID"}}
+		}
+		model = named
+	}
+	if model == nil {
+		return ValidationErrors{{Field: "Model", Message: "is required"}}
+	}
+
+	brandID, modelID := brand.ID, model.ID
+	v.BrandID, v.ModelID = &brandID, &modelID
+	v.Brand, v.Model = brand.Name, model.Name
+	return nil
+}
+
+// CheckBrand reports a conflict if the name or an alias of brand already
+// resolves to another brand.
+func (c *Catalog) CheckBrand(brand *Brand) error {
+	for _, name := range append([]string{brand.Name}, brand.Aliases...) {
+		if existing, ok := c.LookupBrand(name); ok && existing.ID != brand.ID {
+			return &CatalogConflictError{Name: name, Existing: existing.Name}
+		}
+	}
+	return nil
+}
+
+// CheckModel is CheckBrand for the models of model.BrandID.
+func (c *Catalog) CheckModel(model *BrandModel) error {
+	for _, name := range append([]string{model.Name}, model.Aliases...) {
+		if existing, ok := c.LookupModel(model.BrandID, name); ok && existing.ID != model.ID {
+			return &CatalogConflictError{Name: name, Existing: existing.Name}
+		}
+	}
+	return nil
+}
+
+// validateCatalogNames checks the name of a new brand or model, trimming it
+// and its aliases and dropping empty and repeated aliases.
+func validateCatalogNames(name *string, aliases *[]string) error {
+	var errs ValidationErrors
+	*name = strings.TrimSpace(*name)
+	if CatalogKey(*name) == "" {
+		errs = append(errs, ValidationError{"name", "must contain letters or digits"})
+	}
+
+	seen := map[string]bool{CatalogKey(*name): true}
+	kept := []string{}
+	for _, alias := range *aliases {
+		alias = strings.TrimSpace(alias)
+		key := CatalogKey(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, alias)
+	}
+	*aliases = kept
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate normalises the name and aliases of a new brand.
+func (b *Brand) Validate() error {
+	return validateCatalogNames(&b.Name, &b.Aliases)
+}
+
+// Validate normalises the name and aliases of a new model.
+func (m *BrandModel) Validate() error {
+	return validateCatalogNames(&m.Name, &m.Aliases)
+}
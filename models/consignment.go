@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type CommissionType string
+
+const (
+	// CommissionFixed is an amount in euros.
+	CommissionFixed CommissionType = "fixed"
+	// CommissionPercentage is a percentage of the sale price.
+	CommissionPercentage CommissionType = "percentage"
+)
+
+type ConsignmentStatus string
+
+const (
+	ConsignmentActive    ConsignmentStatus = "active"
+	ConsignmentSold      ConsignmentStatus = "sold"
+	ConsignmentWithdrawn ConsignmentStatus = "withdrawn"
+)
+
+// Consignment is an agreement to sell a vehicle on behalf of its owner, who
+// is paid the sale price minus the stand's commission. While it is active
+// the vehicle cannot be sold below MinimumPrice.
+type Consignment struct {
+	ID              int64             `json:"id"`
+	VehicleID       int64             `json:"vehicle_id" binding:"required"`
+	OwnerID         int64             `json:"owner_id" binding:"required"`
+	MinimumPrice    float64           `json:"minimum_price" binding:"required"`
+	CommissionType  CommissionType    `json:"commission_type" binding:"required"`
+	CommissionValue float64           `json:"commission_value"`
+	Status          ConsignmentStatus `json:"status"`
+	CreatedAt       time.Time         `json:"created_at"`
+	ClosedAt        *time.Time        `json:"closed_at,omitempty"`
+	// SaleID is set once the vehicle has been sold.
+	SaleID *int64 `json:"sale_id,omitempty"`
+}
+
+// Validate checks a new consignment.
+func (c *Consignment) Validate() error {
+	var errs ValidationErrors
+	if c.MinimumPrice <= 0 {
+		errs = append(errs, ValidationError{"minimum_price", "must be positive"})
+	}
+	switch c.CommissionType {
+	case CommissionFixed:
+		if c.CommissionValue < 0 || c.CommissionValue > c.MinimumPrice {
+			errs = append(errs, ValidationError{"commission_value", "must be between 0 and the minimum price"})
+		}
+	case CommissionPercentage:
+		if c.CommissionValue < 0 || c.CommissionValue > 100 {
+			errs = append(errs, ValidationError{"commission_value", "must be between 0 and 100"})
+		}
+	default:
+		errs = append(errs, ValidationError{"commission_type", "must be fixed or percentage"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Commission is what the stand keeps from a sale at price, rounded to cents.
+func (c *Consignment) Commission(price float64) float64 {
+	if c.CommissionType == CommissionPercentage {
+		return math.Round(price*c.CommissionValue) / 100
+	}
+	return c.CommissionValue
+}
+
+// AmountOwed is what the owner is paid for a sale at price.
+func (c *Consignment) AmountOwed(price float64) float64 {
+	return math.Round((price-c.Commission(price))*100) / 100
+}
+
+// CheckPrice returns BelowMinimumPriceError if price is below the agreed minimum.
+func (c *Consignment) CheckPrice(price float64) error {
+	if price < c.MinimumPrice {
+		return &BelowMinimumPriceError{VehicleID: c.VehicleID, ConsignmentID: c.ID, MinimumPrice: c.MinimumPrice}
+	}
+	return nil
+}
+
+// ConsignmentSettlement is the statement of what the stand owes the owner
+// of a sold consignment. The amounts are fixed when the sale is recorded.
+type ConsignmentSettlement struct {
+	Consignment Consignment `json:"consignment"`
+	Owner       Client      `json:"owner"`
+	Vehicle     Vehicle     `json:"vehicle"`
+	SaleDate    time.Time   `json:"sale_date"`
+	SalePrice   float64     `json:"sale_price"`
+	Commission  float64     `json:"commission"`
+	// AmountOwed is the sale price minus the commission.
+	AmountOwed float64 `json:"amount_owed"`
+}
+
+// BelowMinimumPriceError is returned when a consigned vehicle is sold below
+// the price agreed with its owner.
+type BelowMinimumPriceError struct {
+	VehicleID     int64
+	ConsignmentID int64
+	MinimumPrice  float64
+}
+
+func (e *BelowMinimumPriceError) Error() string {
+	return fmt.Sprintf("vehicle %d cannot be sold below %.2f", e.VehicleID, e.MinimumPrice)
+}
+
+// VehicleConsignedError is returned when consigning a vehicle that already
+// has an active consignment.
+type VehicleConsignedError struct {
+	VehicleID     int64
+	ConsignmentID int64
+}
+
+func (e *VehicleConsignedError) Error() string {
+	return fmt.Sprintf("vehicle %d is already consigned under consignment %d", e.VehicleID, e.ConsignmentID)
+}
+
+// ConsignedPurchasePriceError is returned when a consigned vehicle has a
+// purchase price: the stand never buys it, so its cost is what its owner is
+// owed instead.
+type ConsignedPurchasePriceError struct {
+	VehicleID int64
+}
+
+func (e *ConsignedPurchasePriceError) Error() string {
+	return fmt.Sprintf("vehicle %d is consigned and cannot have a purchase price", e.VehicleID)
+}
+
+// ConsignmentNotActiveError is returned when withdrawing a consignment that
+// is no longer active, or settling one that has not been sold.
+type ConsignmentNotActiveError struct {
+	ConsignmentID int64
+	Status        ConsignmentStatus
+}
+
+func (e *ConsignmentNotActiveError) Error() string {
+	return fmt.Sprintf("consignment %d is %s", e.ConsignmentID, e.Status)
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+type FuelType string
+
+const (
+	FuelPetrol       FuelType = "petrol"
+	FuelDiesel       FuelType = "diesel"
+	FuelHybrid       FuelType = "hybrid"
+	FuelPlugInHybrid FuelType = "plug_in_hybrid"
+	FuelElectric     FuelType = "electric"
+	FuelLPG          FuelType = "lpg"
+	FuelCNG          FuelType = "cng"
+)
+
+// FuelTypes lists the fuel types with the aliases Normalize accepts for each.
+var FuelTypes = []struct {
+	Type    FuelType
+	Aliases []string
+}{
+	{FuelPetrol, []string{"gasolina", "gasoline"}},
+	{FuelDiesel, []string{"gasóleo", "gasoleo"}},
+	{FuelHybrid, []string{"híbrido", "hibrido", "hev"}},
+	{FuelPlugInHybrid, []string{"plug-in", "plug-in hybrid", "híbrido plug-in", "phev"}},
+	{FuelElectric, []string{"elétrico", "eléctrico", "ev", "bev"}},
+	{FuelLPG, []string{"gpl"}},
+	{FuelCNG, []string{"gnc", "gnv"}},
+}
+
+// LookupFuelType finds the fuel type whose name or alias matches name,
+// ignoring case and accents.
+func LookupFuelType(name string) (FuelType, bool) {
+	folded := Fold(strings.TrimSpace(name))
+	for _, fuel := range FuelTypes {
+		if Fold(string(fuel.Type)) == folded {
+			return fuel.Type, true
+		}
+		for _, alias := range fuel.Aliases {
+			if Fold(alias) == folded {
+				return fuel.Type, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (f FuelType) Valid() bool {
+	for _, fuel := range FuelTypes {
+		if fuel.Type == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Electrified reports whether the fuel type has a traction battery.
+func (f FuelType) Electrified() bool {
+	return f == FuelHybrid || f == FuelPlugInHybrid || f == FuelElectric
+}
+
+// hpPerKW is the metric horsepower (cv, PS) in one kilowatt.
+const hpPerKW = 1.35962
+
+// KWToHP converts a power in kW to metric horsepower, rounded.
+func KWToHP(kw int) int {
+	return int(math.Round(float64(kw) * hpPerKW))
+}
+
+// HPToKW converts a power in metric horsepower to kW, rounded.
+func HPToKW(hp int) int {
+	return int(math.Round(float64(hp) / hpPerKW))
+}
+
+var emissionStandardPattern = regexp.MustCompile(`^euro ?-?([1-6])([a-d]?)(-?temp)?$`)
+
+// NormalizeEmissionStandard puts a European emission standard in the form
+// "Euro 6d-TEMP", accepting spellings such as "EURO6D-temp" or "euro 5".
+func NormalizeEmissionStandard(value string) (string, error) {
+	match := emissionStandardPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if match == nil {
+		return "", errors.New("must be a Euro standard such as Euro 6d")
+	}
+	standard := "Euro " + match[1] + match[2]
+	if match[3] != "" {
+		standard += "-TEMP"
+	}
+	return standard, nil
+}
+
+// normalizeEngine puts the fuel type and emission standard in canonical
+// form and fills in whichever of PowerKW and PowerHP was left out.
+func (v *Vehicle) normalizeEngine() {
+	if fuel, ok := LookupFuelType(string(v.FuelType)); ok {
+		v.FuelType = fuel
+	}
+	if standard, err := NormalizeEmissionStandard(v.EmissionStandard); err == nil {
+		v.EmissionStandard = standard
+	}
+	switch {
+	case v.PowerKW == 0 && v.PowerHP > 0:
+		v.PowerKW = HPToKW(v.PowerHP)
+	case v.PowerHP == 0 && v.PowerKW > 0:
+		v.PowerHP = KWToHP(v.PowerKW)
+	}
+}
+
+// validateEngine checks the engine data. The displacement (EngineCC) is
+// checked with the type-specific attributes.
+func (v *Vehicle) validateEngine() ValidationErrors {
+	var errs ValidationErrors
+	if v.FuelType != "" && !v.FuelType.Valid() {
+		errs = append(errs, ValidationError{"FuelType", "must be petrol, diesel, hybrid, plug_in_hybrid, electric, lpg or cng"})
+	}
+	if v.FuelType == FuelElectric && v.EngineCC != 0 {
+		errs = append(errs, ValidationError{"EngineCC", "does not apply to electric vehicles"})
+	}
+	if v.PowerKW < 0 || v.PowerKW > 2000 {
+		errs = append(errs, ValidationError{"PowerKW", "must be between 0 and 2000"})
+	}
+	if v.PowerHP < 0 {
+		errs = append(errs, ValidationError{"PowerHP", "must not be negative"})
+	}
+	// Both are usually given as advertised, so allow for their rounding.
+	if v.PowerKW > 0 && v.PowerHP > 0 && math.Abs(float64(v.PowerHP-KWToHP(v.PowerKW))) > 1+float64(v.PowerHP)/50 {
+		errs = append(errs, ValidationError{"PowerHP", fmt.Sprintf("does not match PowerKW (%d kW is %d hp)", v.PowerKW, KWToHP(v.PowerKW))})
+	}
+	if v.CO2 < 0 || v.CO2 > 1000 {
+		errs = append(errs, ValidationError{"CO2", "must be between 0 and 1000 g/km"})
+	}
+	if v.EmissionStandard != "" {
+		if _, err := NormalizeEmissionStandard(v.EmissionStandard); err != nil {
+			errs = append(errs, ValidationError{"EmissionStandard", err.Error()})
+		}
+	}
+	if v.BatteryKWh < 0 || v.BatteryKWh > 250 {
+		errs = append(errs, ValidationError{"BatteryKWh", "must be between 0 and 250"})
+	} else if v.BatteryKWh > 0 && !v.FuelType.Electrified() {
+		errs = append(errs, ValidationError{"BatteryKWh", "only applies to electric and hybrid vehicles"})
+	}
+	return errs
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// FeedExport records a run of the scheduled feed export. The incremental
+// feed of the next run covers the vehicles changed since ExportedAt.
+type FeedExport struct {
+	ID         int64     `json:"id"`
+	ExportedAt time.Time `json:"exported_at"`
+	// Since is the previous export, or nil for the first one.
+	Since    *time.Time `json:"since,omitempty"`
+	Listings int        `json:"listings"`
+	Removed  int        `json:"removed"`
+}
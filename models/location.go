@@ -0,0 +1,159 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Location is a lot or branch where vehicles are kept.
+type Location struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name" binding:"required"`
+	Address string `json:"address"`
+}
+
+// Validate trims and checks a location.
+func (l *Location) Validate() error {
+	l.Name = strings.TrimSpace(l.Name)
+	l.Address = strings.TrimSpace(l.Address)
+	if l.Name == "" {
+		return ValidationErrors{{"name", "is required"}}
+	}
+	return nil
+}
+
+// LocationStock counts the vehicles in stock (see InStockStatuses) at a
+// location. LocationID is nil for the vehicles without a location.
+type LocationStock struct {
+	LocationID *int64                `json:"location_id"`
+	Name       string                `json:"name"`
+	Total      int                   `json:"total"`
+	ByStatus   map[VehicleStatus]int `json:"by_status"`
+	// Incoming and Outgoing count the vehicles in transit to and from the
+	// location; outgoing vehicles are still counted in Total until received.
+	Incoming int `json:"incoming"`
+	Outgoing int `json:"outgoing"`
+}
+
+// StockCount is the number of vehicles in stock with a location and status.
+type StockCount struct {
+	LocationID *int64
+	Status     VehicleStatus
+	Count      int
+}
+
+// CountLocationStock builds the stock of each location, in the order given,
+// from the counts of vehicles in stock and the transfers in transit. The
+// vehicles without a location are counted last, if there are any.
+func CountLocationStock(locations []Location, counts []StockCount, inTransit []VehicleTransfer) []LocationStock {
+	stock := make([]LocationStock, len(locations))
+	byID := map[int64]*LocationStock{}
+	for i, location := range locations {
+		id := location.ID
+		stock[i] = LocationStock{LocationID: &id, Name: location.Name, ByStatus: map[VehicleStatus]int{}}
+		byID[id] = &stock[i]
+	}
+	unassigned := LocationStock{Name: "Unassigned", ByStatus: map[VehicleStatus]int{}}
+
+	for _, count := range counts {
+		entry := &unassigned
+		if count.LocationID != nil {
+			if entry = byID[*count.LocationID]; entry == nil {
+				continue
+			}
+		}
+		entry.Total += count.Count
+		entry.ByStatus[count.Status] += count.Count
+	}
+	for _, transfer := range inTransit {
+		if entry := byID[transfer.ToLocationID]; entry != nil {
+			entry.Incoming++
+		}
+		if transfer.FromLocationID == nil {
+			unassigned.Outgoing++
+		} else if entry := byID[*transfer.FromLocationID]; entry != nil {
+			entry.Outgoing++
+		}
+	}
+
+	if unassigned.Total > 0 {
+		stock = append(stock, unassigned)
+	}
+	return stock
+}
+
+type TransferStatus string
+
+const (
+	TransferRequested TransferStatus = "requested"
+	TransferInTransit TransferStatus = "in_transit"
+	TransferReceived  TransferStatus = "received"
+	TransferCancelled TransferStatus = "cancelled"
+)
+
+// Open reports whether the transfer has not been received or cancelled.
+func (s TransferStatus) Open() bool {
+	return s == TransferRequested || s == TransferInTransit
+}
+
+// VehicleTransfer moves a vehicle between locations: it is requested,
+// dispatched with a driver and received, at which point the vehicle's
+// location changes. A requested transfer can be cancelled.
+type VehicleTransfer struct {
+	ID        int64 `json:"id"`
+	VehicleID int64 `json:"vehicle_id" binding:"required"`
+	// FromLocationID is the vehicle's location when requested; nil for
+	// vehicles that had none.
+	FromLocationID *int64         `json:"from_location_id"`
+	ToLocationID   int64          `json:"to_location_id" binding:"required"`
+	Driver         string         `json:"driver"`
+	Status         TransferStatus `json:"status"`
+	RequestedAt    time.Time      `json:"requested_at"`
+	DispatchedAt   *time.Time     `json:"dispatched_at,omitempty"`
+	ReceivedAt     *time.Time     `json:"received_at,omitempty"`
+	CancelledAt    *time.Time     `json:"cancelled_at,omitempty"`
+}
+
+// Check validates a transfer request against the status and location of
+// the vehicle.
+func (t *VehicleTransfer) Check(status VehicleStatus, locationID *int64) error {
+	if !contains(InStockStatuses, status) {
+		return ValidationErrors{{"vehicle_id", "is not in stock"}}
+	}
+	if locationID != nil && *locationID == t.ToLocationID {
+		return ValidationErrors{{"to_location_id", "is the vehicle's current location"}}
+	}
+	return nil
+}
+
+// DuplicateLocationError is returned when a location name is already taken.
+type DuplicateLocationError struct {
+	Name string
+}
+
+func (e *DuplicateLocationError) Error() string {
+	return fmt.Sprintf("location %q already exists", e.Name)
+}
+
+// VehicleInTransferError is returned when requesting a transfer of, or
+// booking a test drive in, a vehicle that has an open transfer.
+type VehicleInTransferError struct {
+	VehicleID  int64
+	TransferID int64
+}
+
+func (e *VehicleInTransferError) Error() string {
+	return fmt.Sprintf("vehicle %d already has open transfer %d", e.VehicleID, e.TransferID)
+}
+
+// TransferStatusError is returned when a transfer cannot move to the
+// requested step from its current status.
+type TransferStatusError struct {
+	TransferID int64
+	Status     TransferStatus
+}
+
+func (e *TransferStatusError) Error() string {
+	return fmt.Sprintf("transfer %d is %s", e.TransferID, e.Status)
+}
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrInUse is returned when deleting a record that other records still reference.
+	ErrInUse = errors.New("record is referenced by other records")
+)
+
+type VehicleRepository interface {
+	// Create stores the vehicle and records its initial status in the history.
+	// Like every vehicle write, it resolves the brand and model against the
+	// catalogue (see Catalog.Resolve) and returns ValidationErrors if they
+	// are not in it.
+	Create(ctx context.Context, vehicle *Vehicle) error
+	// CreateBatch stores the vehicles in one transaction, all or none,
+	// filling in their IDs. The failing vehicle is reported in a BatchError.
+	CreateBatch(ctx context.Context, vehicles []Vehicle) error
+	GetAll(ctx context.Context) ([]Vehicle, error)
+	GetByID(ctx context.Context, id int64) (*Vehicle, error)
+	// Find returns the page of vehicles selected by query, with Items never nil.
+	Find(ctx context.Context, query VehicleQuery) (*VehiclePage, error)
+	// FindByIdentity returns the vehicles that have one of the VINs or
+	// licence plates, ordered by ID.
+	FindByIdentity(ctx context.Context, vins, plates []string) ([]Vehicle, error)
+	// Facets counts the vehicles matching filter by type, brand, model, year
+	// bucket, status and price band.
+	Facets(ctx context.Context, filter VehicleFilter) (*VehicleFacets, error)
+	// Update changes every field except Status, which only moves through
+	// Transition, and LocationID, which only moves through transfers and is
+	// filled in from the store. A changed asking price is recorded in the
+	// price history.
+	Update(ctx context.Context, vehicle *Vehicle) error
+	Delete(ctx context.Context, id int64) error
+	// Transition moves the vehicle to change.To if the state machine allows it
+	// and appends change to the history, filling in its ID, From and ChangedAt.
+	// It returns VehicleReservedError for a vehicle held by a reservation.
+	Transition(ctx context.Context, change *VehicleStatusChange) error
+	StatusHistory(ctx context.Context, vehicleID int64) ([]VehicleStatusChange, error)
+	Profitability(ctx context.Context, vehicleID int64) (*Profitability, error)
+	// Stock returns the vehicles in one of InStockStatuses, ordered by ID.
+	Stock(ctx context.Context) ([]StockVehicle, error)
+	// PriceHistory returns the asking price changes of a vehicle, oldest first.
+	PriceHistory(ctx context.Context, vehicleID int64) ([]PriceChange, error)
+	// Reprice sets the asking price of an available vehicle to change.NewPrice
+	// and records change, filling in its ID and ChangedAt. It returns
+	// ErrVehicleChanged if the vehicle is no longer available at change.OldPrice.
+	Reprice(ctx context.Context, change *PriceChange) error
+}
+
+type ExpenseRepository interface {
+	// Create adds an expense to the ledger of expense.VehicleID.
+	Create(ctx context.Context, expense *VehicleExpense) error
+	ListByVehicle(ctx context.Context, vehicleID int64) ([]VehicleExpense, error)
+	// Delete removes an expense of the given vehicle.
+	Delete(ctx context.Context, vehicleID, id int64) error
+}
+
+type MediaRepository interface {
+	// Create appends media to its vehicle's gallery, making it the cover if
+	// the vehicle has none.
+	Create(ctx context.Context, media *VehicleMedia) error
+	// ListByVehicle returns a vehicle's media in gallery order.
+	ListByVehicle(ctx context.Context, vehicleID int64) ([]VehicleMedia, error)
+	GetByID(ctx context.Context, vehicleID, id int64) (*VehicleMedia, error)
+	// Delete removes media from the gallery, closing the gap in the order
+	// and passing the cover on to the first remaining item.
+	Delete(ctx context.Context, vehicleID, id int64) error
+	// Reorder sets the gallery order to ids, which must list every media ID
+	// of the vehicle once.
+	Reorder(ctx context.Context, vehicleID int64, ids []int64) error
+	SetCover(ctx context.Context, vehicleID, id int64) error
+}
+
+type DocumentRepository interface {
+	// Create stores a document of document.VehicleID, returning ErrNotFound
+	// if the vehicle does not exist.
+	Create(ctx context.Context, document *VehicleDocument) error
+	// ListByVehicle returns a vehicle's documents by type, latest expiry first.
+	ListByVehicle(ctx context.Context, vehicleID int64) ([]VehicleDocument, error)
+	GetByID(ctx context.Context, vehicleID, id int64) (*VehicleDocument, error)
+	Delete(ctx context.Context, vehicleID, id int64) error
+	// Expiring returns the documents of vehicles in stock that expire by the
+	// given time and have not been superseded (see VehicleDocument.Supersedes),
+	// soonest first.
+	Expiring(ctx context.Context, by time.Time) ([]ExpiringDocument, error)
+	// FlagExpiring sets FlaggedAt to now on the Expiring documents that were
+	// not flagged yet and returns them.
+	FlagExpiring(ctx context.Context, by, now time.Time) ([]ExpiringDocument, error)
+}
+
+type CatalogRepository interface {
+	// Catalog returns every brand and model with their aliases.
+	Catalog(ctx context.Context) (*Catalog, error)
+	// CreateBrand adds a brand, returning CatalogConflictError if its name or
+	// an alias already resolves to another brand.
+	CreateBrand(ctx context.Context, brand *Brand) error
+	// CreateModel adds a model to model.BrandID, returning ErrNotFound if the
+	// brand does not exist and CatalogConflictError like CreateBrand.
+	CreateModel(ctx context.Context, model *BrandModel) error
+}
+
+type FeedRepository interface {
+	// Record stores a run of the scheduled feed export, filling in its ID.
+	Record(ctx context.Context, export *FeedExport) error
+	// Last returns the most recent export, or ErrNotFound if there is none.
+	Last(ctx context.Context) (*FeedExport, error)
+}
+
+type ClientRepository interface {
+	Create(ctx context.Context, client *Client) error
+	GetAll(ctx context.Context) ([]Client, error)
+	GetByID(ctx context.Context, id int64) (*Client, error)
+	Update(ctx context.Context, client *Client) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type SaleRepository interface {
+	// Create records the sale and marks the vehicle as sold. It returns
+	// VehicleAlreadySoldError if the vehicle already has a sale,
+	// VehicleReservedError if another client holds it, BelowMinimumPriceError
+	// if the price is below that of an active consignment and ErrNotFound if
+	// the client or vehicle does not exist. A reservation held by the buyer
+	// is converted and its deposit credited; an active consignment is closed
+	// as sold with its commission and the amount owed to the owner.
+	Create(ctx context.Context, sale *Sale) error
+	GetAll(ctx context.Context) ([]SaleWithDetails, error)
+	GetByID(ctx context.Context, id int64) (*SaleWithDetails, error)
+}
+
+type ReservationRepository interface {
+	// Create stores an active reservation and moves the vehicle to reserved.
+	Create(ctx context.Context, reservation *Reservation) error
+	GetAll(ctx context.Context) ([]Reservation, error)
+	GetByID(ctx context.Context, id int64) (*Reservation, error)
+	// Cancel closes an active reservation and makes the vehicle available again.
+	Cancel(ctx context.Context, id int64) error
+	// Convert sells the reserved vehicle to the reservation's client,
+	// filling in sale's client, vehicle and reservation.
+	Convert(ctx context.Context, id int64, sale *Sale) error
+	// ExpireDue closes the active reservations that expired by now, makes
+	// their vehicles available and returns them.
+	ExpireDue(ctx context.Context, now time.Time) ([]Reservation, error)
+}
+
+type ConsignmentRepository interface {
+	// Create stores an active consignment. It returns ErrNotFound if the
+	// vehicle or owner does not exist, VehicleAlreadySoldError if the vehicle
+	// is sold and VehicleConsignedError if it is already consigned.
+	Create(ctx context.Context, consignment *Consignment) error
+	GetAll(ctx context.Context) ([]Consignment, error)
+	GetByID(ctx context.Context, id int64) (*Consignment, error)
+	// Withdraw closes an active consignment when the owner takes the vehicle
+	// back, returning ConsignmentNotActiveError otherwise.
+	Withdraw(ctx context.Context, id int64) error
+	// Settlement returns the statement of a sold consignment, or
+	// ConsignmentNotActiveError if it has not been sold.
+	Settlement(ctx context.Context, id int64) (*ConsignmentSettlement, error)
+}
+
+type LocationRepository interface {
+	// Create and Update return DuplicateLocationError if the name is taken.
+	Create(ctx context.Context, location *Location) error
+	GetAll(ctx context.Context) ([]Location, error)
+	GetByID(ctx context.Context, id int64) (*Location, error)
+	Update(ctx context.Context, location *Location) error
+	// Delete returns ErrInUse while vehicles, transfers or test drives
+	// reference the location.
+	Delete(ctx context.Context, id int64) error
+	// Stock counts the vehicles in stock at each location, ordered by name,
+	// followed by those without a location if there are any.
+	Stock(ctx context.Context) ([]LocationStock, error)
+}
+
+type TransferRepository interface {
+	// Request opens a transfer of an in-stock vehicle from its current
+	// location. It returns ErrNotFound if the vehicle or destination does not
+	// exist, VehicleInTransferError if the vehicle already has an open
+	// transfer and ValidationErrors if the vehicle is not in stock or is
+	// already at the destination.
+	Request(ctx context.Context, transfer *VehicleTransfer) error
+	// GetAll lists the transfers, newest first, optionally only those of a vehicle.
+	GetAll(ctx context.Context, vehicleID *int64) ([]VehicleTransfer, error)
+	GetByID(ctx context.Context, id int64) (*VehicleTransfer, error)
+	// Dispatch puts a requested transfer in transit with its driver.
+	Dispatch(ctx context.Context, id int64, driver string) error
+	// Receive completes a transfer in transit and moves the vehicle to the
+	// destination.
+	Receive(ctx context.Context, id int64) error
+	// Cancel closes a transfer that has not been dispatched.
+	// Dispatch, Receive and Cancel return TransferStatusError when the
+	// transfer is not in the status they expect.
+	Cancel(ctx context.Context, id int64) error
+}
+
+type TestDriveRepository interface {
+	// Book stores a scheduled test drive at the vehicle's current location.
+	// It returns ErrNotFound if the client or vehicle does not exist,
+	// VehicleNotBookableError if the vehicle is sold, reserved or scrapped,
+	// VehicleInTransferError if it has an open transfer, and
+	// TestDriveConflictError if it overlaps a scheduled test drive of the
+	// same vehicle or salesperson.
+	Book(ctx context.Context, drive *TestDrive) error
+	// Find returns the test drives selected by filter, by start time.
+	Find(ctx context.Context, filter TestDriveFilter) ([]TestDriveWithDetails, error)
+	GetByID(ctx context.Context, id int64) (*TestDriveWithDetails, error)
+	// Cancel frees the slot of a scheduled test drive, returning
+	// TestDriveNotScheduledError otherwise.
+	Cancel(ctx context.Context, id int64) error
+}
+
+// Every repository method takes the caller's context; implementations must
+// stop work and return ctx.Err() (or the driver's error) once it is done.
+
+// Repositories groups the storage backend injected into the route handlers.
+type Repositories struct {
+	Vehicles     VehicleRepository
+	Clients      ClientRepository
+	Sales        SaleRepository
+	Reservations ReservationRepository
+	Expenses     ExpenseRepository
+	Media        MediaRepository
+	Documents    DocumentRepository
+	Feeds        FeedRepository
+	Catalog      CatalogRepository
+	Consignments ConsignmentRepository
+	Locations    LocationRepository
+	Transfers    TransferRepository
+	TestDrives   TestDriveRepository
+}
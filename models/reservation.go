@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationConverted ReservationStatus = "converted"
+	ReservationCancelled ReservationStatus = "cancelled"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// Reservation holds a vehicle for a client until it expires, is cancelled or
+// is converted into a sale that credits the deposit.
+type Reservation struct {
+	ID        int64             `json:"id"`
+	ClientID  int64             `json:"client_id" binding:"required"`
+	VehicleID int64             `json:"vehicle_id" binding:"required"`
+	Deposit   float64           `json:"deposit"`
+	ExpiresAt time.Time         `json:"expires_at" binding:"required"`
+	Status    ReservationStatus `json:"status"`
+	CreatedAt time.Time         `json:"created_at"`
+	ClosedAt  *time.Time        `json:"closed_at,omitempty"`
+	// SaleID is set once the reservation has been converted.
+	SaleID *int64 `json:"sale_id,omitempty"`
+}
+
+// Validate checks a new reservation.
+func (r *Reservation) Validate(now time.Time) error {
+	var errs ValidationErrors
+	if r.Deposit < 0 {
+		errs = append(errs, ValidationError{"deposit", "must not be negative"})
+	}
+	if !r.ExpiresAt.After(now) {
+		errs = append(errs, ValidationError{"expires_at", "must be in the future"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Holds reports whether the reservation still blocks the vehicle at now.
+func (r *Reservation) Holds(now time.Time) bool {
+	return r.Status == ReservationActive && r.ExpiresAt.After(now)
+}
+
+// VehicleReservedError is returned when a vehicle is held by another
+// client's reservation.
+type VehicleReservedError struct {
+	VehicleID     int64
+	ReservationID int64
+	ExpiresAt     time.Time
+}
+
+func (e *VehicleReservedError) Error() string {
+	return fmt.Sprintf("vehicle %d is reserved until %s", e.VehicleID, e.ExpiresAt.Format(time.RFC3339))
+}
+
+// ReservationNotActiveError is returned when cancelling or converting a
+// reservation that is no longer active.
+type ReservationNotActiveError struct {
+	ReservationID int64
+	Status        ReservationStatus
+}
+
+func (e *ReservationNotActiveError) Error() string {
+	return fmt.Sprintf("reservation %d is %s", e.ReservationID, e.Status)
+}
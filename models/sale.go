@@ -1,11 +1,8 @@
 package models
 
 import (
-	"database/sql"
-	"log"
+	"fmt"
 	"time"
-
-	"github.com/Stand/db"
 )
 
 type Sale struct {
@@ -14,144 +11,85 @@ type Sale struct {
 	VehicleID int64     `json:"vehicle_id" binding:"required"`
 	Price     float64   `json:"price" binding:"required"`
 	SaleDate  time.Time `json:"sale_date"`
+	// ReservationID is the client's reservation converted by the sale. It is
+	// filled in when the client holds the vehicle even if not submitted.
+	ReservationID   *int64  `json:"reservation_id,omitempty"`
+	DepositCredited float64 `json:"deposit_credited"`
+	// ConsignmentID is set when the vehicle was sold on behalf of its owner.
+	ConsignmentID *int64 `json:"consignment_id,omitempty"`
+	// TradeIns are the client's vehicles taken in part-exchange; their
+	// valuations add up to TradeInCredit.
+	TradeIns      []TradeIn `json:"trade_ins,omitempty" binding:"omitempty,dive"`
+	TradeInCredit float64   `json:"trade_in_credit"`
+	// AmountDue is the price minus the credited deposit and trade-ins; it is
+	// negative when the stand owes the client the difference.
+	AmountDue float64 `json:"amount_due"`
 }
 
-// SaleWithDetails represents a sale with client and vehicle information
-type SaleWithDetails struct {
-	ID       int64     `json:"id"`
-	Price    float64   `json:"price"`
-	SaleDate time.Time `json:"sale_date"`
-	Client   Client    `json:"client"`
-	Vehicle  Vehicle   `json:"vehicle"`
+// TradeIn is a vehicle handed over by the buyer as part of a sale. The
+// vehicle is added to the inventory as incoming, with the valuation as its
+// purchase price and the buyer as its previous owner.
+type TradeIn struct {
+	Valuation float64 `json:"valuation" binding:"required"`
+	Vehicle   Vehicle `json:"vehicle"`
 }
 
-func (s *Sale) Save() error {
-	log.Printf("[v0] Starting Sale.Save() with data: %+v", s)
+// PrepareTradeIns validates the trade-ins of a new sale and fills in the
+// fields derived from the sale. complete fills in each normalised vehicle's
+// engine fields from its Motor; it is engine.Complete, which this package
+// cannot import.
+func (s *Sale) PrepareTradeIns(complete func(*Vehicle)) error {
+	var errs ValidationErrors
+	s.TradeInCredit = 0
 
-	// First check if vehicle is already sold
-	var existingSaleID int64
-	checkQuery := "SELECT id FROM sales WHERE vehicle_id = $1"
-	err := db.DB.QueryRow(checkQuery, s.VehicleID).Scan(&existingSaleID)
+	for i := range s.TradeIns {
+		tradeIn := &s.TradeIns[i]
+		prefix := fmt.Sprintf("trade_ins[%d].", i)
 
-	if err != sql.ErrNoRows {
-		if err == nil {
-			log.Printf("[v0] Vehicle %d is already sold", s.VehicleID)
-			return &VehicleAlreadySoldError{VehicleID: s.VehicleID}
+		if tradeIn.Valuation <= 0 {
+			errs = append(errs, ValidationError{prefix + "valuation", "must be positive"})
+		}
+		s.TradeInCredit += tradeIn.Valuation
+
+		vehicle := &tradeIn.Vehicle
+		vehicle.ID = 0
+		vehicle.Status = StatusIncoming
+		vehicle.PurchasePrice = tradeIn.Valuation
+		vehicle.PreviousOwnerID = &s.ClientID
+		vehicle.Normalize()
+		complete(vehicle)
+		if err := vehicle.Validate(); err != nil {
+			for _, fieldErr := range err.(ValidationErrors) {
+				errs = append(errs, ValidationError{prefix + "vehicle." + fieldErr.Field, fieldErr.Message})
+			}
 		}
-		log.Printf("[v0] Error checking existing sale: %v", err)
-		return err
-	}
-
-	// Check if vehicle exists and is available
-	vehicle, err := GetVehicleByID(s.VehicleID)
-	if err != nil {
-		log.Printf("[v0] Error getting vehicle: %v", err)
-		return err
-	}
-
-	if vehicle.Status == "sold" {
-		log.Printf("[v0] Vehicle %d status is already 'sold'", s.VehicleID)
-		return &VehicleAlreadySoldError{VehicleID: s.VehicleID}
-	}
-
-	// Check if client exists
-	_, err = GetClientByID(s.ClientID)
-	if err != nil {
-		log.Printf("[v0] Error getting client: %v", err)
-		return err
-	}
-
-	// Create the sale
-	s.SaleDate = time.Now()
-	query := `INSERT INTO sales (client_id, vehicle_id, price, sale_date)
-	VALUES ($1, $2, $3, $4) RETURNING id`
-
-	log.Printf("[v0] SQL Query: %s", query)
-	log.Printf("[v0] Parameters: client_id=%d, vehicle_id=%d, price=%.2f, sale_date=%v",
-		s.ClientID, s.VehicleID, s.Price, s.SaleDate)
-
-	err = db.DB.QueryRow(query, s.ClientID, s.VehicleID, s.Price, s.SaleDate).Scan(&s.ID)
-	if err != nil {
-		log.Printf("[v0] QueryRow/Scan error: %v", err)
-		return err
 	}
 
-	log.Printf("[v0] Sale saved successfully with ID: %d", s.ID)
-
-	// Update vehicle status to sold
-	updateQuery := "UPDATE vehicles SET status = 'sold' WHERE id = $1"
-	_, err = db.DB.Exec(updateQuery, s.VehicleID)
-	if err != nil {
-		log.Printf("[v0] Error updating vehicle status: %v", err)
-		return err
+	if len(errs) > 0 {
+		return errs
 	}
-
-	log.Printf("[v0] Vehicle %d status updated to 'sold'", s.VehicleID)
 	return nil
 }
 
-func GetAllSales() ([]SaleWithDetails, error) {
-	query := `
-	SELECT 
-		s.id, s.price, s.sale_date,
-		c.id, c.name, c.email, c.phone,
-		v.id, v.type, v.brand, v.model, v.year, v.motor, v.status
-	FROM sales s
-	JOIN clients c ON s.client_id = c.id
-	JOIN vehicles v ON s.vehicle_id = v.id
-	ORDER BY s.sale_date DESC`
-
-	rows, err := db.DB.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var sales []SaleWithDetails
-
-	for rows.Next() {
-		var sale SaleWithDetails
-		err := rows.Scan(
-			&sale.ID, &sale.Price, &sale.SaleDate,
-			&sale.Client.ID, &sale.Client.Name, &sale.Client.Email, &sale.Client.Phone,
-			&sale.Vehicle.ID, &sale.Vehicle.Type, &sale.Vehicle.Brand,
-			&sale.Vehicle.Model, &sale.Vehicle.Year, &sale.Vehicle.Motor, &sale.Vehicle.Status,
-		)
-		if err != nil {
-			return nil, err
-		}
-		sales = append(sales, sale)
-	}
-
-	return sales, nil
-}
-
-func GetSaleByID(id int64) (*SaleWithDetails, error) {
-	query := `
-	SELECT 
-		s.id, s.price, s.sale_date,
-		c.id, c.name, c.email, c.phone,
-		v.id, v.type, v.brand, v.model, v.year, v.motor, v.status
-	FROM sales s
-	JOIN clients c ON s.client_id = c.id
-	JOIN vehicles v ON s.vehicle_id = v.id
-	WHERE s.id = $1`
-
-	row := db.DB.QueryRow(query, id)
-
-	var sale SaleWithDetails
-	err := row.Scan(
-		&sale.ID, &sale.Price, &sale.SaleDate,
-		&sale.Client.ID, &sale.Client.Name, &sale.Client.Email, &sale.Client.Phone,
-		&sale.Vehicle.ID, &sale.Vehicle.Type, &sale.Vehicle.Brand,
-		&sale.Vehicle.Model, &sale.Vehicle.Year, &sale.Vehicle.Motor, &sale.Vehicle.Status,
-	)
-
-	if err != nil {
-		return nil, err
-	}
+// SaleWithDetails represents a sale with client and vehicle information
+type SaleWithDetails struct {
+	ID       int64     `json:"id"`
+	Price    float64   `json:"price"`
+	SaleDate time.Time `json:"sale_date"`
+	Client   Client    `json:"client"`
+	Vehicle  Vehicle   `json:"vehicle"`
 
-	return &sale, nil
+	ReservationID   *int64    `json:"reservation_id,omitempty"`
+	DepositCredited float64   `json:"deposit_credited"`
+	ConsignmentID   *int64    `json:"consignment_id,omitempty"`
+	TradeIns        []TradeIn `json:"trade_ins,omitempty"`
+	TradeInCredit   float64   `json:"trade_in_credit"`
+	AmountDue       float64   `json:"amount_due"`
+
+	// Cost is the vehicle's cost as defined by VehicleCost; GrossMargin is
+	// the price minus the cost.
+	Cost        float64 `json:"cost"`
+	GrossMargin float64 `json:"gross_margin"`
 }
 
 type VehicleAlreadySoldError struct {
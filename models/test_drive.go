@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type TestDriveStatus string
+
+const (
+	TestDriveScheduled TestDriveStatus = "scheduled"
+	TestDriveCancelled TestDriveStatus = "cancelled"
+)
+
+// TestDrive books a vehicle for a client, accompanied by a salesperson,
+// between StartsAt and EndsAt. Scheduled test drives of the same vehicle or
+// salesperson cannot overlap.
+type TestDrive struct {
+	ID          int64  `json:"id"`
+	ClientID    int64  `json:"client_id" binding:"required"`
+	VehicleID   int64  `json:"vehicle_id" binding:"required"`
+	Salesperson string `json:"salesperson" binding:"required"`
+	// LocationID is the vehicle's location when the test drive was booked.
+	LocationID *int64          `json:"location_id"`
+	StartsAt   time.Time       `json:"starts_at" binding:"required"`
+	EndsAt     time.Time       `json:"ends_at" binding:"required"`
+	Notes      string          `json:"notes"`
+	Status     TestDriveStatus `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	// CancelledAt is set once the test drive has been cancelled.
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// Validate trims and checks a new test drive, storing its times in UTC to
+// the second.
+func (d *TestDrive) Validate(now time.Time) error {
+	d.Salesperson = strings.TrimSpace(d.Salesperson)
+	d.Notes = strings.TrimSpace(d.Notes)
+	d.StartsAt = d.StartsAt.UTC().Truncate(time.Second)
+	d.EndsAt = d.EndsAt.UTC().Truncate(time.Second)
+
+	var errs ValidationErrors
+	if d.Salesperson == "" {
+		errs = append(errs, ValidationError{"salesperson", "is required"})
+	}
+	if !d.StartsAt.After(now) {
+		errs = append(errs, ValidationError{"starts_at", "must be in the future"})
+	}
+	if !d.EndsAt.After(d.StartsAt) {
+		errs = append(errs, ValidationError{"ends_at", "must be after starts_at"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Overlaps reports whether two test drives share any time. One that ends
+// when the other starts does not overlap it.
+func (d *TestDrive) Overlaps(other *TestDrive) bool {
+	return d.StartsAt.Before(other.EndsAt) && other.StartsAt.Before(d.EndsAt)
+}
+
+// Conflicts reports whether other is a scheduled test drive that overlaps d
+// with the same vehicle or salesperson.
+func (d *TestDrive) Conflicts(other *TestDrive) bool {
+	if other.Status != TestDriveScheduled || !d.Overlaps(other) {
+		return false
+	}
+	return other.VehicleID == d.VehicleID || Fold(other.Salesperson) == Fold(d.Salesperson)
+}
+
+// CheckBookable returns VehicleNotBookableError for vehicles that cannot be
+// test driven: sold, reserved for a client or scrapped.
+func CheckBookable(vehicleID int64, status VehicleStatus) error {
+	switch status {
+	case StatusSold, StatusReserved, StatusScrapped:
+		return &VehicleNotBookableError{VehicleID: vehicleID, Status: status}
+	}
+	return nil
+}
+
+// TestDriveFilter selects test drives; zero fields match everything.
+// Salesperson is compared like Fold.
+type TestDriveFilter struct {
+	VehicleID   *int64
+	LocationID  *int64
+	Salesperson string
+	// EndsAfter keeps the test drives that end after it.
+	EndsAfter *time.Time
+	// Scheduled leaves out cancelled test drives.
+	Scheduled bool
+}
+
+// Matches reports whether a test drive is selected by the filter.
+func (f *TestDriveFilter) Matches(d *TestDrive) bool {
+	switch {
+	case f.VehicleID != nil && d.VehicleID != *f.VehicleID:
+		return false
+	case f.LocationID != nil && (d.LocationID == nil || *d.LocationID != *f.LocationID):
+		return false
+	case f.Salesperson != "" && Fold(d.Salesperson) != Fold(f.Salesperson):
+		return false
+	case f.EndsAfter != nil && !d.EndsAt.After(*f.EndsAfter):
+		return false
+	case f.Scheduled && d.Status != TestDriveScheduled:
+		return false
+	}
+	return true
+}
+
+// TestDriveWithDetails is a test drive with its client, vehicle and
+// location, if any.
+type TestDriveWithDetails struct {
+	TestDrive
+	Client   Client    `json:"client"`
+	Vehicle  Vehicle   `json:"vehicle"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// VehicleNotBookableError is returned when booking a test drive of a vehicle
+// that is sold, reserved or scrapped.
+type VehicleNotBookableError struct {
+	VehicleID int64
+	Status    VehicleStatus
+}
+
+func (e *VehicleNotBookableError) Error() string {
+	return fmt.Sprintf("vehicle %d is %s and cannot be test driven", e.VehicleID, e.Status)
+}
+
+// TestDriveConflictError is returned when a test drive overlaps another
+// scheduled test drive of the same vehicle or salesperson.
+type TestDriveConflictError struct {
+	TestDriveID int64
+	// Field is "vehicle_id" or "salesperson", whichever is double-booked.
+	Field    string
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+func (e *TestDriveConflictError) Error() string {
+	return fmt.Sprintf("test drive overlaps test drive %d with the same %s from %s to %s", e.TestDriveID, e.Field,
+		e.StartsAt.Format(time.RFC3339), e.EndsAt.Format(time.RFC3339))
+}
+
+// NewTestDriveConflictError describes the conflict of d with other.
+func NewTestDriveConflictError(d, other *TestDrive) *TestDriveConflictError {
+	field := "salesperson"
+	if other.VehicleID == d.VehicleID {
+		field = "vehicle_id"
+	}
+	return &TestDriveConflictError{TestDriveID: other.ID, Field: field, StartsAt: other.StartsAt, EndsAt: other.EndsAt}
+}
+
+// TestDriveNotScheduledError is returned when cancelling a test drive that
+// is already cancelled.
+type TestDriveNotScheduledError struct {
+	TestDriveID int64
+	Status      TestDriveStatus
+}
+
+func (e *TestDriveNotScheduledError) Error() string {
+	return fmt.Sprintf("test drive %d is %s", e.TestDriveID, e.Status)
+}
@@ -1,152 +1,129 @@
 package models
 
 import (
-	"fmt"
-	"github.com/Stand/db"
-	"log"
+	"strings"
+	"time"
 )
 
 type Vehicle struct {
-	ID     int
-	Type   string `binding:"required"`
-	Brand  string `binding:"required"`
-	Model  string `binding:"required"`
-	Year   int    `binding:"required"`
-	Motor  string `binding:"required"`
-	Status string `binding:"required"`
+	ID int
+	// Type is one of VehicleTypes, which decides the type-specific
+	// attributes below that apply.
+	Type string `binding:"required"`
+	// Brand and Year may be left empty when they are decoded from the VIN.
+	// Brand and Model are resolved against the catalogue on every write and
+	// replaced by the catalogue names; BrandID and ModelID may be given
+	// instead. They are nil for vehicles stored before the catalogue until
+	// they are normalised.
+	Brand   string
+	Model   string
+	BrandID *int64
+	ModelID *int64
+	Year    int
+	Motor   string `binding:"required"`
+	// Status defaults to incoming on creation and afterwards only changes
+	// through status transitions.
+	Status VehicleStatus
+
+	VIN          string
+	LicensePlate string
+	Mileage      int // odometer reading in km
+	Colour       string
+	Transmission Transmission
+	AskingPrice  float64
+
+	// Type-specific attributes; zero or empty means not given.
+	Doors           int
+	Seats           int
+	EngineCC        int    // cylinder capacity (displacement)
+	LicenceCategory string // AM, A1, A2 or A
+	PayloadKg       int
+	Axles           int
+
+	// Engine data; zero or empty means not given. Motor remains the
+	// description shown in listings; fields left empty are filled in from
+	// it on creation, update and import, and for stored vehicles by the
+	// "engine migrate" command.
+	FuelType         FuelType
+	PowerKW          int
+	PowerHP          int     // metric horsepower (cv); filled in from PowerKW if left out
+	CO2              int     // emissions in g/km
+	EmissionStandard string  // e.g. "Euro 6d"
+	BatteryKWh       float64 // capacity of the traction battery
+
+	// Acquisition: what the stand paid for the vehicle, to whom and when.
+	PurchasePrice   float64
+	Supplier        string
+	AcquisitionDate *time.Time
+	// PreviousOwnerID is the client who traded the vehicle in, if any.
+	PreviousOwnerID *int64
+	// LocationID is where the vehicle is kept. It may be given on creation
+	// and afterwards only changes when a transfer is received.
+	LocationID *int64
+
+	// UpdatedAt is set by the repository whenever the vehicle, its status,
+	// asking price or photos change.
+	UpdatedAt time.Time
 }
 
-var vehicles = []Vehicle{}
-
-func (v *Vehicle) Save() error {
-	log.Printf("[v0] Starting Vehicle.Save() with data: %+v", v)
-
-	query := `
-	INSERT INTO vehicles(type, brand, model, year, motor, status)
-	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
-
-	log.Printf("[v0] SQL Query: %s", query)
-	log.Printf("[v0] Parameters: type=%s, brand=%s, model=%s, year=%d, motor=%s, status=%s",
-		v.Type, v.Brand, v.Model, v.Year, v.Motor, v.Status)
-
-	err := db.DB.QueryRow(query, v.Type, v.Brand, v.Model, v.Year, v.Motor, v.Status).Scan(&v.ID)
-	if err != nil {
-		log.Printf("[v0] QueryRow/Scan error: %v", err)
-		return err
+// Normalize puts the type, VIN, licence plate, transmission, licence
+// category and engine data in their canonical form; call it before Validate.
+func (v *Vehicle) Normalize() {
+	if spec, ok := LookupVehicleType(v.Type); ok {
+		v.Type = spec.Name
 	}
-
-	log.Printf("[v0] Vehicle saved successfully with ID: %d", v.ID)
-	return nil
+	v.LicenceCategory = strings.ToUpper(strings.TrimSpace(v.LicenceCategory))
+	v.VIN = NormalizeVIN(v.VIN)
+	v.Transmission = Transmission(strings.ToLower(strings.TrimSpace(string(v.Transmission))))
+	if plate, err := NormalizeLicensePlate(v.LicensePlate); err == nil {
+		v.LicensePlate = plate
+	}
+	v.normalizeEngine()
 }
 
-func GetAllVehicles() ([]Vehicle, error) {
-	query := "SELECT * FROM vehicles"
-	rows, err := db.DB.Query(query)
+// Validate checks the vehicle fields that the binding tags cannot express.
+func (v *Vehicle) Validate() error {
+	var errs ValidationErrors
 
-	if err != nil {
-		return nil, err
+	if strings.TrimSpace(v.Brand) == "" && v.BrandID == nil {
+		errs = append(errs, ValidationError{"Brand", "is required"})
 	}
-
-	defer rows.Close()
-
-	var vehicles []Vehicle
-
-	for rows.Next() {
-		var vehicle Vehicle
-		err := rows.Scan(&vehicle.ID, &vehicle.Type, &vehicle.Brand, &vehicle.Model, &vehicle.Year, &vehicle.Motor, &vehicle.Status)
-
-		if err != nil {
-			return nil, err
-		}
-
-		vehicles = append(vehicles, vehicle)
+	if strings.TrimSpace(v.Model) == "" && v.ModelID == nil {
+		errs = append(errs, ValidationError{"Model", "is required"})
 	}
-	return vehicles, nil
-}
-
-func GetVehicleByID(id int64) (*Vehicle, error) {
-	query := "SELECT * FROM vehicles WHERE id=$1"
-	row := db.DB.QueryRow(query, id)
-
-	var vehicle Vehicle
-	err := row.Scan(&vehicle.ID, &vehicle.Type, &vehicle.Brand, &vehicle.Model, &vehicle.Year, &vehicle.Motor, &vehicle.Status)
-
-	if err != nil {
-		return nil, err
+	if v.Year < 1886 || v.Year > time.Now().Year()+1 {
+		errs = append(errs, ValidationError{"Year", "is out of range"})
 	}
-
-	return &vehicle, nil
-}
-
-func (vehicle Vehicle) UpdateVehicle() error {
-	query := `UPDATE vehicles 
-	SET type=$1, brand=$2, model=$3, year=$4, motor=$5, status=$6
-	WHERE id=$7
-	`
-	stmt, err := db.DB.Prepare(query)
-
-	if err != nil {
-		return err
+	if v.VIN != "" {
+		if err := ValidateVIN(v.VIN); err != nil {
+			errs = append(errs, ValidationError{"VIN", err.Error()})
+		}
 	}
-
-	defer stmt.Close()
-
-	_, err = stmt.Exec(vehicle.Type, vehicle.Brand, vehicle.Model, vehicle.Year, vehicle.Motor, vehicle.Status, vehicle.ID)
-	return err
-}
-
-func (vehicle Vehicle) Delete() error {
-	query := "DELETE FROM vehicles WHERE id = $1"
-	stmt, err := db.DB.Prepare(query)
-
-	if err != nil {
-		return err
+	if v.LicensePlate != "" {
+		if _, err := NormalizeLicensePlate(v.LicensePlate); err != nil {
+			errs = append(errs, ValidationError{"LicensePlate", err.Error()})
+		}
 	}
-
-	defer stmt.Close()
-
-	_, err = stmt.Exec(vehicle.ID)
-	return err
-}
-
-func GetVehiclesWithFilters(vehicleType, brand string, year int) ([]Vehicle, error) {
-	query := "SELECT * FROM vehicles WHERE 1=1"
-	var args []interface{}
-	paramCount := 1
-
-	if vehicleType != "" {
-		query += " AND type = $" + fmt.Sprintf("%d", paramCount)
-		args = append(args, vehicleType)
-		paramCount++
+	if v.Mileage < 0 {
+		errs = append(errs, ValidationError{"Mileage", "must not be negative"})
 	}
-
-	if brand != "" {
-		query += " AND brand = $" + fmt.Sprintf("%d", paramCount)
-		args = append(args, brand)
-		paramCount++
+	if v.Transmission != "" && !v.Transmission.Valid() {
+		errs = append(errs, ValidationError{"Transmission", "must be manual, automatic or semi_automatic"})
 	}
-
-	if year > 0 {
-		query += " AND year = $" + fmt.Sprintf("%d", paramCount)
-		args = append(args, year)
-		paramCount++
+	errs = append(errs, v.validateAttributes()...)
+	errs = append(errs, v.validateEngine()...)
+	if v.AskingPrice < 0 {
+		errs = append(errs, ValidationError{"AskingPrice", "must not be negative"})
 	}
-
-	rows, err := db.DB.Query(query, args...)
-	if err != nil {
-		return nil, err
+	if v.PurchasePrice < 0 {
+		errs = append(errs, ValidationError{"PurchasePrice", "must not be negative"})
 	}
-	defer rows.Close()
-
-	var vehicles []Vehicle
-	for rows.Next() {
-		var vehicle Vehicle
-		err := rows.Scan(&vehicle.ID, &vehicle.Type, &vehicle.Brand, &vehicle.Model, &vehicle.Year, &vehicle.Motor, &vehicle.Status)
-		if err != nil {
-			return nil, err
-		}
-		vehicles = append(vehicles, vehicle)
+	if v.AcquisitionDate != nil && v.AcquisitionDate.After(time.Now()) {
+		errs = append(errs, ValidationError{"AcquisitionDate", "must not be in the future"})
 	}
 
-	return vehicles, nil
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// InStockStatuses are the statuses of vehicles that are still on the lot.
+var InStockStatuses = []VehicleStatus{StatusIncoming, StatusInPreparation, StatusAvailable, StatusReserved, StatusReturned}
+
+// AgingBucketLimits are the upper bounds, in days, of every aging bucket but
+// the last, which is open-ended.
+var AgingBucketLimits = []int{30, 60, 90, 180}
+
+// StockVehicle is a vehicle on the lot with the date it entered stock and
+// the total of its expense ledger.
+type StockVehicle struct {
+	Vehicle Vehicle
+	// Since is the acquisition date, or the creation date when it is unknown.
+	Since    time.Time
+	Expenses float64
+}
+
+// DaysInStock is the number of whole days between Since and now.
+func (s *StockVehicle) DaysInStock(now time.Time) int {
+	days := int(now.Sub(s.Since).Hours() / 24)
+	return max(days, 0)
+}
+
+// Cost is what the stand has spent on the vehicle so far.
+func (s *StockVehicle) Cost() float64 {
+	return s.Vehicle.PurchasePrice + s.Expenses
+}
+
+// VehicleAge is a line of the inventory aging report.
+type VehicleAge struct {
+	VehicleID    int           `json:"vehicle_id"`
+	Type         string        `json:"type"`
+	Brand        string        `json:"brand"`
+	Model        string        `json:"model"`
+	Status       VehicleStatus `json:"status"`
+	AskingPrice  float64       `json:"asking_price"`
+	InStockSince time.Time     `json:"in_stock_since"`
+	DaysInStock  int           `json:"days_in_stock"`
+	Bucket       string        `json:"bucket"`
+}
+
+// AgingGroup summarises the age of the vehicles sharing a brand or type.
+type AgingGroup struct {
+	Value       string       `json:"value"`
+	Count       int          `json:"count"`
+	AverageDays float64      `json:"average_days"`
+	MaxDays     int          `json:"max_days"`
+	Buckets     []FacetCount `json:"buckets"`
+}
+
+// InventoryAging reports how long the vehicles in stock have been on the
+// lot, oldest first, with totals per aging bucket, brand and type.
+type InventoryAging struct {
+	AsOf        time.Time    `json:"as_of"`
+	Total       int          `json:"total"`
+	AverageDays float64      `json:"average_days"`
+	Buckets     []FacetCount `json:"buckets"`
+	ByBrand     []AgingGroup `json:"by_brand"`
+	ByType      []AgingGroup `json:"by_type"`
+	Vehicles    []VehicleAge `json:"vehicles"`
+}
+
+// AgingBucket returns the index of the bucket containing days.
+func AgingBucket(days int) int {
+	for i, limit := range AgingBucketLimits {
+		if days <= limit {
+			return i
+		}
+	}
+	return len(AgingBucketLimits)
+}
+
+// AgingBucketFacet describes the bucket with the given index; Min and Max
+// are inclusive numbers of days.
+func AgingBucketFacet(index, count int) FacetCount {
+	min := 0.0
+	if index > 0 {
+		min = float64(AgingBucketLimits[index-1] + 1)
+	}
+	if index == len(AgingBucketLimits) {
+		return FacetCount{Value: fmt.Sprintf("%d+", int(min)), Count: count, Min: &min}
+	}
+	max := float64(AgingBucketLimits[index])
+	return FacetCount{Value: fmt.Sprintf("%d-%d", int(min), int(max)), Count: count, Min: &min, Max: &max}
+}
+
+type agingTally struct {
+	label   string
+	count   int
+	days    int
+	maxDays int
+	buckets []int
+}
+
+func (t *agingTally) add(days int) {
+	if t.buckets == nil {
+		t.buckets = make([]int, len(AgingBucketLimits)+1)
+	}
+	t.count++
+	t.days += days
+	t.maxDays = max(t.maxDays, days)
+	t.buckets[AgingBucket(days)]++
+}
+
+func (t *agingTally) average() float64 {
+	if t.count == 0 {
+		return 0
+	}
+	return math.Round(float64(t.days)/float64(t.count)*10) / 10
+}
+
+func (t *agingTally) facets() []FacetCount {
+	facets := make([]FacetCount, len(AgingBucketLimits)+1)
+	for i := range facets {
+		count := 0
+		if t.buckets != nil {
+			count = t.buckets[i]
+		}
+		facets[i] = AgingBucketFacet(i, count)
+	}
+	return facets
+}
+
+// BuildInventoryAging computes the aging report of stock as of now. Brands
+// and types are grouped like the search filters, ignoring case and accents.
+func BuildInventoryAging(stock []StockVehicle, now time.Time) *InventoryAging {
+	report := &InventoryAging{AsOf: now, Total: len(stock), Vehicles: []VehicleAge{}}
+
+	var total agingTally
+	brands := map[string]*agingTally{}
+	types := map[string]*agingTally{}
+
+	for i := range stock {
+		item := &stock[i]
+		days := item.DaysInStock(now)
+
+		report.Vehicles = append(report.Vehicles, VehicleAge{
+			VehicleID:    item.Vehicle.ID,
+			Type:         item.Vehicle.Type,
+			Brand:        item.Vehicle.Brand,
+			Model:        item.Vehicle.Model,
+			Status:       item.Vehicle.Status,
+			AskingPrice:  item.Vehicle.AskingPrice,
+			InStockSince: item.Since,
+			DaysInStock:  days,
+			Bucket:       AgingBucketFacet(AgingBucket(days), 0).Value,
+		})
+
+		total.add(days)
+		tallyGroup(brands, item.Vehicle.Brand).add(days)
+		tallyGroup(types, item.Vehicle.Type).add(days)
+	}
+
+	sort.SliceStable(report.Vehicles, func(i, j int) bool {
+		if report.Vehicles[i].DaysInStock != report.Vehicles[j].DaysInStock {
+			return report.Vehicles[i].DaysInStock > report.Vehicles[j].DaysInStock
+		}
+		return report.Vehicles[i].VehicleID < report.Vehicles[j].VehicleID
+	})
+
+	report.AverageDays = total.average()
+	report.Buckets = total.facets()
+	report.ByBrand = agingGroups(brands)
+	report.ByType = agingGroups(types)
+	return report
+}
+
+func tallyGroup(groups map[string]*agingTally, label string) *agingTally {
+	key := Fold(label)
+	group, ok := groups[key]
+	if !ok {
+		group = &agingTally{label: label}
+		groups[key] = group
+	} else if label < group.label {
+		group.label = label
+	}
+	return group
+}
+
+// agingGroups lists the groups with the oldest average first.
+func agingGroups(tallies map[string]*agingTally) []AgingGroup {
+	groups := []AgingGroup{}
+	for _, tally := range tallies {
+		groups = append(groups, AgingGroup{
+			Value:       tally.label,
+			Count:       tally.count,
+			AverageDays: tally.average(),
+			MaxDays:     tally.maxDays,
+			Buckets:     tally.facets(),
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].AverageDays != groups[j].AverageDays {
+			return groups[i].AverageDays > groups[j].AverageDays
+		}
+		return groups[i].Value < groups[j].Value
+	})
+	return groups
+}
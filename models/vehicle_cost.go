@@ -0,0 +1,114 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+type ExpenseCategory string
+
+const (
+	ExpenseTransport  ExpenseCategory = "transport"
+	ExpenseCleaning   ExpenseCategory = "cleaning"
+	ExpenseRepairs    ExpenseCategory = "repairs"
+	ExpenseInspection ExpenseCategory = "inspection"
+	ExpenseOther      ExpenseCategory = "other"
+)
+
+func (c ExpenseCategory) Valid() bool {
+	switch c {
+	case ExpenseTransport, ExpenseCleaning, ExpenseRepairs, ExpenseInspection, ExpenseOther:
+		return true
+	}
+	return false
+}
+
+// VehicleExpense is an entry of a vehicle's reconditioning expense ledger.
+type VehicleExpense struct {
+	ID          int64           `json:"id"`
+	VehicleID   int64           `json:"vehicle_id"`
+	Category    ExpenseCategory `json:"category" binding:"required"`
+	Description string          `json:"description"`
+	Amount      float64         `json:"amount" binding:"required"`
+	// IncurredOn defaults to the day the expense is recorded.
+	IncurredOn time.Time `json:"incurred_on"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (e *VehicleExpense) Validate() error {
+	var errs ValidationErrors
+	if !e.Category.Valid() {
+		errs = append(errs, ValidationError{"category", "must be transport, cleaning, repairs, inspection or other"})
+	}
+	if e.Amount <= 0 {
+		errs = append(errs, ValidationError{"amount", "must be positive"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// VehicleCost is what a vehicle costs the stand: its purchase price plus
+// its expenses. A consigned vehicle is never bought, so amountOwed, what its
+// owner is paid, takes the place of the purchase price.
+func VehicleCost(vehicle *Vehicle, expenses float64, amountOwed *float64) float64 {
+	if amountOwed != nil {
+		return *amountOwed + expenses
+	}
+	return vehicle.PurchasePrice + expenses
+}
+
+// Profitability is the cost and gross margin of a vehicle. The margin is
+// realised once the vehicle is sold and projected from the asking price
+// until then.
+type Profitability struct {
+	VehicleID     int64   `json:"vehicle_id"`
+	PurchasePrice float64 `json:"purchase_price"`
+	// AmountOwed is what the owner of a consigned vehicle is paid for it, or
+	// would be paid at the asking price while it is unsold.
+	AmountOwed         *float64                    `json:"amount_owed,omitempty"`
+	Expenses           float64                     `json:"expenses"`
+	ExpensesByCategory map[ExpenseCategory]float64 `json:"expenses_by_category"`
+	TotalCost          float64                     `json:"total_cost"`
+	Sold               bool                        `json:"sold"`
+	// Revenue is the sale price, or the asking price while unsold.
+	Revenue       float64  `json:"revenue"`
+	GrossMargin   float64  `json:"gross_margin"`
+	MarginPercent *float64 `json:"margin_percent,omitempty"`
+}
+
+// ComputeProfitability works out a vehicle's profitability from its expense
+// ledger and, if it has been sold, its sale price. consignment is the
+// consignment the vehicle is offered or was sold under, or nil.
+func ComputeProfitability(vehicle *Vehicle, expenses []VehicleExpense, salePrice *float64, consignment *Consignment) *Profitability {
+	p := &Profitability{
+		VehicleID:          int64(vehicle.ID),
+		PurchasePrice:      vehicle.PurchasePrice,
+		ExpensesByCategory: map[ExpenseCategory]float64{},
+		Revenue:            vehicle.AskingPrice,
+	}
+
+	for _, expense := range expenses {
+		p.Expenses += expense.Amount
+		p.ExpensesByCategory[expense.Category] += expense.Amount
+	}
+
+	if salePrice != nil {
+		p.Sold = true
+		p.Revenue = *salePrice
+	}
+	if consignment != nil {
+		owed := consignment.AmountOwed(p.Revenue)
+		p.AmountOwed = &owed
+		p.PurchasePrice = 0
+	}
+	p.TotalCost = VehicleCost(vehicle, p.Expenses, p.AmountOwed)
+
+	p.GrossMargin = p.Revenue - p.TotalCost
+	if p.Revenue > 0 {
+		percent := math.Round(p.GrossMargin/p.Revenue*10000) / 100
+		p.MarginPercent = &percent
+	}
+	return p
+}
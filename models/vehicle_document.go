@@ -0,0 +1,85 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+type DocumentType string
+
+const (
+	// DocumentRegistration is the registration certificate (DUA).
+	DocumentRegistration DocumentType = "dua"
+	// DocumentInspection is the periodic inspection (IPO) report.
+	DocumentInspection  DocumentType = "ipo"
+	DocumentServiceBook DocumentType = "service_book"
+	DocumentOther       DocumentType = "other"
+)
+
+// DocumentTypes lists the accepted document types.
+var DocumentTypes = []DocumentType{DocumentRegistration, DocumentInspection, DocumentServiceBook, DocumentOther}
+
+func (t DocumentType) Valid() bool {
+	return slices.Contains(DocumentTypes, t)
+}
+
+// VehicleDocument is a scan of one of a vehicle's papers, stored in the
+// media storage under StorageKey. Dates are days, at midnight UTC.
+type VehicleDocument struct {
+	ID          int64        `json:"id"`
+	VehicleID   int64        `json:"vehicle_id"`
+	Type        DocumentType `json:"type"`
+	FileName    string       `json:"file_name"`
+	ContentType string       `json:"content_type"`
+	Size        int64        `json:"size"`
+	IssuedOn    *time.Time   `json:"issued_on,omitempty"`
+	ExpiresOn   *time.Time   `json:"expires_on,omitempty"`
+	// FlaggedAt is when the expiry job first reported the document.
+	FlaggedAt  *time.Time `json:"flagged_at,omitempty"`
+	StorageKey string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// URL is where the file is served.
+	URL string `json:"url,omitempty"`
+}
+
+// Validate checks the type and dates of a new document. Inspections must
+// say when they expire.
+func (d *VehicleDocument) Validate() error {
+	var errs ValidationErrors
+	if !d.Type.Valid() {
+		errs = append(errs, ValidationError{"type", "must be dua, ipo, service_book or other"})
+	}
+	if d.Type == DocumentInspection && d.ExpiresOn == nil {
+		errs = append(errs, ValidationError{"expires_on", "is required for inspections"})
+	}
+	if d.IssuedOn != nil && d.ExpiresOn != nil && !d.ExpiresOn.After(*d.IssuedOn) {
+		errs = append(errs, ValidationError{"expires_on", "must be after issued_on"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Supersedes reports whether d renews other: a later-expiring document of
+// the same vehicle and type, or one expiring as late and uploaded after it.
+// Documents of type other are unrelated and never renew each other.
+func (d *VehicleDocument) Supersedes(other *VehicleDocument) bool {
+	if d.VehicleID != other.VehicleID || d.Type != other.Type || d.Type == DocumentOther {
+		return false
+	}
+	if d.ExpiresOn == nil || other.ExpiresOn == nil {
+		return false
+	}
+	if !d.ExpiresOn.Equal(*other.ExpiresOn) {
+		return d.ExpiresOn.After(*other.ExpiresOn)
+	}
+	return d.ID > other.ID
+}
+
+// ExpiringDocument is a vehicle's current document of its type that expires
+// within the alert window.
+type ExpiringDocument struct {
+	VehicleDocument
+	Vehicle Vehicle `json:"vehicle"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// YearBucketSize is the number of model years grouped in one year facet.
+const YearBucketSize = 5
+
+// PriceBandLimits are the upper bounds of every price band but the last,
+// which is open-ended.
+var PriceBandLimits = []float64{5000, 10000, 20000, 30000, 50000}
+
+// FacetCount is the number of vehicles sharing one facet value.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+	// Min and Max bound range facets and can be passed back as *_min and
+	// *_max filters; Max is inclusive for years and exclusive for prices.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// VehicleFacets counts the vehicles matching a filter by attribute. Each
+// facet ignores the filter on its own attribute so that the other values
+// remain selectable; Total applies every filter.
+type VehicleFacets struct {
+	Total      int          `json:"total"`
+	Types      []FacetCount `json:"types"`
+	Brands     []FacetCount `json:"brands"`
+	Models     []FacetCount `json:"models"`
+	Years      []FacetCount `json:"years"`
+	Statuses   []FacetCount `json:"statuses"`
+	PriceBands []FacetCount `json:"price_bands"`
+}
+
+// YearBucket returns the first year of the bucket containing year.
+func YearBucket(year int) int {
+	return year / YearBucketSize * YearBucketSize
+}
+
+// YearBucketFacet describes the bucket starting at start.
+func YearBucketFacet(start, count int) FacetCount {
+	end := start + YearBucketSize - 1
+	min, max := float64(start), float64(end)
+	return FacetCount{Value: fmt.Sprintf("%d-%d", start, end), Count: count, Min: &min, Max: &max}
+}
+
+// PriceBand returns the index of the band containing price.
+func PriceBand(price float64) int {
+	for i, limit := range PriceBandLimits {
+		if price < limit {
+			return i
+		}
+	}
+	return len(PriceBandLimits)
+}
+
+// PriceBandFacet describes the band with the given index.
+func PriceBandFacet(band, count int) FacetCount {
+	facet := FacetCount{Count: count}
+	min := 0.0
+	if band > 0 {
+		min = PriceBandLimits[band-1]
+	}
+	facet.Min = &min
+
+	if band < len(PriceBandLimits) {
+		max := PriceBandLimits[band]
+		facet.Max = &max
+		facet.Value = fmt.Sprintf("%.0f-%.0f", min, max)
+	} else {
+		facet.Value = fmt.Sprintf("%.0f+", min)
+	}
+	return facet
+}
+
+// SortFacetCounts orders value facets by descending count, then by value.
+func SortFacetCounts(counts []FacetCount) {
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// vinTransliteration maps VIN characters to their ISO 3779 check-digit values.
+// I, O and Q are not allowed in a VIN.
+var vinTransliteration = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+var vinWeights = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// NormalizeVIN upper-cases the VIN and strips surrounding whitespace.
+func NormalizeVIN(vin string) string {
+	return strings.ToUpper(strings.TrimSpace(vin))
+}
+
+// ValidateVIN checks the length and alphabet of a normalised VIN and, for
+// North-American VINs, the check digit (see VINCheckDigitRequired).
+func ValidateVIN(vin string) error {
+	if err := CheckVINFormat(vin); err != nil {
+		return err
+	}
+
+	if VINCheckDigitRequired(vin) && !VINCheckDigitValid(vin) {
+		return fmt.Errorf("invalid check digit %q, expected %q", vin[8], vinCheckDigit(vin))
+	}
+	return nil
+}
+
+// CheckVINFormat checks the length and alphabet of a normalised VIN.
+func CheckVINFormat(vin string) error {
+	if len(vin) != 17 {
+		return errors.New("must have 17 characters")
+	}
+
+	for _, char := range vin {
+		if _, ok := vinCharValue(char); !ok {
+			return fmt.Errorf("invalid character %q", char)
+		}
+	}
+	return nil
+}
+
+// VINCheckDigitRequired reports whether the VIN was assigned in North
+// America (a WMI starting with 1 to 5), where position 9 must hold the check
+// digit. Elsewhere, in Europe in particular, manufacturers mostly put a
+// filler there.
+func VINCheckDigitRequired(vin string) bool {
+	return len(vin) > 0 && vin[0] >= '1' && vin[0] <= '5'
+}
+
+// VINCheckDigitValid reports whether position 9 of a VIN that passed
+// CheckVINFormat holds its ISO 3779 check digit.
+func VINCheckDigitValid(vin string) bool {
+	return vin[8] == vinCheckDigit(vin)
+}
+
+func vinCheckDigit(vin string) byte {
+	sum := 0
+	for i, char := range vin {
+		value, _ := vinCharValue(char)
+		sum += value * vinWeights[i]
+	}
+
+	if sum%11 == 10 {
+		return 'X'
+	}
+	return byte('0' + sum%11)
+}
+
+func vinCharValue(char rune) (int, bool) {
+	if char >= '0' && char <= '9' {
+		return int(char - '0'), true
+	}
+	value, ok := vinTransliteration[char]
+	return value, ok
+}
+
+// Portuguese plates have had four national formats, all six characters in
+// three pairs: AA-00-00 (1937-1992), 00-00-AA (1992-2005), 00-AA-00
+// (2005-2020) and AA-00-AA (since 2020).
+var licensePlateFormats = []*regexp.Regexp{
+	regexp.MustCompile(`^[A-Z]{2}[0-9]{4}$`),
+	regexp.MustCompile(`^[0-9]{4}[A-Z]{2}$`),
+	regexp.MustCompile(`^[0-9]{2}[A-Z]{2}[0-9]{2}$`),
+	regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z]{2}$`),
+}
+
+// NormalizeLicensePlate returns the plate in the canonical "AA-00-00" form,
+// accepting any mix of case, spaces, dots and dashes.
+func NormalizeLicensePlate(plate string) (string, error) {
+	var compact strings.Builder
+	for _, char := range strings.ToUpper(plate) {
+		if (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') {
+			compact.WriteRune(char)
+		} else if char != ' ' && char != '-' && char != '.' {
+			return "", fmt.Errorf("invalid character %q", char)
+		}
+	}
+
+	value := compact.String()
+	for _, format := range licensePlateFormats {
+		if format.MatchString(value) {
+			return value[0:2] + "-" + value[2:4] + "-" + value[4:6], nil
+		}
+	}
+	return "", errors.New("is not a Portuguese licence plate")
+}
+
+type Transmission string
+
+const (
+	TransmissionManual        Transmission = "manual"
+	TransmissionAutomatic     Transmission = "automatic"
+	TransmissionSemiAutomatic Transmission = "semi_automatic"
+)
+
+func (t Transmission) Valid() bool {
+	switch t {
+	case TransmissionManual, TransmissionAutomatic, TransmissionSemiAutomatic:
+		return true
+	}
+	return false
+}
+
+// ValidationError describes an invalid field of a request.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationErrors collects every invalid field of a request.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// DuplicateVehicleError is returned when another vehicle already has the same VIN or plate.
+type DuplicateVehicleError struct {
+	Field string
+}
+
+func (e *DuplicateVehicleError) Error() string {
+	return "another vehicle already has this " + e.Field
+}
+
+// BatchError reports which vehicle of a batch could not be stored.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("vehicle %d of the batch: %v", e.Index+1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// VehicleMedia is a photo of a vehicle. Its original and thumbnails live in
+// the media storage under StorageKey.
+type VehicleMedia struct {
+	ID          int64  `json:"id"`
+	VehicleID   int64  `json:"vehicle_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	// Position orders the media of a vehicle starting at 1; the cover is
+	// the image shown in listings.
+	Position   int       `json:"position"`
+	Cover      bool      `json:"cover"`
+	StorageKey string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	// URLs maps "original" and each thumbnail size to where it is served.
+	URLs map[string]string `json:"urls,omitempty"`
+}
+
+// CheckMediaOrder returns a validation error unless ids lists every one of
+// the current media IDs exactly once.
+func CheckMediaOrder(current []VehicleMedia, ids []int64) error {
+	seen := map[int64]bool{}
+	for _, media := range current {
+		seen[media.ID] = false
+	}
+
+	for _, id := range ids {
+		listed, ok := seen[id]
+		if !ok || listed {
+			return ValidationErrors{{"ids", "must list every media ID of the vehicle exactly once"}}
+		}
+		seen[id] = true
+	}
+	if len(ids) != len(current) {
+		return ValidationErrors{{"ids", "must list every media ID of the vehicle exactly once"}}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrVehicleChanged is returned by a repricing when the vehicle's asking price
+// or status changed since the price change was computed.
+var ErrVehicleChanged = errors.New("vehicle changed since it was read")
+
+// PriceChange is an entry of a vehicle's asking price history.
+type PriceChange struct {
+	ID        int64   `json:"id"`
+	VehicleID int64   `json:"vehicle_id"`
+	OldPrice  float64 `json:"old_price"`
+	NewPrice  float64 `json:"new_price"`
+	// Rule names the price rule that made the change; it is empty for
+	// changes made through the vehicles API.
+	Rule      string    `json:"rule,omitempty"`
+	Reason    string    `json:"reason"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ManualPriceReason is recorded for asking prices changed through the API.
+const ManualPriceReason = "asking price updated"
+
+// PriceRule lowers the asking price of an available vehicle by Percent once
+// it has been in stock for AfterDays.
+type PriceRule struct {
+	AfterDays int
+	Percent   float64
+}
+
+// Name identifies the rule in the price history, e.g. "30d-5%".
+func (r PriceRule) Name() string {
+	return fmt.Sprintf("%dd-%s%%", r.AfterDays, strconv.FormatFloat(r.Percent, 'f', -1, 64))
+}
+
+// PricePolicy is the set of automatic price reductions. No reduction takes
+// the asking price below the vehicle's cost (purchase price plus expenses)
+// marked up by FloorMarkup percent; vehicles without a purchase price are
+// never repriced.
+type PricePolicy struct {
+	Rules       []PriceRule
+	FloorMarkup float64
+}
+
+// Floor is the lowest asking price allowed for a vehicle costing cost,
+// rounded up to whole euros.
+func (p PricePolicy) Floor(cost float64) float64 {
+	return math.Ceil(cost * (1 + p.FloorMarkup/100))
+}
+
+// Due returns the reductions to apply to a vehicle as of now, oldest rule
+// first. Rules already in history since the vehicle entered stock are not
+// applied again, and a rule that would not lower the price is skipped.
+// Prices are rounded down to whole euros.
+func (p PricePolicy) Due(stock *StockVehicle, history []PriceChange, now time.Time) []PriceChange {
+	vehicle := &stock.Vehicle
+	if vehicle.Status != StatusAvailable || vehicle.PurchasePrice <= 0 || vehicle.AskingPrice <= 0 {
+		return nil
+	}
+
+	applied := map[string]bool{}
+	for _, change := range history {
+		if change.Rule != "" && !change.ChangedAt.Before(stock.Since) {
+			applied[change.Rule] = true
+		}
+	}
+
+	rules := append([]PriceRule(nil), p.Rules...)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].AfterDays < rules[j].AfterDays })
+
+	days := stock.DaysInStock(now)
+	floor := p.Floor(stock.Cost())
+	price := vehicle.AskingPrice
+
+	var due []PriceChange
+	for _, rule := range rules {
+		if rule.AfterDays > days || applied[rule.Name()] {
+			continue
+		}
+
+		next := max(math.Floor(price*(1-rule.Percent/100)), floor)
+		if next >= price {
+			continue
+		}
+
+		due = append(due, PriceChange{
+			VehicleID: int64(vehicle.ID),
+			OldPrice:  price,
+			NewPrice:  next,
+			Rule:      rule.Name(),
+			Reason:    fmt.Sprintf("%s%% price reduction after %d days in stock", strconv.FormatFloat(rule.Percent, 'f', -1, 64), rule.AfterDays),
+		})
+		price = next
+	}
+	return due
+}
@@ -0,0 +1,357 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VehicleFilter holds the optional filters of GET /vehicles. Every non-empty
+// field must match; the values of a list field are alternatives. Text is
+// compared case- and accent-insensitively (see Fold) except for the VIN,
+// plate, status, transmission, fuel type and emission standard, which are
+// compared in canonical form.
+type VehicleFilter struct {
+	Types         []string
+	Brands        []string
+	Models        []string
+	Colours       []string
+	Statuses      []VehicleStatus
+	LocationIDs   []int64
+	Transmissions []Transmission
+	// LicenceCategories are compared in upper case.
+	LicenceCategories []string
+	FuelTypes         []FuelType
+	EmissionStandards []string
+	VIN               string
+	LicensePlate      string
+
+	// Inclusive ranges; nil leaves that end open.
+	YearMin, YearMax         *int
+	PriceMin, PriceMax       *float64
+	MileageMin, MileageMax   *int
+	SeatsMin, SeatsMax       *int
+	EngineCCMin, EngineCCMax *int
+	PayloadMin, PayloadMax   *int
+	AxlesMin, AxlesMax       *int
+	PowerKWMin, PowerKWMax   *int
+	PowerHPMin, PowerHPMax   *int
+	CO2Min, CO2Max           *int
+	BatteryMin, BatteryMax   *float64 // kWh
+
+	// UpdatedSince selects the vehicles changed after this time.
+	UpdatedSince *time.Time
+
+	// Query is free text; every word must appear in the brand, model or motor.
+	Query string
+}
+
+// SortKey orders vehicles by one of VehicleSortFields.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// VehicleQuery selects a page of vehicles. Results are always ordered by ID
+// after the sort keys so that pages are stable.
+type VehicleQuery struct {
+	Filter VehicleFilter
+	Sort   []SortKey
+	// Limit of 0 returns every matching vehicle and ignores Offset.
+	Limit  int
+	Offset int
+	// After continues from the last vehicle of a previous page.
+	After *VehicleCursor
+}
+
+// VehiclePage is the response envelope of GET /vehicles.
+type VehiclePage struct {
+	Items []Vehicle `json:"items"`
+	// Total counts every vehicle matching the filter, ignoring pagination.
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// VehicleSortFields maps the sortable column names to the vehicle value they
+// sort by; numbers are returned as float64 and text as string.
+var VehicleSortFields = map[string]func(v *Vehicle) interface{}{
+	"id":           func(v *Vehicle) interface{} { return float64(v.ID) },
+	"type":         func(v *Vehicle) interface{} { return v.Type },
+	"brand":        func(v *Vehicle) interface{} { return v.Brand },
+	"model":        func(v *Vehicle) interface{} { return v.Model },
+	"year":         func(v *Vehicle) interface{} { return float64(v.Year) },
+	"motor":        func(v *Vehicle) interface{} { return v.Motor },
+	"status":       func(v *Vehicle) interface{} { return string(v.Status) },
+	"mileage":      func(v *Vehicle) interface{} { return float64(v.Mileage) },
+	"colour":       func(v *Vehicle) interface{} { return v.Colour },
+	"transmission": func(v *Vehicle) interface{} { return string(v.Transmission) },
+	"doors":        func(v *Vehicle) interface{} { return float64(v.Doors) },
+	"seats":        func(v *Vehicle) interface{} { return float64(v.Seats) },
+	"asking_price": func(v *Vehicle) interface{} { return v.AskingPrice },
+	"engine_cc":    func(v *Vehicle) interface{} { return float64(v.EngineCC) },
+	"payload_kg":   func(v *Vehicle) interface{} { return float64(v.PayloadKg) },
+	"axles":        func(v *Vehicle) interface{} { return float64(v.Axles) },
+	"power_kw":     func(v *Vehicle) interface{} { return float64(v.PowerKW) },
+	"power_hp":     func(v *Vehicle) interface{} { return float64(v.PowerHP) },
+	"co2":          func(v *Vehicle) interface{} { return float64(v.CO2) },
+	"battery_kwh":  func(v *Vehicle) interface{} { return v.BatteryKWh },
+}
+
+// ParseVehicleSort parses a comma-separated list of sort fields, each
+// optionally prefixed with "-" for descending order, e.g. "-year,asking_price".
+func ParseVehicleSort(value string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key := SortKey{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if _, ok := VehicleSortFields[key.Field]; !ok {
+			return nil, fmt.Errorf("cannot sort by %q", key.Field)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// FormatVehicleSort is the inverse of ParseVehicleSort.
+func FormatVehicleSort(keys []SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.Field
+		if key.Desc {
+			parts[i] = "-" + key.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// CompareVehicles orders a before b (negative), after b (positive) or equal
+// (zero) by the sort keys and then by ID.
+func CompareVehicles(a, b *Vehicle, keys []SortKey) int {
+	for _, key := range keys {
+		value := VehicleSortFields[key.Field]
+		if c := compareValues(value(a), value(b)); c != 0 {
+			if key.Desc {
+				return -c
+			}
+			return c
+		}
+	}
+	return a.ID - b.ID
+}
+
+// VehicleCursor identifies the last vehicle of a page by its sort values so
+// the next page can start right after it.
+type VehicleCursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+	ID     int           `json:"id"`
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func NewVehicleCursor(v *Vehicle, keys []SortKey) *VehicleCursor {
+	cursor := &VehicleCursor{Sort: FormatVehicleSort(keys), ID: v.ID}
+	for _, key := range keys {
+		cursor.Values = append(cursor.Values, VehicleSortFields[key.Field](v))
+	}
+	return cursor
+}
+
+// After reports whether v comes after the cursor in the cursor's sort order.
+func (c *VehicleCursor) After(v *Vehicle, keys []SortKey) bool {
+	for i, key := range keys {
+		if cmp := compareValues(VehicleSortFields[key.Field](v), c.Values[i]); cmp != 0 {
+			return (cmp > 0) != key.Desc
+		}
+	}
+	return v.ID > c.ID
+}
+
+// Encode returns the opaque form of the cursor handed out to clients.
+func (c *VehicleCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeVehicleCursor parses an encoded cursor, checking that it was issued
+// for the same sort order.
+func DecodeVehicleCursor(encoded string, keys []SortKey) (*VehicleCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor VehicleCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != FormatVehicleSort(keys) || len(cursor.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	// The values must have the type of their sort field so that they compare
+	// the same way in SQL and in memory.
+	sample := &Vehicle{}
+	for i, key := range keys {
+		switch VehicleSortFields[key.Field](sample).(type) {
+		case string:
+			if _, ok := cursor.Values[i].(string); !ok {
+				return nil, ErrInvalidCursor
+			}
+		case float64:
+			if _, ok := cursor.Values[i].(float64); !ok {
+				return nil, ErrInvalidCursor
+			}
+		}
+	}
+	return &cursor, nil
+}
+
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
+
+// Matches reports whether the vehicle satisfies every filter.
+func (f *VehicleFilter) Matches(v *Vehicle) bool {
+	if !matchesFolded(f.Types, v.Type) || !matchesFolded(f.Brands, v.Brand) ||
+		!matchesFolded(f.Models, v.Model) || !matchesFolded(f.Colours, v.Colour) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !contains(f.Statuses, v.Status) {
+		return false
+	}
+	if len(f.LocationIDs) > 0 && (v.LocationID == nil || !contains(f.LocationIDs, *v.LocationID)) {
+		return false
+	}
+	if len(f.Transmissions) > 0 && !contains(f.Transmissions, v.Transmission) {
+		return false
+	}
+	if len(f.LicenceCategories) > 0 && !contains(f.LicenceCategories, v.LicenceCategory) {
+		return false
+	}
+	if len(f.FuelTypes) > 0 && !contains(f.FuelTypes, v.FuelType) {
+		return false
+	}
+	if len(f.EmissionStandards) > 0 && !contains(f.EmissionStandards, v.EmissionStandard) {
+		return false
+	}
+	if f.VIN != "" && v.VIN != f.VIN {
+		return false
+	}
+	if f.LicensePlate != "" && v.LicensePlate != f.LicensePlate {
+		return false
+	}
+	if f.YearMin != nil && v.Year < *f.YearMin || f.YearMax != nil && v.Year > *f.YearMax {
+		return false
+	}
+	if f.PriceMin != nil && v.AskingPrice < *f.PriceMin || f.PriceMax != nil && v.AskingPrice > *f.PriceMax {
+		return false
+	}
+	if f.MileageMin != nil && v.Mileage < *f.MileageMin || f.MileageMax != nil && v.Mileage > *f.MileageMax {
+		return false
+	}
+	if !inRange(v.Seats, f.SeatsMin, f.SeatsMax) || !inRange(v.EngineCC, f.EngineCCMin, f.EngineCCMax) ||
+		!inRange(v.PayloadKg, f.PayloadMin, f.PayloadMax) || !inRange(v.Axles, f.AxlesMin, f.AxlesMax) {
+		return false
+	}
+	if !inRange(v.PowerKW, f.PowerKWMin, f.PowerKWMax) || !inRange(v.PowerHP, f.PowerHPMin, f.PowerHPMax) ||
+		!inRange(v.CO2, f.CO2Min, f.CO2Max) {
+		return false
+	}
+	if f.BatteryMin != nil && v.BatteryKWh < *f.BatteryMin || f.BatteryMax != nil && v.BatteryKWh > *f.BatteryMax {
+		return false
+	}
+	if f.UpdatedSince != nil && !v.UpdatedAt.After(*f.UpdatedSince) {
+		return false
+	}
+
+	text := Fold(v.Brand + " " + v.Model + " " + v.Motor)
+	for _, word := range f.QueryWords() {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryWords splits the free-text query into folded words.
+func (f *VehicleFilter) QueryWords() []string {
+	return strings.Fields(Fold(f.Query))
+}
+
+func inRange(value int, min, max *int) bool {
+	return (min == nil || value >= *min) && (max == nil || value <= *max)
+}
+
+func matchesFolded(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	folded := Fold(value)
+	for _, candidate := range values {
+		if Fold(candidate) == folded {
+			return true
+		}
+	}
+	return false
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// FoldFrom and FoldTo pair each accented Latin letter with the plain
+// lower-case letter it folds to; SQL backends use them with translate().
+const (
+	FoldFrom = "áàâãäåéèêëíìîïóòôõöúùûüçñýÿÁÀÂÃÄÅÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇÑÝ"
+	FoldTo   = "aaaaaaeeeeiiiiooooouuuucnyyaaaaaaeeeeiiiiooooouuuucny"
+)
+
+var foldMap = func() map[rune]rune {
+	from, to := []rune(FoldFrom), []rune(FoldTo)
+	if len(from) != len(to) {
+		panic("models: FoldFrom and FoldTo differ in length")
+	}
+	folds := make(map[rune]rune, len(from))
+	for i, r := range from {
+		folds[r] = to[i]
+	}
+	return folds
+}()
+
+// Fold lower-cases text and strips accents so that "Citroën" matches "citroen".
+func Fold(text string) string {
+	return strings.Map(func(r rune) rune {
+		if folded, ok := foldMap[r]; ok {
+			return folded
+		}
+		return r
+	}, strings.ToLower(text))
+}
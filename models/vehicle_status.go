@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type VehicleStatus string
+
+const (
+	StatusIncoming      VehicleStatus = "incoming"
+	StatusInPreparation VehicleStatus = "in_preparation"
+	StatusAvailable     VehicleStatus = "available"
+	StatusReserved      VehicleStatus = "reserved"
+	StatusSold          VehicleStatus = "sold"
+	StatusReturned      VehicleStatus = "returned"
+	StatusScrapped      VehicleStatus = "scrapped"
+)
+
+// vehicleTransitions lists, for each status, the statuses a vehicle may move to.
+var vehicleTransitions = map[VehicleStatus][]VehicleStatus{
+	StatusIncoming:      {StatusInPreparation, StatusAvailable, StatusScrapped},
+	StatusInPreparation: {StatusAvailable, StatusScrapped},
+	StatusAvailable:     {StatusInPreparation, StatusReserved, StatusSold, StatusScrapped},
+	StatusReserved:      {StatusAvailable, StatusSold},
+	StatusSold:          {StatusReturned},
+	StatusReturned:      {StatusInPreparation, StatusAvailable, StatusScrapped},
+	StatusScrapped:      {},
+}
+
+func (s VehicleStatus) Valid() bool {
+	_, ok := vehicleTransitions[s]
+	return ok
+}
+
+// initialStatuses are the statuses a vehicle may be created in. It only
+// becomes reserved or sold through a reservation or sale.
+var initialStatuses = []VehicleStatus{StatusIncoming, StatusInPreparation, StatusAvailable}
+
+// Initial reports whether a vehicle may be created in the status.
+func (s VehicleStatus) Initial() bool {
+	return contains(initialStatuses, s)
+}
+
+func (s VehicleStatus) CanTransitionTo(to VehicleStatus) bool {
+	for _, allowed := range vehicleTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransition returns an error if a vehicle cannot move from one status to another.
+func CheckTransition(from, to VehicleStatus) error {
+	if !to.Valid() {
+		return &InvalidStatusError{Status: to}
+	}
+	if !from.CanTransitionTo(to) {
+		return &InvalidTransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// VehicleStatusChange is an entry of a vehicle's status history
+type VehicleStatusChange struct {
+	ID        int64          `json:"id"`
+	VehicleID int64          `json:"vehicle_id"`
+	From      *VehicleStatus `json:"from"`
+	To        VehicleStatus  `json:"to" binding:"required"`
+	Reason    string         `json:"reason" binding:"required"`
+	Actor     string         `json:"actor" binding:"required"`
+	ChangedAt time.Time      `json:"changed_at"`
+}
+
+// SystemActor is recorded as the actor of status changes made by the API itself.
+const SystemActor = "system"
+
+type InvalidStatusError struct {
+	Status VehicleStatus
+}
+
+func (e *InvalidStatusError) Error() string {
+	return fmt.Sprintf("invalid vehicle status %q", e.Status)
+}
+
+type InvalidTransitionError struct {
+	From VehicleStatus
+	To   VehicleStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("vehicle cannot move from %q to %q", e.From, e.To)
+}
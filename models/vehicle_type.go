@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The vehicle types. Type is stored with these spellings; Normalize maps
+// the aliases of VehicleTypes onto them.
+const (
+	TypeCar        = "Car"
+	TypeMotorcycle = "Motorcycle"
+	TypeVan        = "Van"
+	TypeTruck      = "Truck"
+)
+
+// AttributeSpec describes a type-specific field of Vehicle. Numbers must be
+// within Min and Max and text one of Values; zero or empty means not given.
+type AttributeSpec struct {
+	Field  string   `json:"field"`
+	Min    int      `json:"min,omitempty"`
+	Max    int      `json:"max,omitempty"`
+	Unit   string   `json:"unit,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// VehicleTypeSpec is a vehicle type with the attributes that apply to it.
+type VehicleTypeSpec struct {
+	Name       string          `json:"name"`
+	Aliases    []string        `json:"aliases"`
+	Attributes []AttributeSpec `json:"attributes"`
+}
+
+// LicenceCategories are the driving licence categories for motorcycles.
+var LicenceCategories = []string{"AM", "A1", "A2", "A"}
+
+// VehicleTypes is the attribute schema of each vehicle type, served by
+// GET /vehicle-types.
+var VehicleTypes = []VehicleTypeSpec{
+	{
+		Name:    TypeCar,
+		Aliases: []string{"carro", "automóvel", "ligeiro"},
+		Attributes: []AttributeSpec{
+			{Field: "Doors", Min: 1, Max: 9},
+			{Field: "Seats", Min: 1, Max: 9},
+			{Field: "EngineCC", Min: 49, Max: 8500, Unit: "cc"},
+		},
+	},
+	{
+		Name:    TypeMotorcycle,
+		Aliases: []string{"mota", "moto", "motociclo", "motorbike"},
+		Attributes: []AttributeSpec{
+			{Field: "EngineCC", Min: 49, Max: 2500, Unit: "cc"},
+			{Field: "LicenceCategory", Values: LicenceCategories},
+		},
+	},
+	{
+		Name:    TypeVan,
+		Aliases: []string{"carrinha", "furgão", "comercial"},
+		Attributes: []AttributeSpec{
+			{Field: "Doors", Min: 1, Max: 9},
+			{Field: "Seats", Min: 1, Max: 9},
+			{Field: "EngineCC", Min: 49, Max: 8500, Unit: "cc"},
+			{Field: "PayloadKg", Min: 1, Max: 5000, Unit: "kg"},
+			{Field: "Axles", Min: 2, Max: 3},
+		},
+	},
+	{
+		Name:    TypeTruck,
+		Aliases: []string{"camião", "pesado", "lorry"},
+		Attributes: []AttributeSpec{
+			{Field: "Seats", Min: 1, Max: 9},
+			{Field: "EngineCC", Min: 49, Max: 20000, Unit: "cc"},
+			{Field: "PayloadKg", Min: 1, Max: 60000, Unit: "kg"},
+			{Field: "Axles", Min: 2, Max: 10},
+		},
+	},
+}
+
+// attributeValues reads each type-specific field of a vehicle.
+var attributeValues = map[string]func(v *Vehicle) interface{}{
+	"Doors":           func(v *Vehicle) interface{} { return v.Doors },
+	"Seats":           func(v *Vehicle) interface{} { return v.Seats },
+	"EngineCC":        func(v *Vehicle) interface{} { return v.EngineCC },
+	"LicenceCategory": func(v *Vehicle) interface{} { return v.LicenceCategory },
+	"PayloadKg":       func(v *Vehicle) interface{} { return v.PayloadKg },
+	"Axles":           func(v *Vehicle) interface{} { return v.Axles },
+}
+
+// attributeOrder lists attributeValues in a fixed order for error reporting.
+var attributeOrder = []string{"Doors", "Seats", "EngineCC", "LicenceCategory", "PayloadKg", "Axles"}
+
+// LookupVehicleType finds the type whose name or alias matches name, ignoring
+// case and accents.
+func LookupVehicleType(name string) (*VehicleTypeSpec, bool) {
+	folded := Fold(strings.TrimSpace(name))
+	for i := range VehicleTypes {
+		spec := &VehicleTypes[i]
+		if Fold(spec.Name) == folded {
+			return spec, true
+		}
+		for _, alias := range spec.Aliases {
+			if Fold(alias) == folded {
+				return spec, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// VehicleTypeNames lists the names of VehicleTypes.
+func VehicleTypeNames() []string {
+	names := make([]string, len(VehicleTypes))
+	for i, spec := range VehicleTypes {
+		names[i] = spec.Name
+	}
+	return names
+}
+
+func (s *VehicleTypeSpec) attribute(field string) (*AttributeSpec, bool) {
+	for i := range s.Attributes {
+		if s.Attributes[i].Field == field {
+			return &s.Attributes[i], true
+		}
+	}
+	return nil, false
+}
+
+// validateAttributes checks the type-specific fields against the schema of
+// the vehicle type: those of other types must be left empty.
+func (v *Vehicle) validateAttributes() ValidationErrors {
+	spec, ok := LookupVehicleType(v.Type)
+	if !ok {
+		return ValidationErrors{{"Type", "must be one of " + strings.Join(VehicleTypeNames(), ", ")}}
+	}
+
+	var errs ValidationErrors
+	for _, field := range attributeOrder {
+		value := attributeValues[field](v)
+		attribute, applies := spec.attribute(field)
+		if !applies {
+			if value != 0 && value != "" {
+				errs = append(errs, ValidationError{field, "does not apply to type " + spec.Name})
+			}
+			continue
+		}
+
+		switch value := value.(type) {
+		case int:
+			if value != 0 && (value < attribute.Min || value > attribute.Max) {
+				errs = append(errs, ValidationError{field, fmt.Sprintf("must be between %d and %d", attribute.Min, attribute.Max)})
+			}
+		case string:
+			if value != "" && !contains(attribute.Values, value) {
+				errs = append(errs, ValidationError{field, "must be one of " + strings.Join(attribute.Values, ", ")})
+			}
+		}
+	}
+	return errs
+}
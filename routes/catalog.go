@@ -0,0 +1,148 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) getBrands(context *gin.Context) {
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	catalog, err := h.Repos.Catalog.Catalog(ctx)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the catalogue."})
+		return
+	}
+
+	context.JSON(http.StatusOK, catalog.Brands())
+}
+
+func (h *handler) getBrandModels(context *gin.Context) {
+	brandID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse brand id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	catalog, err := h.Repos.Catalog.Catalog(ctx)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the catalogue."})
+		return
+	}
+
+	if _, ok := catalog.BrandByID(brandID); !ok {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Brand not found."})
+		return
+	}
+
+	context.JSON(http.StatusOK, catalog.Models(brandID))
+}
+
+func (h *handler) createBrand(context *gin.Context) {
+	var brand models.Brand
+	if err := context.ShouldBindJSON(&brand); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	if err := brand.Validate(); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid brand data.", "errors": err})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err := h.Repos.Catalog.CreateBrand(ctx, &brand)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if respondCatalogConflict(context, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create the brand."})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Brand created!", "brand": brand})
+}
+
+func (h *handler) createBrandModel(context *gin.Context) {
+	brandID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse brand id."})
+		return
+	}
+
+	var model models.BrandModel
+	if err := context.ShouldBindJSON(&model); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+	model.BrandID = brandID
+
+	if err := model.Validate(); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid model data.", "errors": err})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Catalog.CreateModel(ctx, &model)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Brand not found."})
+		return
+	}
+
+	if respondCatalogConflict(context, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create the model."})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Model created!", "model": model})
+}
+
+func respondCatalogConflict(context *gin.Context, err error) bool {
+	var conflict *models.CatalogConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+
+	context.JSON(http.StatusConflict, gin.H{
+		"message":  "\"" + conflict.Name + "\" already refers to " + conflict.Existing + ".",
+		"existing": conflict.Existing,
+	})
+	return true
+}
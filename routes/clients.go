@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -9,8 +10,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func getClients(context *gin.Context) {
-	clients, err := models.GetAllClients()
+func (h *handler) getClients(context *gin.Context) {
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	clients, err := h.Repos.Clients.GetAll(ctx)
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch clients. Try again later."})
 		return
@@ -18,14 +26,26 @@ func getClients(context *gin.Context) {
 	context.JSON(http.StatusOK, clients)
 }
 
-func getClient(context *gin.Context) {
+func (h *handler) getClient(context *gin.Context) {
 	clientId, err := strconv.ParseInt(context.Param("id"), 10, 64)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse client id."})
 		return
 	}
 
-	client, err := models.GetClientByID(clientId)
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	client, err := h.Repos.Clients.GetByID(ctx, clientId)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Client not found."})
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch client."})
@@ -35,7 +55,7 @@ func getClient(context *gin.Context) {
 	context.JSON(http.StatusOK, client)
 }
 
-func createClient(context *gin.Context) {
+func (h *handler) createClient(context *gin.Context) {
 	log.Println("Starting createClient handler")
 
 	var client models.Client
@@ -49,7 +69,14 @@ func createClient(context *gin.Context) {
 
 	log.Printf("Parsed client data: %+v", client)
 
-	err = client.Save()
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Clients.Create(ctx, &client)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
 
 	if err != nil {
 		log.Printf("Database save error: %v", err)
@@ -61,18 +88,13 @@ func createClient(context *gin.Context) {
 	context.JSON(http.StatusCreated, gin.H{"message": "Client created!", "client": client})
 }
 
-func updateClient(context *gin.Context) {
+func (h *handler) updateClient(context *gin.Context) {
 	clientId, err := strconv.ParseInt(context.Param("id"), 10, 64)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse client id."})
 		return
 	}
 
-	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the client."})
-		return
-	}
-
 	var updatedClient models.Client
 	err = context.ShouldBindJSON(&updatedClient)
 
@@ -82,7 +104,20 @@ func updateClient(context *gin.Context) {
 	}
 
 	updatedClient.ID = clientId
-	err = updatedClient.Update()
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Clients.Update(ctx, &updatedClient)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Client not found."})
+		return
+	}
+
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update client."})
 		return
@@ -90,21 +125,31 @@ func updateClient(context *gin.Context) {
 	context.JSON(http.StatusOK, gin.H{"message": "Client updated successfully!"})
 }
 
-func deleteClient(context *gin.Context) {
+func (h *handler) deleteClient(context *gin.Context) {
 	clientId, err := strconv.ParseInt(context.Param("id"), 10, 64)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse client id."})
 		return
 	}
 
-	client, err := models.GetClientByID(clientId)
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
 
-	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the client."})
+	err = h.Repos.Clients.Delete(ctx, clientId)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Client not found."})
 		return
 	}
 
-	err = client.Delete()
+	if errors.Is(err, models.ErrInUse) {
+		context.JSON(http.StatusConflict, gin.H{"message": "Client has sales, reservations, consignments, test drives or traded-in vehicles and cannot be deleted."})
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the client."})
@@ -0,0 +1,178 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) getConsignments(context *gin.Context) {
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	consignments, err := h.Repos.Consignments.GetAll(ctx)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch consignments. Try again later."})
+		return
+	}
+	context.JSON(http.StatusOK, consignments)
+}
+
+func (h *handler) getConsignment(context *gin.Context) {
+	consignmentID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse consignment id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	consignment, err := h.Repos.Consignments.GetByID(ctx, consignmentID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Consignment not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch consignment."})
+		return
+	}
+
+	context.JSON(http.StatusOK, consignment)
+}
+
+func (h *handler) createConsignment(context *gin.Context) {
+	var consignment models.Consignment
+	if err := context.ShouldBindJSON(&consignment); err != nil {
+		log.Printf("JSON binding error: %v", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	if err := consignment.Validate(); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid consignment data.", "errors": err})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err := h.Repos.Consignments.Create(ctx, &consignment)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var soldErr *models.VehicleAlreadySoldError
+	var consignedErr *models.VehicleConsignedError
+	var purchasedErr *models.ConsignedPurchasePriceError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Owner or vehicle not found."})
+		return
+	case errors.As(err, &soldErr):
+		context.JSON(http.StatusConflict, gin.H{"message": "Vehicle is already sold", "vehicle_id": soldErr.VehicleID})
+		return
+	case errors.As(err, &consignedErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":        "Vehicle is already consigned",
+			"vehicle_id":     consignedErr.VehicleID,
+			"consignment_id": consignedErr.ConsignmentID,
+		})
+		return
+	case errors.As(err, &purchasedErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":    "Vehicle was bought by the stand and cannot be consigned; clear its purchase price first",
+			"vehicle_id": purchasedErr.VehicleID,
+		})
+		return
+	case err != nil:
+		log.Printf("Database save error: %v", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create consignment. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Consignment created!", "consignment": consignment})
+}
+
+// withdrawConsignment ends a consignment when the owner takes the vehicle back.
+func (h *handler) withdrawConsignment(context *gin.Context) {
+	consignmentID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse consignment id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Consignments.Withdraw(ctx, consignmentID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var notActiveErr *models.ConsignmentNotActiveError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Consignment not found."})
+		return
+	case errors.As(err, &notActiveErr):
+		context.JSON(http.StatusConflict, gin.H{"message": "Consignment is no longer active", "status": notActiveErr.Status})
+		return
+	case err != nil:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not withdraw the consignment."})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Consignment withdrawn!"})
+}
+
+// getConsignmentSettlement serves the statement of what is owed to the owner
+// of a sold consignment.
+func (h *handler) getConsignmentSettlement(context *gin.Context) {
+	consignmentID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse consignment id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	settlement, err := h.Repos.Consignments.Settlement(ctx, consignmentID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var notActiveErr *models.ConsignmentNotActiveError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Consignment not found."})
+		return
+	case errors.As(err, &notActiveErr):
+		context.JSON(http.StatusConflict, gin.H{"message": "Consignment has not been sold", "status": notActiveErr.Status})
+		return
+	case err != nil:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the settlement."})
+		return
+	}
+
+	context.JSON(http.StatusOK, settlement)
+}
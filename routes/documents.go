@@ -0,0 +1,313 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Stand/media"
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+// documentContentTypes are the accepted scan formats, as sniffed from the
+// file contents.
+var documentContentTypes = []string{"application/pdf", "image/jpeg", "image/png"}
+
+// setDocumentURLs fills in where each document is served.
+func setDocumentURLs(documents []models.VehicleDocument) {
+	for i := range documents {
+		documents[i].URL = media.DocumentURL(documents[i].VehicleID, documents[i].ID)
+	}
+}
+
+func (h *handler) getVehicleDocuments(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	documents, err := h.Repos.Documents.ListByVehicle(ctx, vehicleID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the vehicle documents."})
+		return
+	}
+
+	setDocumentURLs(documents)
+	context.JSON(http.StatusOK, documents)
+}
+
+// formDate reads an optional YYYY-MM-DD form field as midnight UTC.
+func formDate(context *gin.Context, key string) (*time.Time, error) {
+	raw := strings.TrimSpace(context.PostForm(key))
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, errors.New(key + " must be a YYYY-MM-DD date.")
+	}
+	return &value, nil
+}
+
+// uploadVehicleDocument accepts a PDF, JPEG or PNG scan in the "file" field
+// of a multipart form, with its "type" and optional "issued_on" and
+// "expires_on" dates.
+func (h *handler) uploadVehicleDocument(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, h.MaxUploadSize)
+
+	header, err := context.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("Uploads are limited to %d bytes.", h.MaxUploadSize)})
+			return
+		}
+		if errors.Is(err, http.ErrMissingFile) {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "No file uploaded; send the document in the \"file\" field."})
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the multipart form."})
+		return
+	}
+
+	document := models.VehicleDocument{
+		VehicleID:  vehicleID,
+		Type:       models.DocumentType(strings.TrimSpace(context.PostForm("type"))),
+		FileName:   header.Filename,
+		StorageKey: media.NewDocumentKey(vehicleID),
+	}
+	if document.IssuedOn, err = formDate(context, "issued_on"); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if document.ExpiresOn, err = formDate(context, "expires_on"); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if err := document.Validate(); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid document data.", "errors": err})
+		return
+	}
+
+	data, err := readFormFile(header)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not read " + header.Filename + "."})
+		return
+	}
+	document.Size = int64(len(data))
+	document.ContentType = http.DetectContentType(data)
+	if !slices.Contains(documentContentTypes, document.ContentType) {
+		context.JSON(http.StatusBadRequest, gin.H{"message": header.Filename + " is not a PDF, JPEG or PNG file."})
+		return
+	}
+
+	err = h.storeDocument(context, &document, data)
+
+	if abortOnContextError(context, context.Request.Context(), err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	if err != nil {
+		slog.Error("Document upload error", "err", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not store " + header.Filename + ". Try again later."})
+		return
+	}
+
+	document.URL = media.DocumentURL(document.VehicleID, document.ID)
+	context.JSON(http.StatusCreated, gin.H{"message": "Document uploaded!", "document": document})
+}
+
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// storeDocument writes the file to the media storage and then records the
+// document, removing the stored file if that fails.
+func (h *handler) storeDocument(context *gin.Context, document *models.VehicleDocument, data []byte) error {
+	err := h.MediaStorage.Put(context.Request.Context(), document.StorageKey, bytes.NewReader(data), document.Size, document.ContentType)
+
+	if err == nil {
+		ctx, cancel := h.writeContext(context)
+		defer cancel()
+		err = h.Repos.Documents.Create(ctx, document)
+	}
+
+	if err != nil {
+		h.deleteDocumentFile(document.StorageKey)
+	}
+	return err
+}
+
+// deleteDocumentFile removes a stored document in the background of a
+// request that already succeeded or failed, so errors are only logged.
+func (h *handler) deleteDocumentFile(key string) {
+	if err := h.MediaStorage.Delete(context.Background(), key); err != nil {
+		slog.Warn("Could not delete document file", "key", key, "err", err)
+	}
+}
+
+func (h *handler) getVehicleDocumentFile(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	documentID, err := strconv.ParseInt(context.Param("documentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse document id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	document, err := h.Repos.Documents.GetByID(ctx, vehicleID, documentID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Document not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the document."})
+		return
+	}
+
+	file, err := h.MediaStorage.Get(context.Request.Context(), document.StorageKey)
+	if errors.Is(err, media.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Document file not found."})
+		return
+	}
+	if err != nil {
+		slog.Error("Media storage error", "err", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not read the document file."})
+		return
+	}
+	defer file.Close()
+
+	// Documents hold personal data, so only the client may cache them.
+	context.DataFromReader(http.StatusOK, document.Size, document.ContentType, file, map[string]string{
+		"Cache-Control":       "private, max-age=3600",
+		"Content-Disposition": mime.FormatMediaType("inline", map[string]string{"filename": document.FileName}),
+	})
+}
+
+func (h *handler) deleteVehicleDocument(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	documentID, err := strconv.ParseInt(context.Param("documentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse document id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	document, err := h.Repos.Documents.GetByID(ctx, vehicleID, documentID)
+	if err == nil {
+		err = h.Repos.Documents.Delete(ctx, vehicleID, documentID)
+	}
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Document not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the document."})
+		return
+	}
+
+	h.deleteDocumentFile(document.StorageKey)
+	context.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully!"})
+}
+
+// getExpiringDocuments lists the current documents of vehicles in stock
+// that expired or expire within within_days, by default the configured
+// window.
+func (h *handler) getExpiringDocuments(context *gin.Context) {
+	window := h.DocumentExpiryWindow
+	days, err := queryInt(context, "within_days")
+	if err != nil || (days != nil && *days < 0) {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "within_days must be a non-negative number of days."})
+		return
+	}
+	if days != nil {
+		window = time.Duration(*days) * 24 * time.Hour
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	documents, err := h.Repos.Documents.Expiring(ctx, time.Now().Add(window))
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch expiring documents. Try again later."})
+		return
+	}
+
+	for i := range documents {
+		documents[i].URL = media.DocumentURL(documents[i].VehicleID, documents[i].ID)
+	}
+	context.JSON(http.StatusOK, documents)
+}
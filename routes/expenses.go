@@ -0,0 +1,150 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) getVehicleExpenses(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	expenses, err := h.Repos.Expenses.ListByVehicle(ctx, vehicleID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the vehicle expenses."})
+		return
+	}
+
+	context.JSON(http.StatusOK, expenses)
+}
+
+func (h *handler) createVehicleExpense(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	var expense models.VehicleExpense
+	err = context.ShouldBindJSON(&expense)
+
+	if err != nil {
+		log.Printf("JSON binding error: %v", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	if err := expense.Validate(); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid expense data.", "errors": err})
+		return
+	}
+
+	expense.VehicleID = vehicleID
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Expenses.Create(ctx, &expense)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	if err != nil {
+		log.Printf("Database save error: %v", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not record the expense. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Expense recorded!", "expense": expense})
+}
+
+func (h *handler) deleteVehicleExpense(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	expenseID, err := strconv.ParseInt(context.Param("expenseId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse expense id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Expenses.Delete(ctx, vehicleID, expenseID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Expense not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the expense."})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Expense deleted successfully!"})
+}
+
+func (h *handler) getVehicleProfitability(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	profitability, err := h.Repos.Vehicles.Profitability(ctx, vehicleID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not compute the vehicle profitability."})
+		return
+	}
+
+	context.JSON(http.StatusOK, profitability)
+}
@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Stand/feed"
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getFeed serves the feed in the format whose file name is requested. The
+// since parameter, an RFC 3339 time or "last" for the last scheduled
+// export, selects the incremental feed.
+func (h *handler) getFeed(context *gin.Context) {
+	var format feed.Format
+	for _, candidate := range feed.Formats() {
+		if feed.FileName(candidate, false) == context.Param("file") {
+			format = candidate
+		}
+	}
+	if format == nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Feed not found."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	var since *time.Time
+	switch value := context.Query("since"); value {
+	case "":
+	case "last":
+		last, err := h.Repos.Feeds.Last(ctx)
+		if abortOnContextError(context, ctx, err) {
+			return
+		}
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the last feed export."})
+			return
+		}
+		// Without a previous export the whole feed is new.
+		if last != nil {
+			since = &last.ExportedAt
+		}
+	default:
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "since must be an RFC 3339 time or \"last\"."})
+			return
+		}
+		since = &parsed
+	}
+
+	builder := feed.Builder{Vehicles: h.Repos.Vehicles, Media: h.Repos.Media, BaseURL: h.FeedBaseURL}
+	if builder.BaseURL == "" {
+		scheme := "http"
+		if context.Request.TLS != nil {
+			scheme = "https"
+		}
+		builder.BaseURL = scheme + "://" + context.Request.Host
+	}
+
+	result, err := builder.Build(ctx, since)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not build the feed."})
+		return
+	}
+
+	var body bytes.Buffer
+	if err := format.Encode(&body, result); err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not encode the feed."})
+		return
+	}
+	context.Data(http.StatusOK, format.ContentType(), body.Bytes())
+}
@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/Stand/importer"
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+// importVehicles creates vehicles from a CSV or XLSX file sent in the "file"
+// field of a multipart form. With ?dry_run=true it only validates the rows;
+// ?map=HEADER:FIELD,... maps columns whose header is not recognised.
+func (h *handler) importVehicles(context *gin.Context) {
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, h.MaxUploadSize)
+
+	header, err := context.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("Uploads are limited to %d bytes.", h.MaxUploadSize)})
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"message": "No file uploaded; send the CSV or XLSX file in the \"file\" field."})
+		return
+	}
+
+	format, err := importer.FormatOf(header.Filename)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not import " + header.Filename + ": " + err.Error() + "."})
+		return
+	}
+
+	mapping, err := importer.ParseMapping(context.Query("map"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid map parameter: " + err.Error() + "."})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not read " + header.Filename + "."})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not read " + header.Filename + "."})
+		return
+	}
+
+	table, err := importer.ReadTable(data, format)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not import " + header.Filename + ": " + err.Error() + "."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	options := importer.Options{Mapping: mapping, DryRun: context.Query("dry_run") == "true"}
+	report, err := importer.Import(ctx, h.Repos, table, options)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var duplicateErr *models.DuplicateVehicleError
+	var invalidErr models.ValidationErrors
+	switch {
+	case errors.Is(err, importer.ErrMapping):
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error() + "."})
+	case errors.As(err, &duplicateErr), errors.As(err, &invalidErr):
+		context.JSON(http.StatusConflict, gin.H{"message": "Nothing was imported: " + err.Error() + "."})
+	case err != nil:
+		slog.Error("Vehicle import error", "err", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not import vehicles. Try again later."})
+	case options.DryRun:
+		context.JSON(http.StatusOK, gin.H{"message": "Dry run finished; no vehicles were created.", "report": report})
+	case report.Imported == 0:
+		context.JSON(http.StatusBadRequest, gin.H{"message": "The file has no valid rows to import.", "report": report})
+	default:
+		context.JSON(http.StatusCreated, gin.H{"message": fmt.Sprintf("Imported %d of %d rows!", report.Imported, report.Rows), "report": report})
+	}
+}
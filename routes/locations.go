@@ -0,0 +1,200 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) getLocations(context *gin.Context) {
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	locations, err := h.Repos.Locations.GetAll(ctx)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch locations. Try again later."})
+		return
+	}
+	context.JSON(http.StatusOK, locations)
+}
+
+func (h *handler) getLocation(context *gin.Context) {
+	locationID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse location id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	location, err := h.Repos.Locations.GetByID(ctx, locationID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Location not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch location."})
+		return
+	}
+
+	context.JSON(http.StatusOK, location)
+}
+
+// getLocationStock serves the number of vehicles in stock at each location,
+// by status, with the vehicles in transit to and from it.
+func (h *handler) getLocationStock(context *gin.Context) {
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	stock, err := h.Repos.Locations.Stock(ctx)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not count the stock. Try again later."})
+		return
+	}
+	context.JSON(http.StatusOK, stock)
+}
+
+func (h *handler) createLocation(context *gin.Context) {
+	var location models.Location
+	if err := context.ShouldBindJSON(&location); err != nil {
+		log.Printf("JSON binding error: %v", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	if err := location.Validate(); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid location data.", "errors": err})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err := h.Repos.Locations.Create(ctx, &location)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if respondDuplicateLocation(context, err) {
+		return
+	}
+
+	if err != nil {
+		log.Printf("Database save error: %v", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create location. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Location created!", "location": location})
+}
+
+func (h *handler) updateLocation(context *gin.Context) {
+	locationID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse location id."})
+		return
+	}
+
+	var location models.Location
+	if err := context.ShouldBindJSON(&location); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	if err := location.Validate(); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid location data.", "errors": err})
+		return
+	}
+
+	location.ID = locationID
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Locations.Update(ctx, &location)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Location not found."})
+		return
+	}
+
+	if respondDuplicateLocation(context, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update location."})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Location updated successfully!"})
+}
+
+func (h *handler) deleteLocation(context *gin.Context) {
+	locationID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse location id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Locations.Delete(ctx, locationID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Location not found."})
+		return
+	}
+
+	if errors.Is(err, models.ErrInUse) {
+		context.JSON(http.StatusConflict, gin.H{"message": "Location has vehicles, transfers or test drives and cannot be deleted."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the location."})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Location deleted successfully!"})
+}
+
+// respondDuplicateLocation answers 409 when the location name is taken.
+func respondDuplicateLocation(context *gin.Context, err error) bool {
+	var duplicateErr *models.DuplicateLocationError
+	if !errors.As(err, &duplicateErr) {
+		return false
+	}
+
+	context.JSON(http.StatusConflict, gin.H{"message": "Another location is already named " + duplicateErr.Name + "."})
+	return true
+}
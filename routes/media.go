@@ -0,0 +1,371 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/Stand/media"
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+// setMediaURLs fills in where the original and thumbnails of each item are served.
+func setMediaURLs(gallery []models.VehicleMedia) {
+	for i := range gallery {
+		gallery[i].URLs = map[string]string{}
+		for _, rendition := range media.Renditions() {
+			gallery[i].URLs[rendition] = media.URL(gallery[i].VehicleID, gallery[i].ID, rendition)
+		}
+	}
+}
+
+func (h *handler) getVehicleMedia(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	gallery, err := h.Repos.Media.ListByVehicle(ctx, vehicleID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the vehicle media."})
+		return
+	}
+
+	setMediaURLs(gallery)
+	context.JSON(http.StatusOK, gallery)
+}
+
+// upload is an image from a multipart request, decoded and ready to store.
+type upload struct {
+	header *multipart.FileHeader
+	data   []byte
+	image  *media.Image
+}
+
+func readUpload(header *multipart.FileHeader) (*upload, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := media.Process(data)
+	if err != nil {
+		return nil, err
+	}
+	return &upload{header: header, data: data, image: image}, nil
+}
+
+// uploadVehicleMedia accepts one or more images in the "file" fields of a
+// multipart form and adds them to the end of the vehicle's gallery.
+func (h *handler) uploadVehicleMedia(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, h.MaxUploadSize)
+
+	form, err := context.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("Uploads are limited to %d bytes.", h.MaxUploadSize)})
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the multipart form."})
+		return
+	}
+
+	headers := form.File["file"]
+	if len(headers) == 0 {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "No file uploaded; send the images in \"file\" fields."})
+		return
+	}
+
+	// Every file is decoded before anything is stored, so one bad file
+	// rejects the whole upload.
+	var uploads []*upload
+	for _, header := range headers {
+		upload, err := readUpload(header)
+		if errors.Is(err, media.ErrUnsupportedImage) {
+			context.JSON(http.StatusBadRequest, gin.H{"message": header.Filename + " is not a JPEG, PNG, GIF or WebP image."})
+			return
+		}
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Could not read " + header.Filename + "."})
+			return
+		}
+		uploads = append(uploads, upload)
+	}
+
+	gallery := []models.VehicleMedia{}
+	for _, upload := range uploads {
+		item, err := h.storeUpload(context, vehicleID, upload)
+
+		if abortOnContextError(context, context.Request.Context(), err) {
+			return
+		}
+
+		if errors.Is(err, models.ErrNotFound) {
+			context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+			return
+		}
+
+		if err != nil {
+			slog.Error("Media upload error", "err", err)
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not store " + upload.header.Filename + ". Try again later."})
+			return
+		}
+		gallery = append(gallery, *item)
+	}
+
+	setMediaURLs(gallery)
+	context.JSON(http.StatusCreated, gin.H{"message": "Media uploaded!", "media": gallery})
+}
+
+// storeUpload writes the original and thumbnails to the media storage and
+// then records the item, removing the stored files if that fails.
+func (h *handler) storeUpload(context *gin.Context, vehicleID int64, upload *upload) (*models.VehicleMedia, error) {
+	requestCtx := context.Request.Context()
+	item := &models.VehicleMedia{
+		VehicleID:   vehicleID,
+		FileName:    upload.header.Filename,
+		ContentType: upload.image.ContentType,
+		Size:        int64(len(upload.data)),
+		Width:       upload.image.Width,
+		Height:      upload.image.Height,
+		StorageKey:  media.NewPrefix(vehicleID),
+	}
+
+	err := h.MediaStorage.Put(requestCtx, media.Key(item.StorageKey, media.Original), bytes.NewReader(upload.data), item.Size, item.ContentType)
+	for name, thumbnail := range upload.image.Thumbnails {
+		if err != nil {
+			break
+		}
+		err = h.MediaStorage.Put(requestCtx, media.Key(item.StorageKey, name), bytes.NewReader(thumbnail), int64(len(thumbnail)), media.ThumbnailContentType)
+	}
+
+	if err == nil {
+		ctx, cancel := h.writeContext(context)
+		defer cancel()
+		err = h.Repos.Media.Create(ctx, item)
+	}
+
+	if err != nil {
+		h.deleteMediaFiles(item.StorageKey)
+		return nil, err
+	}
+	return item, nil
+}
+
+// deleteMediaFiles removes stored files in the background of a request that
+// already succeeded or failed, so errors are only logged.
+func (h *handler) deleteMediaFiles(prefixes ...string) {
+	for _, prefix := range prefixes {
+		if err := media.DeleteAll(context.Background(), h.MediaStorage, prefix); err != nil {
+			slog.Warn("Could not delete media files", "prefix", prefix, "err", err)
+		}
+	}
+}
+
+func (h *handler) getVehicleMediaFile(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	mediaID, err := strconv.ParseInt(context.Param("mediaId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse media id."})
+		return
+	}
+
+	rendition := context.Param("rendition")
+	if !media.ValidRendition(rendition) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Unknown media size."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	item, err := h.Repos.Media.GetByID(ctx, vehicleID, mediaID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Media not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the media."})
+		return
+	}
+
+	file, err := h.MediaStorage.Get(context.Request.Context(), media.Key(item.StorageKey, rendition))
+	if errors.Is(err, media.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Media file not found."})
+		return
+	}
+	if err != nil {
+		slog.Error("Media storage error", "err", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not read the media file."})
+		return
+	}
+	defer file.Close()
+
+	contentType := media.ThumbnailContentType
+	if rendition == media.Original {
+		contentType = item.ContentType
+	}
+
+	// Stored files never change: a replaced photo gets a new media id.
+	context.DataFromReader(http.StatusOK, -1, contentType, file, map[string]string{
+		"Cache-Control": "public, max-age=31536000, immutable",
+	})
+}
+
+func (h *handler) deleteVehicleMedia(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	mediaID, err := strconv.ParseInt(context.Param("mediaId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse media id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	item, err := h.Repos.Media.GetByID(ctx, vehicleID, mediaID)
+	if err == nil {
+		err = h.Repos.Media.Delete(ctx, vehicleID, mediaID)
+	}
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Media not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the media."})
+		return
+	}
+
+	h.deleteMediaFiles(item.StorageKey)
+	context.JSON(http.StatusOK, gin.H{"message": "Media deleted successfully!"})
+}
+
+type mediaOrderRequest struct {
+	IDs []int64 `json:"ids" binding:"required"`
+}
+
+func (h *handler) reorderVehicleMedia(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	var request mediaOrderRequest
+	if err := context.ShouldBindJSON(&request); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Media.Reorder(ctx, vehicleID, request.IDs)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var invalid models.ValidationErrors
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+	case errors.As(err, &invalid):
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid media order.", "errors": invalid})
+	case err != nil:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not reorder the media."})
+	default:
+		context.JSON(http.StatusOK, gin.H{"message": "Media reordered successfully!"})
+	}
+}
+
+func (h *handler) setVehicleMediaCover(context *gin.Context) {
+	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	mediaID, err := strconv.ParseInt(context.Param("mediaId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse media id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Media.SetCover(ctx, vehicleID, mediaID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Media not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not set the cover image."})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Cover image updated!"})
+}
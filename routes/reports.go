@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) getInventoryAging(context *gin.Context) {
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	stock, err := h.Repos.Vehicles.Stock(ctx)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not build the inventory aging report."})
+		return
+	}
+
+	context.JSON(http.StatusOK, models.BuildInventoryAging(stock, time.Now()))
+}
@@ -0,0 +1,186 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) getReservations(context *gin.Context) {
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	reservations, err := h.Repos.Reservations.GetAll(ctx)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch reservations. Try again later."})
+		return
+	}
+	context.JSON(http.StatusOK, reservations)
+}
+
+func (h *handler) getReservation(context *gin.Context) {
+	reservationID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse reservation id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	reservation, err := h.Repos.Reservations.GetByID(ctx, reservationID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Reservation not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch reservation."})
+		return
+	}
+
+	context.JSON(http.StatusOK, reservation)
+}
+
+func (h *handler) createReservation(context *gin.Context) {
+	var reservation models.Reservation
+	err := context.ShouldBindJSON(&reservation)
+
+	if err != nil {
+		log.Printf("JSON binding error: %v", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	if err := reservation.Validate(time.Now()); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid reservation data.", "errors": err})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Reservations.Create(ctx, &reservation)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var transitionErr *models.InvalidTransitionError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Client or vehicle not found."})
+		return
+	case errors.As(err, &transitionErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":    "Vehicle is not available for reservation",
+			"vehicle_id": reservation.VehicleID,
+			"status":     transitionErr.From,
+		})
+		return
+	case err != nil:
+		log.Printf("Database save error: %v", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create reservation. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Reservation created!", "reservation": reservation})
+}
+
+func (h *handler) cancelReservation(context *gin.Context) {
+	reservationID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse reservation id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Reservations.Cancel(ctx, reservationID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var notActiveErr *models.ReservationNotActiveError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Reservation not found."})
+		return
+	case errors.As(err, &notActiveErr):
+		context.JSON(http.StatusConflict, gin.H{"message": "Reservation is no longer active", "status": notActiveErr.Status})
+		return
+	case err != nil:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not cancel the reservation."})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Reservation cancelled!"})
+}
+
+// convertReservation sells the reserved vehicle to the reservation's client,
+// crediting the deposit against the price.
+func (h *handler) convertReservation(context *gin.Context) {
+	reservationID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse reservation id."})
+		return
+	}
+
+	var request struct {
+		Price float64 `json:"price" binding:"required"`
+	}
+	if err := context.ShouldBindJSON(&request); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	sale := models.Sale{Price: request.Price}
+	err = h.Repos.Reservations.Convert(ctx, reservationID, &sale)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Reservation not found."})
+		return
+	}
+
+	if err != nil {
+		log.Printf("Reservation conversion error: %v", err)
+		respondSaleError(context, err, sale.VehicleID)
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Sale created successfully!", "sale": sale})
+}
+
+// respondVehicleReserved answers 409 for a vehicle held by a reservation.
+func respondVehicleReserved(context *gin.Context, err *models.VehicleReservedError) {
+	context.JSON(http.StatusConflict, gin.H{
+		"message":        "Vehicle is reserved",
+		"vehicle_id":     err.VehicleID,
+		"reservation_id": err.ReservationID,
+		"expires_at":     err.ExpiresAt,
+	})
+}
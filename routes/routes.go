@@ -1,24 +1,155 @@
 package routes
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Stand/config"
+	"github.com/Stand/media"
+	"github.com/Stand/models"
+	"github.com/Stand/vin"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(server *gin.Engine) {
-	server.GET("/vehicles", getVehicles)
-	server.GET("/vehicles/:id", getVehicle)
-	server.POST("/vehicles", createVehicle)
-	server.PUT("/vehicles/:id", updateVehicle)
-	server.DELETE("/vehicles/:id", deleteVehicle)
-	// /vehicles?type=carro&brand=Toyota&year=2020
-
-	server.GET("/clients", getClients)
-	server.GET("/clients/:id", getClient)
-	server.POST("/clients", createClient)
-	server.PUT("/clients/:id", updateClient)
-	server.DELETE("/clients/:id", deleteClient)
-
-	server.GET("/sales", getSales)
-	server.GET("/sales/:id", getSale)
-	server.POST("/sales", createSale)
+// Dependencies are the services used by the route handlers.
+type Dependencies struct {
+	Repos        models.Repositories
+	Timeouts     config.Timeouts
+	VINDecoder   *vin.Decoder
+	MediaStorage media.Storage
+	// MaxUploadSize is the largest accepted upload request, in bytes.
+	MaxUploadSize int64
+	// FeedBaseURL prefixes the photo URLs of the feed; when empty they are
+	// built from the request's host.
+	FeedBaseURL string
+	// DocumentExpiryWindow is the default window of /documents/expiring.
+	DocumentExpiryWindow time.Duration
+}
+
+type handler struct {
+	Dependencies
+}
+
+func RegisterRoutes(server *gin.Engine, deps Dependencies) {
+	h := &handler{deps}
+
+	server.GET("/vehicles", h.getVehicles)
+	server.GET("/vehicles/facets", h.getVehicleFacets)
+	server.GET("/vehicle-types", h.getVehicleTypes)
+	server.GET("/vehicles/decode-vin/:vin", h.decodeVIN)
+	server.GET("/vehicles/:id", h.getVehicle)
+	server.POST("/vehicles", h.createVehicle)
+	server.POST("/vehicles/import", h.importVehicles)
+	server.PUT("/vehicles/:id", h.updateVehicle)
+	server.DELETE("/vehicles/:id", h.deleteVehicle)
+	server.GET("/vehicles/:id/transitions", h.getVehicleTransitions)
+	server.POST("/vehicles/:id/transitions", h.createVehicleTransition)
+	server.GET("/vehicles/:id/expenses", h.getVehicleExpenses)
+	server.POST("/vehicles/:id/expenses", h.createVehicleExpense)
+	server.DELETE("/vehicles/:id/expenses/:expenseId", h.deleteVehicleExpense)
+	server.GET("/vehicles/:id/profitability", h.getVehicleProfitability)
+	server.GET("/vehicles/:id/media", h.getVehicleMedia)
+	server.POST("/vehicles/:id/media", h.uploadVehicleMedia)
+	server.PUT("/vehicles/:id/media/order", h.reorderVehicleMedia)
+	server.GET("/vehicles/:id/media/:mediaId/:rendition", h.getVehicleMediaFile)
+	server.POST("/vehicles/:id/media/:mediaId/cover", h.setVehicleMediaCover)
+	server.DELETE("/vehicles/:id/media/:mediaId", h.deleteVehicleMedia)
+	server.GET("/vehicles/:id/documents", h.getVehicleDocuments)
+	server.POST("/vehicles/:id/documents", h.uploadVehicleDocument)
+	server.GET("/vehicles/:id/documents/:documentId", h.getVehicleDocumentFile)
+	server.DELETE("/vehicles/:id/documents/:documentId", h.deleteVehicleDocument)
+	// /documents/expiring?within_days=60
+	server.GET("/documents/expiring", h.getExpiringDocuments)
+	// /vehicles?type=carro&brand=Toyota,BMW&year_min=2018&price_max=20000&q=diesel&sort=-year&limit=20
+	// /vehicles?fuel=electric,plug_in_hybrid&battery_min=50&power_hp_min=150&co2_max=120&emission_standard=Euro 6d
+
+	server.GET("/clients", h.getClients)
+	server.GET("/clients/:id", h.getClient)
+	server.POST("/clients", h.createClient)
+	server.PUT("/clients/:id", h.updateClient)
+	server.DELETE("/clients/:id", h.deleteClient)
+
+	server.GET("/sales", h.getSales)
+	server.GET("/sales/:id", h.getSale)
+	server.POST("/sales", h.createSale)
+
+	server.GET("/reservations", h.getReservations)
+	server.GET("/reservations/:id", h.getReservation)
+	server.POST("/reservations", h.createReservation)
+	server.POST("/reservations/:id/cancel", h.cancelReservation)
+	server.POST("/reservations/:id/convert", h.convertReservation)
+
+	server.GET("/consignments", h.getConsignments)
+	server.GET("/consignments/:id", h.getConsignment)
+	server.POST("/consignments", h.createConsignment)
+	server.POST("/consignments/:id/withdraw", h.withdrawConsignment)
+	server.GET("/consignments/:id/settlement", h.getConsignmentSettlement)
+
+	server.GET("/locations", h.getLocations)
+	server.GET("/locations/stock", h.getLocationStock)
+	server.GET("/locations/:id", h.getLocation)
+	server.POST("/locations", h.createLocation)
+	server.PUT("/locations/:id", h.updateLocation)
+	server.DELETE("/locations/:id", h.deleteLocation)
+
+	// /transfers?vehicle_id=12
+	server.GET("/transfers", h.getTransfers)
+	server.GET("/transfers/:id", h.getTransfer)
+	server.POST("/transfers", h.requestTransfer)
+	server.POST("/transfers/:id/dispatch", h.dispatchTransfer)
+	server.POST("/transfers/:id/receive", h.receiveTransfer)
+	server.POST("/transfers/:id/cancel", h.cancelTransfer)
+
+	// /test-drives?salesperson=Ana&location_id=1&vehicle_id=12&from=2026-03-01T00:00:00Z&scheduled=true
+	server.GET("/test-drives", h.getTestDrives)
+	server.GET("/test-drives/:id", h.getTestDrive)
+	server.POST("/test-drives", h.bookTestDrive)
+	server.POST("/test-drives/:id/cancel", h.cancelTestDrive)
+	// /calendars/salespeople/Ana.ics, /calendars/locations/1.ics
+	server.GET("/calendars/salespeople/:file", h.getSalespersonCalendar)
+	server.GET("/calendars/locations/:file", h.getLocationCalendar)
+
+	server.GET("/catalog/brands", h.getBrands)
+	server.POST("/catalog/brands", h.createBrand)
+	server.GET("/catalog/brands/:id/models", h.getBrandModels)
+	server.POST("/catalog/brands/:id/models", h.createBrandModel)
+
+	server.GET("/reports/inventory-aging", h.getInventoryAging)
+
+	// /feeds/vehicles.xml, /feeds/vehicles.json?since=last
+	server.GET("/feeds/:file", h.getFeed)
+}
+
+// readContext derives the context for a read operation from the request, so
+// it is cancelled when the client disconnects or the read deadline passes.
+func (h *handler) readContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), h.Timeouts.Read.Duration)
+}
+
+// writeContext is readContext for operations that modify data.
+func (h *handler) writeContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), h.Timeouts.Write.Duration)
+}
+
+// abortOnContextError responds with 504 when err was caused by the operation
+// deadline and 503 when the request was cancelled, reporting whether it did.
+func abortOnContextError(c *gin.Context, ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Drivers do not always wrap the context error, so check ctx as well.
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		c.JSON(http.StatusGatewayTimeout, gin.H{"message": "The operation timed out. Try again later."})
+		return true
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "The request was cancelled."})
+		return true
+	}
+
+	return false
 }
@@ -0,0 +1,188 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Stand/config"
+	"github.com/Stand/media"
+	"github.com/Stand/memstore"
+	"github.com/Stand/models"
+	"github.com/Stand/routes"
+	"github.com/Stand/vin"
+	"github.com/gin-gonic/gin"
+)
+
+// newServer returns the API routes backed by repos, with the default
+// configuration and photos stored in a temporary directory.
+func newServer(t *testing.T, repos models.Repositories) http.Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Default()
+	cfg.Media.Path = t.TempDir()
+
+	decoder, err := vin.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, err := media.NewStorage(cfg.Media)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := gin.New()
+	routes.RegisterRoutes(server, routes.Dependencies{
+		Repos:                repos,
+		Timeouts:             cfg.Timeouts,
+		VINDecoder:           decoder,
+		MediaStorage:         storage,
+		MaxUploadSize:        cfg.Media.MaxUploadSize,
+		DocumentExpiryWindow: cfg.Documents.ExpiryWindow.Duration,
+	})
+	return server
+}
+
+// newMemoryServer is newServer backed by an empty in-memory store.
+func newMemoryServer(t *testing.T) http.Handler {
+	return newServer(t, memstore.New().Repositories())
+}
+
+// do sends a request with an optional JSON body and checks the response
+// status, decoding the response into out unless it is nil.
+func do(t *testing.T, server http.Handler, method, path, body string, status int, out interface{}) {
+	t.Helper()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+
+	if response.Code != status {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, response.Code, status, response.Body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(response.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+}
+
+const (
+	golf   = `{"Type":"Car","Brand":"vw","Model":"golf","Year":2019,"Motor":"1.6 TDI 115cv","Status":"available"}`
+	client = `{"name":"Rita Sousa","email":"rita@example.pt","phone":911111111}`
+)
+
+func TestVehicleCRUD(t *testing.T) {
+	server := newMemoryServer(t)
+
+	var created struct{ Vehicle models.Vehicle }
+	do(t, server, "POST", "/vehicles", golf, http.StatusCreated, &created)
+	if created.Vehicle.ID != 1 || created.Vehicle.Brand != "Volkswagen" || created.Vehicle.Model != "Golf" {
+		t.Errorf("created %+v, want vehicle 1 resolved to Volkswagen Golf", created.Vehicle)
+	}
+
+	update := strings.Replace(golf, `"Year":2019`, `"Year":2020`, 1)
+	do(t, server, "PUT", "/vehicles/1", update, http.StatusOK, nil)
+
+	var vehicle models.Vehicle
+	do(t, server, "GET", "/vehicles/1", "", http.StatusOK, &vehicle)
+	if vehicle.Year != 2020 || vehicle.Status != models.StatusAvailable {
+		t.Errorf("got year %d and status %q, want 2020 and available", vehicle.Year, vehicle.Status)
+	}
+
+	do(t, server, "PUT", "/vehicles/9", update, http.StatusNotFound, nil)
+	do(t, server, "DELETE", "/vehicles/1", "", http.StatusOK, nil)
+	do(t, server, "GET", "/vehicles/1", "", http.StatusNotFound, nil)
+	do(t, server, "GET", "/vehicles/x", "", http.StatusBadRequest, nil)
+}
+
+func TestCreateVehicleStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		code   int
+		want   models.VehicleStatus
+	}{
+		{"", http.StatusCreated, models.StatusIncoming},
+		{"in_preparation", http.StatusCreated, models.StatusInPreparation},
+		{"available", http.StatusCreated, models.StatusAvailable},
+		{"reserved", http.StatusBadRequest, ""},
+		{"sold", http.StatusBadRequest, ""},
+		{"scrapped", http.StatusBadRequest, ""},
+		{"bogus", http.StatusBadRequest, ""},
+	}
+
+	server := newMemoryServer(t)
+	for _, test := range tests {
+		t.Run(test.status, func(t *testing.T) {
+			body := strings.Replace(golf, `"available"`, `"`+test.status+`"`, 1)
+			var created struct{ Vehicle models.Vehicle }
+			do(t, server, "POST", "/vehicles", body, test.code, &created)
+			if created.Vehicle.Status != test.want {
+				t.Errorf("status %q, want %q", created.Vehicle.Status, test.want)
+			}
+		})
+	}
+}
+
+func TestCreateVehicleFillsEngine(t *testing.T) {
+	server := newMemoryServer(t)
+	do(t, server, "POST", "/vehicles", golf, http.StatusCreated, nil)
+
+	var page models.VehiclePage
+	do(t, server, "GET", "/vehicles?fuel=gasoleo&power_hp_min=110", "", http.StatusOK, &page)
+	if len(page.Items) != 1 || page.Items[0].EngineCC != 1600 || page.Items[0].PowerKW != 85 {
+		t.Errorf("found %+v, want the Golf with 1600 cc and 85 kW", page.Items)
+	}
+}
+
+func TestCreateSale(t *testing.T) {
+	server := newMemoryServer(t)
+	do(t, server, "POST", "/vehicles", golf, http.StatusCreated, nil)
+	do(t, server, "POST", "/clients", client, http.StatusCreated, nil)
+
+	do(t, server, "POST", "/sales", `{"client_id":1,"vehicle_id":1,"price":15000}`, http.StatusCreated, nil)
+	do(t, server, "POST", "/sales", `{"client_id":1,"vehicle_id":1,"price":16000}`, http.StatusConflict, nil)
+	do(t, server, "POST", "/sales", `{"client_id":1,"vehicle_id":9,"price":16000}`, http.StatusNotFound, nil)
+
+	var vehicle models.Vehicle
+	do(t, server, "GET", "/vehicles/1", "", http.StatusOK, &vehicle)
+	if vehicle.Status != models.StatusSold {
+		t.Errorf("vehicle status %q, want sold", vehicle.Status)
+	}
+
+	var transitions []models.VehicleStatusChange
+	do(t, server, "GET", "/vehicles/1/transitions", "", http.StatusOK, &transitions)
+	if len(transitions) != 2 || transitions[1].To != models.StatusSold {
+		t.Errorf("transitions %+v, want creation then sold", transitions)
+	}
+}
+
+func TestTradeInSale(t *testing.T) {
+	server := newMemoryServer(t)
+	do(t, server, "POST", "/vehicles", golf, http.StatusCreated, nil)
+	do(t, server, "POST", "/clients", client, http.StatusCreated, nil)
+
+	sale := `{"client_id":1,"vehicle_id":1,"price":15000,"trade_ins":[
+		{"valuation":4000,"vehicle":{"Type":"Car","Brand":"renault","Model":"clio","Year":2012,"Motor":"1.0 gasolina 69cv"}}]}`
+	do(t, server, "POST", "/sales", sale, http.StatusCreated, nil)
+
+	var tradeIn models.Vehicle
+	do(t, server, "GET", "/vehicles/2", "", http.StatusOK, &tradeIn)
+	if tradeIn.Status != models.StatusIncoming || tradeIn.PurchasePrice != 4000 {
+		t.Errorf("trade-in status %q and purchase price %.2f, want incoming and 4000", tradeIn.Status, tradeIn.PurchasePrice)
+	}
+	if tradeIn.FuelType != models.FuelPetrol || tradeIn.EngineCC != 1000 || tradeIn.PowerHP != 69 {
+		t.Errorf("trade-in engine %q %d cc %d hp, want petrol 1000 cc 69 hp", tradeIn.FuelType, tradeIn.EngineCC, tradeIn.PowerHP)
+	}
+
+	var page models.VehiclePage
+	do(t, server, "GET", "/vehicles?fuel=gasolina", "", http.StatusOK, &page)
+	if len(page.Items) != 1 || page.Items[0].ID != 2 {
+		t.Errorf("petrol vehicles %+v, want the trade-in", page.Items)
+	}
+}
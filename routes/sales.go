@@ -1,16 +1,26 @@
 package routes
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/Stand/engine"
 	"github.com/Stand/models"
 	"github.com/gin-gonic/gin"
 )
 
-func getSales(context *gin.Context) {
-	sales, err := models.GetAllSales()
+func (h *handler) getSales(context *gin.Context) {
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	sales, err := h.Repos.Sales.GetAll(ctx)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch sales. Try again later."})
@@ -19,7 +29,7 @@ func getSales(context *gin.Context) {
 	context.JSON(http.StatusOK, sales)
 }
 
-func getSale(context *gin.Context) {
+func (h *handler) getSale(context *gin.Context) {
 	saleId, err := strconv.ParseInt(context.Param("id"), 10, 64)
 
 	if err != nil {
@@ -27,7 +37,19 @@ func getSale(context *gin.Context) {
 		return
 	}
 
-	sale, err := models.GetSaleByID(saleId)
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	sale, err := h.Repos.Sales.GetByID(ctx, saleId)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Sale not found."})
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch sale."})
@@ -37,7 +59,7 @@ func getSale(context *gin.Context) {
 	context.JSON(http.StatusOK, sale)
 }
 
-func createSale(context *gin.Context) {
+func (h *handler) createSale(context *gin.Context) {
 	log.Println("Starting createSale handler")
 
 	var sale models.Sale
@@ -51,23 +73,86 @@ func createSale(context *gin.Context) {
 
 	log.Printf("Parsed sale data: %+v", sale)
 
-	err = sale.Save()
+	if err := sale.PrepareTradeIns(engine.Complete); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid trade-in data.", "errors": err})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Sales.Create(ctx, &sale)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
 
 	if err != nil {
 		log.Printf("Database save error: %v", err)
-		// Check if it's a vehicle already sold error
-		if vehicleErr, ok := err.(*models.VehicleAlreadySoldError); ok {
-			context.JSON(http.StatusConflict, gin.H{
-				"message":    "Vehicle is already sold",
-				"vehicle_id": vehicleErr.VehicleID,
-			})
-			return
-		}
-
-		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create sale. Try again later."})
+		respondSaleError(context, err, sale.VehicleID)
 		return
 	}
 
 	log.Println("Sale created successfully")
 	context.JSON(http.StatusCreated, gin.H{"message": "Sale created successfully!", "sale": sale})
 }
+
+// respondSaleError maps the errors of creating a sale to a response.
+func respondSaleError(context *gin.Context, err error, vehicleID int64) {
+	var soldErr *models.VehicleAlreadySoldError
+	var reservedErr *models.VehicleReservedError
+	var notActiveErr *models.ReservationNotActiveError
+	var transitionErr *models.InvalidTransitionError
+	var duplicateErr *models.DuplicateVehicleError
+	var invalidErr models.ValidationErrors
+	var belowMinimumErr *models.BelowMinimumPriceError
+
+	switch {
+	case errors.As(err, &soldErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":    "Vehicle is already sold",
+			"vehicle_id": soldErr.VehicleID,
+		})
+	case errors.As(err, &reservedErr):
+		respondVehicleReserved(context, reservedErr)
+	case errors.As(err, &notActiveErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":        "Reservation is no longer active",
+			"reservation_id": notActiveErr.ReservationID,
+			"status":         notActiveErr.Status,
+		})
+	case errors.As(err, &belowMinimumErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":        "Price is below the minimum agreed with the vehicle's owner",
+			"consignment_id": belowMinimumErr.ConsignmentID,
+			"minimum_price":  belowMinimumErr.MinimumPrice,
+		})
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Client or vehicle not found."})
+	case errors.As(err, &transitionErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":    "Vehicle is not available for sale",
+			"vehicle_id": vehicleID,
+			"status":     transitionErr.From,
+		})
+	case errors.As(err, &duplicateErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message": "Another vehicle already has the VIN or plate of a trade-in.",
+			"field":   duplicateErr.Field,
+		})
+	case errors.As(err, &invalidErr):
+		// A trade-in the store rejected, such as one whose brand is not in
+		// the catalogue; name the fields like PrepareTradeIns does.
+		var batchErr *models.BatchError
+		if errors.As(err, &batchErr) {
+			prefixed := models.ValidationErrors{}
+			for _, fieldErr := range invalidErr {
+				prefixed = append(prefixed, models.ValidationError{Field: fmt.Sprintf("trade_ins[%d].vehicle.%s", batchErr.Index, fieldErr.Field), Message: fieldErr.Message})
+			}
+			invalidErr = prefixed
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid trade-in data.", "errors": invalidErr})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create sale. Try again later."})
+	}
+}
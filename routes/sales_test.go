@@ -0,0 +1,224 @@
+package routes_test
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Stand/config"
+	"github.com/Stand/db"
+	"github.com/Stand/models"
+	"github.com/Stand/sqlstore"
+)
+
+// newSQLiteStore returns a migrated SQLite database in a temporary
+// directory and the repositories over it.
+func newSQLiteStore(t *testing.T) (*sql.DB, models.Repositories) {
+	t.Helper()
+
+	cfg := config.Default().Database
+	cfg.Driver = "sqlite"
+	cfg.Path = filepath.Join(t.TempDir(), "stand.db")
+
+	dialect, err := db.DialectFor(cfg.Driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := sql.Open(dialect.DriverName(), dialect.DSN(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetMaxOpenConns(cfg.MaxOpenConns)
+
+	migrator, err := db.NewMigrator(conn, dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return conn, sqlstore.New(conn, dialect).Repositories()
+}
+
+// newPostgresStore returns the repositories over a fresh schema of the
+// Postgres database named by STAND_TEST_POSTGRES_DSN, and skips the test
+// when it is unset. The schema is dropped when the test ends.
+func newPostgresStore(t *testing.T) (*sql.DB, models.Repositories) {
+	t.Helper()
+
+	dsn := os.Getenv("STAND_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("STAND_TEST_POSTGRES_DSN is not set")
+	}
+
+	dialect, err := db.DialectFor("postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := sql.Open(dialect.DriverName(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("stand_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// lib/pq passes options it does not know to the server as run-time
+	// parameters, so every connection uses the new schema.
+	if strings.Contains(dsn, "://") {
+		if strings.Contains(dsn, "?") {
+			dsn += "&search_path=" + schema
+		} else {
+			dsn += "?search_path=" + schema
+		}
+	} else {
+		dsn += " search_path=" + schema
+	}
+	conn, err := sql.Open(dialect.DriverName(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetMaxOpenConns(config.Default().Database.MaxOpenConns)
+
+	migrator, err := db.NewMigrator(conn, dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return conn, sqlstore.New(conn, dialect).Repositories()
+}
+
+// forEachStore runs test against a server backed by each store that needs
+// no external database.
+func forEachStore(t *testing.T, test func(t *testing.T, server http.Handler)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, newMemoryServer(t))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		_, repos := newSQLiteStore(t)
+		test(t, newServer(t, repos))
+	})
+}
+
+// TestConcurrentSales fires parallel sales of one vehicle to different
+// clients: exactly one may succeed. SQLite serialises them with its write
+// lock.
+func TestConcurrentSales(t *testing.T) {
+	conn, repos := newSQLiteStore(t)
+	testConcurrentSales(t, conn, repos)
+}
+
+// TestConcurrentSalesPostgres is TestConcurrentSales on Postgres, where the
+// sales are serialised by SELECT ... FOR UPDATE on the vehicle.
+func TestConcurrentSalesPostgres(t *testing.T) {
+	conn, repos := newPostgresStore(t)
+	testConcurrentSales(t, conn, repos)
+}
+
+func testConcurrentSales(t *testing.T, conn *sql.DB, repos models.Repositories) {
+	const buyers = 20
+
+	handler := newServer(t, repos)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	do(t, handler, "POST", "/vehicles", golf, http.StatusCreated, nil)
+	for i := 0; i < buyers; i++ {
+		do(t, handler, "POST", "/clients", client, http.StatusCreated, nil)
+	}
+
+	codes := make(chan int, buyers)
+	var wg sync.WaitGroup
+	for i := 1; i <= buyers; i++ {
+		wg.Add(1)
+		go func(clientID int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"client_id":%d,"vehicle_id":1,"price":15000}`, clientID)
+			response, err := http.Post(server.URL+"/sales", "application/json", strings.NewReader(body))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			response.Body.Close()
+			codes <- response.StatusCode
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := map[int]int{}
+	for code := range codes {
+		counts[code]++
+	}
+	if counts[http.StatusCreated] != 1 || counts[http.StatusConflict] != buyers-1 {
+		t.Errorf("responses %v, want one %d and %d %d", counts, http.StatusCreated, buyers-1, http.StatusConflict)
+	}
+
+	var sales int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sales WHERE vehicle_id = 1").Scan(&sales); err != nil {
+		t.Fatal(err)
+	}
+	if sales != 1 {
+		t.Errorf("%d sales rows, want 1", sales)
+	}
+
+	var status models.VehicleStatus
+	if err := conn.QueryRow("SELECT status FROM vehicles WHERE id = 1").Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != models.StatusSold {
+		t.Errorf("vehicle status %q, want sold", status)
+	}
+}
+
+// TestConsignedSaleCost checks that a sale and the profitability report
+// agree on the cost of a consigned vehicle: what the owner is owed plus
+// expenses, with no purchase price.
+func TestConsignedSaleCost(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server http.Handler) {
+		bought := strings.Replace(golf, `"Status"`, `"PurchasePrice":12000,"Status"`, 1)
+		do(t, server, "POST", "/vehicles", bought, http.StatusCreated, nil)
+		do(t, server, "POST", "/vehicles", golf, http.StatusCreated, nil)
+		do(t, server, "POST", "/clients", client, http.StatusCreated, nil)
+
+		consign := `{"vehicle_id":%d,"owner_id":1,"minimum_price":20000,"commission_type":"percentage","commission_value":10}`
+		do(t, server, "POST", "/consignments", fmt.Sprintf(consign, 1), http.StatusConflict, nil)
+		do(t, server, "POST", "/consignments", fmt.Sprintf(consign, 2), http.StatusCreated, nil)
+		do(t, server, "PUT", "/vehicles/2", bought, http.StatusConflict, nil)
+		do(t, server, "POST", "/vehicles/2/expenses", `{"category":"cleaning","amount":400}`, http.StatusCreated, nil)
+		do(t, server, "POST", "/sales", `{"client_id":1,"vehicle_id":2,"price":26000}`, http.StatusCreated, nil)
+
+		var sale models.SaleWithDetails
+		do(t, server, "GET", "/sales/1", "", http.StatusOK, &sale)
+		var profitability models.Profitability
+		do(t, server, "GET", "/vehicles/2/profitability", "", http.StatusOK, &profitability)
+
+		// The owner is owed 26000 less the 10% commission.
+		if sale.Cost != 23800 || sale.GrossMargin != 2200 {
+			t.Errorf("sale cost %.2f and margin %.2f, want 23800 and 2200", sale.Cost, sale.GrossMargin)
+		}
+		if profitability.TotalCost != sale.Cost || profitability.GrossMargin != sale.GrossMargin {
+			t.Errorf("profitability cost %.2f and margin %.2f, want the sale's %.2f and %.2f",
+				profitability.TotalCost, profitability.GrossMargin, sale.Cost, sale.GrossMargin)
+		}
+	})
+}
@@ -0,0 +1,293 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Stand/calendar"
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+// calendarHistory is how far back calendar feeds go.
+const calendarHistory = 30 * 24 * time.Hour
+
+func (h *handler) getTestDrives(context *gin.Context) {
+	var filter models.TestDriveFilter
+	var err error
+	if filter.VehicleID, err = queryID(context, "vehicle_id"); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if filter.LocationID, err = queryID(context, "location_id"); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	filter.Salesperson = strings.TrimSpace(context.Query("salesperson"))
+	filter.Scheduled = context.Query("scheduled") == "true"
+	if value := context.Query("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "from must be an RFC 3339 time."})
+			return
+		}
+		filter.EndsAfter = &from
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	drives, err := h.Repos.TestDrives.Find(ctx, filter)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch test drives. Try again later."})
+		return
+	}
+	context.JSON(http.StatusOK, drives)
+}
+
+func (h *handler) getTestDrive(context *gin.Context) {
+	driveID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse test drive id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	drive, err := h.Repos.TestDrives.GetByID(ctx, driveID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Test drive not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch test drive."})
+		return
+	}
+
+	context.JSON(http.StatusOK, drive)
+}
+
+func (h *handler) bookTestDrive(context *gin.Context) {
+	var drive models.TestDrive
+	if err := context.ShouldBindJSON(&drive); err != nil {
+		log.Printf("JSON binding error: %v", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	if err := drive.Validate(time.Now()); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid test drive data.", "errors": err})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err := h.Repos.TestDrives.Book(ctx, &drive)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var notBookableErr *models.VehicleNotBookableError
+	var inTransferErr *models.VehicleInTransferError
+	var conflictErr *models.TestDriveConflictError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Client or vehicle not found."})
+		return
+	case errors.As(err, &notBookableErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":    "Vehicle is " + string(notBookableErr.Status) + " and cannot be test driven",
+			"vehicle_id": notBookableErr.VehicleID,
+		})
+		return
+	case errors.As(err, &inTransferErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":     "Vehicle is being transferred and cannot be test driven",
+			"vehicle_id":  inTransferErr.VehicleID,
+			"transfer_id": inTransferErr.TransferID,
+		})
+		return
+	case errors.As(err, &conflictErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":       "Test drive overlaps another booking",
+			"field":         conflictErr.Field,
+			"test_drive_id": conflictErr.TestDriveID,
+			"starts_at":     conflictErr.StartsAt,
+			"ends_at":       conflictErr.EndsAt,
+		})
+		return
+	case err != nil:
+		log.Printf("Database save error: %v", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not book test drive. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Test drive booked!", "test_drive": drive})
+}
+
+func (h *handler) cancelTestDrive(context *gin.Context) {
+	driveID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse test drive id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.TestDrives.Cancel(ctx, driveID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var notScheduledErr *models.TestDriveNotScheduledError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Test drive not found."})
+		return
+	case errors.As(err, &notScheduledErr):
+		context.JSON(http.StatusConflict, gin.H{"message": "Test drive is " + string(notScheduledErr.Status), "status": notScheduledErr.Status})
+		return
+	case err != nil:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not cancel the test drive."})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Test drive cancelled!"})
+}
+
+// getSalespersonCalendar serves the test drives of a salesperson, e.g.
+// /calendars/salespeople/Ana%20Silva.ics.
+func (h *handler) getSalespersonCalendar(context *gin.Context) {
+	name, ok := strings.CutSuffix(context.Param("file"), ".ics")
+	if name = strings.TrimSpace(name); !ok || name == "" {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Calendar not found."})
+		return
+	}
+
+	h.serveTestDriveCalendar(context, "Test drives: "+name, models.TestDriveFilter{Salesperson: name})
+}
+
+// getLocationCalendar serves the test drives at a location, e.g.
+// /calendars/locations/1.ics.
+func (h *handler) getLocationCalendar(context *gin.Context) {
+	raw, ok := strings.CutSuffix(context.Param("file"), ".ics")
+	locationID, err := strconv.ParseInt(raw, 10, 64)
+	if !ok || err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Calendar not found."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	location, err := h.Repos.Locations.GetByID(ctx, locationID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Location not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch location."})
+		return
+	}
+
+	h.serveTestDriveCalendar(context, "Test drives: "+location.Name, models.TestDriveFilter{LocationID: &locationID})
+}
+
+// serveTestDriveCalendar writes the test drives selected by filter that
+// ended within calendarHistory as an iCalendar feed. Cancelled test drives
+// are included so subscribed calendars drop them.
+func (h *handler) serveTestDriveCalendar(context *gin.Context, name string, filter models.TestDriveFilter) {
+	since := time.Now().Add(-calendarHistory)
+	filter.EndsAfter = &since
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	drives, err := h.Repos.TestDrives.Find(ctx, filter)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch test drives. Try again later."})
+		return
+	}
+
+	events := make([]calendar.Event, len(drives))
+	for i := range drives {
+		events[i] = testDriveEvent(&drives[i])
+	}
+
+	context.Header("Content-Type", calendar.ContentType)
+	context.Status(http.StatusOK)
+	if err := calendar.Write(context.Writer, name, events); err != nil {
+		slog.Warn("Could not write calendar", "err", err)
+	}
+}
+
+// testDriveEvent describes a test drive for calendar apps.
+func testDriveEvent(drive *models.TestDriveWithDetails) calendar.Event {
+	vehicle := strings.TrimSpace(drive.Vehicle.Brand + " " + drive.Vehicle.Model)
+	if drive.Vehicle.LicensePlate != "" {
+		vehicle += " (" + drive.Vehicle.LicensePlate + ")"
+	}
+
+	description := []string{
+		fmt.Sprintf("Client: %s, %d, %s", drive.Client.Name, drive.Client.Phone, drive.Client.Email),
+		"Vehicle: " + vehicle,
+		"Salesperson: " + drive.Salesperson,
+	}
+	if drive.Notes != "" {
+		description = append(description, "", drive.Notes)
+	}
+
+	event := calendar.Event{
+		UID:         fmt.Sprintf("test-drive-%d@stand-api", drive.ID),
+		Start:       drive.StartsAt,
+		End:         drive.EndsAt,
+		Summary:     "Test drive: " + vehicle + " with " + drive.Client.Name,
+		Description: strings.Join(description, "\n"),
+		Updated:     drive.CreatedAt,
+	}
+	if drive.Location != nil {
+		event.Location = drive.Location.Name
+		if drive.Location.Address != "" {
+			event.Location += ", " + drive.Location.Address
+		}
+	}
+	if drive.CancelledAt != nil {
+		event.Cancelled = true
+		event.Updated = *drive.CancelledAt
+		event.Sequence = 1
+	}
+	return event
+}
@@ -0,0 +1,28 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBookTestDriveDuringTransfer(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server http.Handler) {
+		do(t, server, "POST", "/locations", `{"name":"Lisboa"}`, http.StatusCreated, nil)
+		do(t, server, "POST", "/locations", `{"name":"Porto"}`, http.StatusCreated, nil)
+		do(t, server, "POST", "/vehicles", golf, http.StatusCreated, nil)
+		do(t, server, "POST", "/clients", client, http.StatusCreated, nil)
+
+		startsAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Hour)
+		booking := fmt.Sprintf(`{"client_id":1,"vehicle_id":1,"salesperson":"Ana","starts_at":%q,"ends_at":%q}`,
+			startsAt.Format(time.RFC3339), startsAt.Add(time.Hour).Format(time.RFC3339))
+
+		do(t, server, "POST", "/transfers", `{"vehicle_id":1,"to_location_id":2}`, http.StatusCreated, nil)
+		do(t, server, "POST", "/test-drives", booking, http.StatusConflict, nil)
+		do(t, server, "POST", "/transfers/1/dispatch", `{"driver":"Rui"}`, http.StatusOK, nil)
+		do(t, server, "POST", "/test-drives", booking, http.StatusConflict, nil)
+		do(t, server, "POST", "/transfers/1/receive", "", http.StatusOK, nil)
+		do(t, server, "POST", "/test-drives", booking, http.StatusCreated, nil)
+	})
+}
@@ -0,0 +1,211 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) getTransfers(context *gin.Context) {
+	vehicleID, err := queryID(context, "vehicle_id")
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	transfers, err := h.Repos.Transfers.GetAll(ctx, vehicleID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch transfers. Try again later."})
+		return
+	}
+	context.JSON(http.StatusOK, transfers)
+}
+
+func (h *handler) getTransfer(context *gin.Context) {
+	transferID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse transfer id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	transfer, err := h.Repos.Transfers.GetByID(ctx, transferID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Transfer not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch transfer."})
+		return
+	}
+
+	context.JSON(http.StatusOK, transfer)
+}
+
+func (h *handler) requestTransfer(context *gin.Context) {
+	var transfer models.VehicleTransfer
+	if err := context.ShouldBindJSON(&transfer); err != nil {
+		log.Printf("JSON binding error: %v", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+	transfer.Driver = strings.TrimSpace(transfer.Driver)
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err := h.Repos.Transfers.Request(ctx, &transfer)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	var inTransferErr *models.VehicleInTransferError
+	var invalid models.ValidationErrors
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle or location not found."})
+		return
+	case errors.As(err, &inTransferErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message":     "Vehicle already has an open transfer",
+			"vehicle_id":  inTransferErr.VehicleID,
+			"transfer_id": inTransferErr.TransferID,
+		})
+		return
+	case errors.As(err, &invalid):
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid transfer data.", "errors": invalid})
+		return
+	case err != nil:
+		log.Printf("Database save error: %v", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not request transfer. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Transfer requested!", "transfer": transfer})
+}
+
+// dispatchTransfer puts a requested transfer in transit. The driver may be
+// given here or when the transfer was requested.
+func (h *handler) dispatchTransfer(context *gin.Context) {
+	transferID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse transfer id."})
+		return
+	}
+
+	var body struct {
+		Driver string `json:"driver"`
+	}
+	if context.Request.ContentLength != 0 {
+		if err := context.ShouldBindJSON(&body); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+			return
+		}
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Transfers.Dispatch(ctx, transferID, strings.TrimSpace(body.Driver))
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if respondTransferError(context, err, "Could not dispatch the transfer.") {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Transfer dispatched!"})
+}
+
+// receiveTransfer completes a transfer in transit, moving the vehicle to the
+// destination.
+func (h *handler) receiveTransfer(context *gin.Context) {
+	transferID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse transfer id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Transfers.Receive(ctx, transferID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if respondTransferError(context, err, "Could not receive the transfer.") {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Transfer received!"})
+}
+
+func (h *handler) cancelTransfer(context *gin.Context) {
+	transferID, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse transfer id."})
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Transfers.Cancel(ctx, transferID)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if respondTransferError(context, err, "Could not cancel the transfer.") {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Transfer cancelled!"})
+}
+
+// respondTransferError maps the errors of a transfer step to a response and
+// reports whether there was one.
+func respondTransferError(context *gin.Context, err error, message string) bool {
+	var statusErr *models.TransferStatusError
+	var invalid models.ValidationErrors
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Transfer not found."})
+	case errors.As(err, &statusErr):
+		context.JSON(http.StatusConflict, gin.H{"message": "Transfer is " + string(statusErr.Status), "status": statusErr.Status})
+	case errors.As(err, &invalid):
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid transfer data.", "errors": invalid})
+	default:
+		log.Printf("Transfer error: %v", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": message})
+	}
+	return true
+}
@@ -0,0 +1,303 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+func (h *handler) getVehicles(context *gin.Context) {
+	filter, err := parseVehicleFilter(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	query, err := parseVehiclePage(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	query.Filter = filter
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	page, err := h.Repos.Vehicles.Find(ctx, query)
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch vehicles. Try again later."})
+		return
+	}
+	context.JSON(http.StatusOK, page)
+}
+
+func (h *handler) getVehicleFacets(context *gin.Context) {
+	filter, err := parseVehicleFilter(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	facets, err := h.Repos.Vehicles.Facets(ctx, filter)
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not count vehicles. Try again later."})
+		return
+	}
+	context.JSON(http.StatusOK, facets)
+}
+
+// parseVehicleFilter reads the filter parameters of GET /vehicles. List
+// parameters take comma-separated values and may be repeated.
+func parseVehicleFilter(context *gin.Context) (models.VehicleFilter, error) {
+	filter := models.VehicleFilter{
+		Brands:  queryList(context, "brand"),
+		Models:  queryList(context, "model"),
+		Colours: queryList(context, "colour"),
+		VIN:     models.NormalizeVIN(context.Query("vin")),
+		Query:   context.Query("q"),
+	}
+
+	// Types may be given by alias, e.g. type=carro.
+	for _, value := range queryList(context, "type") {
+		spec, ok := models.LookupVehicleType(value)
+		if !ok {
+			return filter, errors.New("Invalid vehicle type.")
+		}
+		filter.Types = append(filter.Types, spec.Name)
+	}
+
+	for _, value := range queryList(context, "status") {
+		status := models.VehicleStatus(strings.ToLower(value))
+		if !status.Valid() {
+			return filter, errors.New("Invalid vehicle status.")
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	for _, value := range queryList(context, "transmission") {
+		transmission := models.Transmission(strings.ToLower(value))
+		if !transmission.Valid() {
+			return filter, errors.New("Invalid transmission.")
+		}
+		filter.Transmissions = append(filter.Transmissions, transmission)
+	}
+
+	for _, value := range queryList(context, "licence_category") {
+		category := strings.ToUpper(value)
+		if !slices.Contains(models.LicenceCategories, category) {
+			return filter, errors.New("Invalid licence category.")
+		}
+		filter.LicenceCategories = append(filter.LicenceCategories, category)
+	}
+
+	// Fuel types may be given by alias, e.g. fuel=gasoleo.
+	for _, value := range queryList(context, "fuel") {
+		fuel, ok := models.LookupFuelType(value)
+		if !ok {
+			return filter, errors.New("Invalid fuel type.")
+		}
+		filter.FuelTypes = append(filter.FuelTypes, fuel)
+	}
+
+	for _, value := range queryList(context, "emission_standard") {
+		standard, err := models.NormalizeEmissionStandard(value)
+		if err != nil {
+			return filter, errors.New("Invalid emission standard.")
+		}
+		filter.EmissionStandards = append(filter.EmissionStandards, standard)
+	}
+
+	for _, value := range queryList(context, "location") {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filter, errors.New("Invalid location.")
+		}
+		filter.LocationIDs = append(filter.LocationIDs, id)
+	}
+
+	if plate := context.Query("plate"); plate != "" {
+		normalized, err := models.NormalizeLicensePlate(plate)
+		if err != nil {
+			return filter, errors.New("Invalid licence plate format.")
+		}
+		filter.LicensePlate = normalized
+	}
+
+	// year is shorthand for year_min and year_max with the same value.
+	year, err := queryInt(context, "year")
+	if err != nil {
+		return filter, err
+	}
+	filter.YearMin, filter.YearMax = year, year
+
+	ranges := []struct {
+		param string
+		dst   **int
+	}{
+		{"year_min", &filter.YearMin},
+		{"year_max", &filter.YearMax},
+		{"mileage_min", &filter.MileageMin},
+		{"mileage_max", &filter.MileageMax},
+		{"seats_min", &filter.SeatsMin},
+		{"seats_max", &filter.SeatsMax},
+		{"engine_cc_min", &filter.EngineCCMin},
+		{"engine_cc_max", &filter.EngineCCMax},
+		{"payload_min", &filter.PayloadMin},
+		{"payload_max", &filter.PayloadMax},
+		{"axles_min", &filter.AxlesMin},
+		{"axles_max", &filter.AxlesMax},
+		{"power_kw_min", &filter.PowerKWMin},
+		{"power_kw_max", &filter.PowerKWMax},
+		{"power_hp_min", &filter.PowerHPMin},
+		{"power_hp_max", &filter.PowerHPMax},
+		{"co2_min", &filter.CO2Min},
+		{"co2_max", &filter.CO2Max},
+	}
+	for _, r := range ranges {
+		value, err := queryInt(context, r.param)
+		if err != nil {
+			return filter, err
+		}
+		if value != nil {
+			*r.dst = value
+		}
+	}
+
+	if filter.PriceMin, err = queryFloat(context, "price_min"); err != nil {
+		return filter, err
+	}
+	if filter.PriceMax, err = queryFloat(context, "price_max"); err != nil {
+		return filter, err
+	}
+	if filter.BatteryMin, err = queryFloat(context, "battery_min"); err != nil {
+		return filter, err
+	}
+	if filter.BatteryMax, err = queryFloat(context, "battery_max"); err != nil {
+		return filter, err
+	}
+
+	if value := context.Query("updated_since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errors.New("updated_since must be an RFC 3339 time.")
+		}
+		filter.UpdatedSince = &since
+	}
+
+	return filter, nil
+}
+
+// parseVehiclePage reads the sort and pagination parameters: sort, limit,
+// and either offset or the cursor of a previous page.
+func parseVehiclePage(context *gin.Context) (models.VehicleQuery, error) {
+	query := models.VehicleQuery{Limit: defaultPageSize}
+
+	sort, err := models.ParseVehicleSort(context.Query("sort"))
+	if err != nil {
+		return query, errors.New("Invalid sort: " + err.Error() + ".")
+	}
+	query.Sort = sort
+
+	if limit, err := queryInt(context, "limit"); err != nil {
+		return query, err
+	} else if limit != nil {
+		if *limit < 1 || *limit > maxPageSize {
+			return query, errors.New("limit must be between 1 and " + strconv.Itoa(maxPageSize) + ".")
+		}
+		query.Limit = *limit
+	}
+
+	if offset, err := queryInt(context, "offset"); err != nil {
+		return query, err
+	} else if offset != nil {
+		if *offset < 0 {
+			return query, errors.New("offset must not be negative.")
+		}
+		query.Offset = *offset
+	}
+
+	if encoded := context.Query("cursor"); encoded != "" {
+		if query.Offset > 0 {
+			return query, errors.New("Use either offset or cursor, not both.")
+		}
+		cursor, err := models.DecodeVehicleCursor(encoded, sort)
+		if err != nil {
+			return query, errors.New("Invalid cursor for this sort order.")
+		}
+		query.After = cursor
+	}
+
+	return query, nil
+}
+
+// queryList splits every value of a repeated, comma-separated parameter.
+func queryList(context *gin.Context, key string) []string {
+	var values []string
+	for _, param := range context.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func queryInt(context *gin.Context, key string) (*int, error) {
+	raw := context.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, errors.New("Invalid " + key + " format.")
+	}
+	return &value, nil
+}
+
+// queryID reads an optional record ID.
+func queryID(context *gin.Context, key string) (*int64, error) {
+	raw := context.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, errors.New("Invalid " + key + " format.")
+	}
+	return &value, nil
+}
+
+func queryFloat(context *gin.Context, key string) (*float64, error) {
+	raw := context.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, errors.New("Invalid " + key + " format.")
+	}
+	return &value, nil
+}
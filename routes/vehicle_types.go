@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Stand/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getVehicleTypes serves the attribute schema of each vehicle type.
+func (h *handler) getVehicleTypes(context *gin.Context) {
+	context.JSON(http.StatusOK, models.VehicleTypes)
+}
@@ -1,68 +1,66 @@
 package routes
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/Stand/engine"
 	"github.com/Stand/models"
+	"github.com/Stand/vin"
 	"github.com/gin-gonic/gin"
 )
 
-func getVehicles(context *gin.Context) {
-	vehicleType := context.Query("type")
-	brand := context.Query("brand")
-	yearStr := context.Query("year")
+func (h *handler) getVehicle(context *gin.Context) {
+	vehicleId, err := strconv.ParseInt(context.Param("id"), 10, 64)
 
-	var year int
-	if yearStr != "" {
-		parsedYear, err := strconv.Atoi(yearStr)
-		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid year format."})
-			return
-		}
-		year = parsedYear
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
 	}
 
-	// If any filters are provided, use filtered search
-	if vehicleType != "" || brand != "" || year > 0 {
-		vehicles, err := models.GetVehiclesWithFilters(vehicleType, brand, year)
-		if err != nil {
-			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch vehicles. Try again later."})
-			return
-		}
-		context.JSON(http.StatusOK, vehicles)
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	vehicle, err := h.Repos.Vehicles.GetByID(ctx, vehicleId)
+
+	if abortOnContextError(context, ctx, err) {
 		return
 	}
 
-	// Otherwise, get all vehicles
-	vehicles, err := models.GetAllVehicles()
-	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch vehicles. Try again later."})
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
 		return
 	}
-	context.JSON(http.StatusOK, vehicles)
-}
-
-func getVehicle(context *gin.Context) {
-	vehicleId, err := strconv.ParseInt(context.Param("id"), 10, 64)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch vehicle."})
 		return
 	}
 
-	vehicle, err := models.GetVehicleByID(vehicleId)
+	priceHistory, err := h.Repos.Vehicles.PriceHistory(ctx, vehicleId)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch vehicle."})
 		return
 	}
 
-	context.JSON(http.StatusOK, vehicle)
+	context.JSON(http.StatusOK, vehicleWithPrices{Vehicle: *vehicle, PriceHistory: priceHistory})
 }
 
-func createVehicle(context *gin.Context) {
+// vehicleWithPrices is a vehicle with its asking price history, which only
+// GET /vehicles/:id includes.
+type vehicleWithPrices struct {
+	models.Vehicle
+	PriceHistory []models.PriceChange
+}
+
+func (h *handler) createVehicle(context *gin.Context) {
 	log.Println("Starting createVehicle handler")
 
 	var vehicle models.Vehicle
@@ -76,19 +74,69 @@ func createVehicle(context *gin.Context) {
 
 	log.Printf("Parsed vehicle data: %+v", vehicle)
 
-	err = vehicle.Save()
+	if vehicle.Status == "" {
+		vehicle.Status = models.StatusIncoming
+	}
+
+	if !vehicle.Status.Valid() {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid vehicle status."})
+		return
+	}
+
+	if !vehicle.Status.Initial() {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "A new vehicle must be incoming, in_preparation or available; it only becomes reserved or sold through a reservation or sale."})
+		return
+	}
+
+	vehicle.Normalize()
+	engine.Complete(&vehicle)
+
+	// With ?decode_vin=true a missing brand and year are taken from the VIN.
+	var decoding *vin.Decoding
+	if context.Query("decode_vin") == "true" {
+		decoding, err = h.prefillFromVIN(&vehicle)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Could not decode the VIN: " + err.Error() + "."})
+			return
+		}
+	}
+
+	if err := vehicle.Validate(); err != nil {
+		response := gin.H{"message": "Invalid vehicle data.", "errors": err}
+		if decoding != nil {
+			response["vin_decoding"] = decoding
+		}
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Vehicles.Create(ctx, &vehicle)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
 
 	if err != nil {
 		log.Printf("Database save error: %v", err)
+		if respondDuplicateVehicle(context, err) || respondInvalidVehicle(context, err) {
+			return
+		}
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create vehicle. Try again later."})
 		return
 	}
 
 	log.Println("Vehicle created successfully")
-	context.JSON(http.StatusCreated, gin.H{"message": "Vehicle created!", "vehicle": vehicle})
+	response := gin.H{"message": "Vehicle created!", "vehicle": vehicle}
+	if decoding != nil {
+		response["vin_decoding"] = decoding
+	}
+	context.JSON(http.StatusCreated, response)
 }
 
-func updateVehicle(context *gin.Context) {
+func (h *handler) updateVehicle(context *gin.Context) {
 	vehicleId, err := strconv.ParseInt(context.Param("id"), 10, 64)
 
 	if err != nil {
@@ -104,8 +152,52 @@ func updateVehicle(context *gin.Context) {
 		return
 	}
 
+	updateVehicle.Normalize()
+	engine.Complete(&updateVehicle)
+	if err := updateVehicle.Validate(); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid vehicle data.", "errors": err})
+		return
+	}
+
 	updateVehicle.ID = int(vehicleId)
-	err = updateVehicle.UpdateVehicle()
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	if updateVehicle.Status != "" {
+		current, err := h.Repos.Vehicles.GetByID(ctx, vehicleId)
+		if abortOnContextError(context, ctx, err) {
+			return
+		}
+
+		if err == nil && current.Status != updateVehicle.Status {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Use POST /vehicles/:id/transitions to change the vehicle status."})
+			return
+		}
+	}
+
+	err = h.Repos.Vehicles.Update(ctx, &updateVehicle)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	var consignedErr *models.ConsignedPurchasePriceError
+	if errors.As(err, &consignedErr) {
+		context.JSON(http.StatusConflict, gin.H{
+			"message":    "A consigned vehicle cannot have a purchase price; its cost is what the owner is owed.",
+			"vehicle_id": consignedErr.VehicleID,
+		})
+		return
+	}
+
+	if respondDuplicateVehicle(context, err) || respondInvalidVehicle(context, err) {
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update vehicle."})
@@ -115,26 +207,177 @@ func updateVehicle(context *gin.Context) {
 
 }
 
-func deleteVehicle(context *gin.Context) {
+func (h *handler) deleteVehicle(context *gin.Context) {
 	vehicleID, err := strconv.ParseInt(context.Param("id"), 10, 64)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
 		return
 	}
 
-	vehicle, err := models.GetVehicleByID(vehicleID)
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
 
-	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the vehicle."})
+	// The media and document rows go with the vehicle; their files are
+	// removed afterwards.
+	gallery, err := h.Repos.Media.ListByVehicle(ctx, vehicleID)
+	var documents []models.VehicleDocument
+	if err == nil {
+		documents, err = h.Repos.Documents.ListByVehicle(ctx, vehicleID)
+	}
+	if err == nil {
+		err = h.Repos.Vehicles.Delete(ctx, vehicleID)
+	}
+
+	if abortOnContextError(context, ctx, err) {
 		return
 	}
 
-	err = vehicle.Delete()
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	if errors.Is(err, models.ErrInUse) {
+		context.JSON(http.StatusConflict, gin.H{"message": "Vehicle has sales, reservations, consignments, test drives or a trade-in record and cannot be deleted."})
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the vehicle."})
 		return
 	}
 
+	for _, item := range gallery {
+		h.deleteMediaFiles(item.StorageKey)
+	}
+	for _, document := range documents {
+		h.deleteDocumentFile(document.StorageKey)
+	}
 	context.JSON(http.StatusOK, gin.H{"message": "Vehicle deleted successfully!"})
 }
+
+func (h *handler) getVehicleTransitions(context *gin.Context) {
+	vehicleId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	ctx, cancel := h.readContext(context)
+	defer cancel()
+
+	history, err := h.Repos.Vehicles.StatusHistory(ctx, vehicleId)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the vehicle status history."})
+		return
+	}
+
+	context.JSON(http.StatusOK, history)
+}
+
+func (h *handler) createVehicleTransition(context *gin.Context) {
+	vehicleId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse vehicle id."})
+		return
+	}
+
+	var change models.VehicleStatusChange
+	err = context.ShouldBindJSON(&change)
+
+	if err != nil {
+		log.Printf("JSON binding error: %v", err)
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		return
+	}
+
+	// Sales move vehicles to sold so that every sold vehicle has a sale record.
+	if change.To == models.StatusSold {
+		context.JSON(http.StatusConflict, gin.H{"message": "Vehicles are marked as sold by creating a sale."})
+		return
+	}
+
+	if change.To == models.StatusReserved {
+		context.JSON(http.StatusConflict, gin.H{"message": "Vehicles are reserved by creating a reservation."})
+		return
+	}
+
+	change.VehicleID = vehicleId
+
+	ctx, cancel := h.writeContext(context)
+	defer cancel()
+
+	err = h.Repos.Vehicles.Transition(ctx, &change)
+
+	if abortOnContextError(context, ctx, err) {
+		return
+	}
+
+	if err != nil {
+		log.Printf("Status transition error: %v", err)
+		respondTransitionError(context, err, "Could not change the vehicle status.")
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Vehicle status changed!", "transition": change})
+}
+
+// respondTransitionError maps the errors of a vehicle status change to a response.
+func respondTransitionError(context *gin.Context, err error, message string) {
+	var statusErr *models.InvalidStatusError
+	var transitionErr *models.InvalidTransitionError
+	var reservedErr *models.VehicleReservedError
+
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		context.JSON(http.StatusNotFound, gin.H{"message": "Vehicle not found."})
+	case errors.As(err, &reservedErr):
+		respondVehicleReserved(context, reservedErr)
+	case errors.As(err, &statusErr):
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid vehicle status."})
+	case errors.As(err, &transitionErr):
+		context.JSON(http.StatusConflict, gin.H{
+			"message": "Vehicle status transition not allowed.",
+			"from":    transitionErr.From,
+			"to":      transitionErr.To,
+		})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": message})
+	}
+}
+
+// respondDuplicateVehicle answers 409 when err is a VIN or plate collision.
+func respondDuplicateVehicle(context *gin.Context, err error) bool {
+	var duplicateErr *models.DuplicateVehicleError
+	if !errors.As(err, &duplicateErr) {
+		return false
+	}
+
+	context.JSON(http.StatusConflict, gin.H{
+		"message": "Another vehicle already has this " + duplicateErr.Field + ".",
+		"field":   duplicateErr.Field,
+	})
+	return true
+}
+
+// respondInvalidVehicle answers 400 when the store rejected a field, such as
+// a previous owner that does not exist.
+func respondInvalidVehicle(context *gin.Context, err error) bool {
+	var invalid models.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return false
+	}
+
+	context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid vehicle data.", "errors": invalid})
+	return true
+}
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Stand/models"
+	"github.com/Stand/vin"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) decodeVIN(context *gin.Context) {
+	decoding, err := h.VINDecoder.Decode(context.Param("vin"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid VIN: " + err.Error() + "."})
+		return
+	}
+
+	// Optional values to compare against, as they would be submitted on creation.
+	var submitted struct {
+		Brand string `form:"brand"`
+		Year  int    `form:"year"`
+	}
+	if err := context.ShouldBindQuery(&submitted); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid year format."})
+		return
+	}
+	decoding.Compare(submitted.Brand, submitted.Year)
+
+	context.JSON(http.StatusOK, decoding)
+}
+
+// prefillFromVIN decodes the vehicle's VIN, fills in a missing brand and year
+// and flags where the submitted values disagree with the VIN.
+func (h *handler) prefillFromVIN(vehicle *models.Vehicle) (*vin.Decoding, error) {
+	decoding, err := h.VINDecoder.Decode(vehicle.VIN)
+	if err != nil {
+		return nil, err
+	}
+
+	decoding.Compare(vehicle.Brand, vehicle.Year)
+
+	if vehicle.Brand == "" {
+		vehicle.Brand = decoding.SuggestedBrand
+	}
+	if vehicle.Year == 0 {
+		vehicle.Year = decoding.SuggestedYear
+	}
+
+	return decoding, nil
+}
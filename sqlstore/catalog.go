@@ -0,0 +1,191 @@
+package sqlstore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Stand/models"
+)
+
+type catalogRepository struct {
+	*Store
+}
+
+// catalogCache keeps the catalogue between writes. The catalogue is only
+// ever added to, so its row counts tell whether it changed since it was
+// read, also when another replica added to it.
+type catalogCache struct {
+	mu      sync.Mutex
+	counts  [4]int64
+	catalog *models.Catalog
+}
+
+// loadCatalog returns the whole catalogue as seen by t, reading it again only
+// if rows were added since it was last read. It is small enough to resolve
+// names in Go, where they can be folded like the search filters.
+func (s *Store) loadCatalog(t *tx) (*models.Catalog, error) {
+	var counts [4]int64
+	err := t.queryRow(`SELECT (SELECT COUNT(*) FROM brands), (SELECT COUNT(*) FROM brand_aliases),
+		(SELECT COUNT(*) FROM vehicle_models), (SELECT COUNT(*) FROM vehicle_model_aliases)`).
+		Scan(&counts[0], &counts[1], &counts[2], &counts[3])
+	if err != nil {
+		return nil, err
+	}
+
+	s.catalogCache.mu.Lock()
+	cached := s.catalogCache.catalog
+	fresh := cached != nil && s.catalogCache.counts == counts
+	s.catalogCache.mu.Unlock()
+	if fresh {
+		return cached, nil
+	}
+
+	catalog, err := readCatalog(t)
+	if err != nil {
+		return nil, err
+	}
+
+	s.catalogCache.mu.Lock()
+	s.catalogCache.counts, s.catalogCache.catalog = counts, catalog
+	s.catalogCache.mu.Unlock()
+	return catalog, nil
+}
+
+func readCatalog(t *tx) (*models.Catalog, error) {
+	var brands []models.Brand
+	rows, err := t.query("SELECT id, name FROM brands")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		brand := models.Brand{Aliases: []string{}}
+		if err := rows.Scan(&brand.ID, &brand.Name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		brands = append(brands, brand)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var brandModels []models.BrandModel
+	rows, err = t.query("SELECT id, brand_id, name FROM vehicle_models")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		model := models.BrandModel{Aliases: []string{}}
+		if err := rows.Scan(&model.ID, &model.BrandID, &model.Name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		brandModels = append(brandModels, model)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	brandAliases, err := loadAliases(t, "SELECT brand_id, alias FROM brand_aliases ORDER BY alias")
+	if err != nil {
+		return nil, err
+	}
+	modelAliases, err := loadAliases(t, "SELECT model_id, alias FROM vehicle_model_aliases ORDER BY alias")
+	if err != nil {
+		return nil, err
+	}
+	for i := range brands {
+		brands[i].Aliases = append(brands[i].Aliases, brandAliases[brands[i].ID]...)
+	}
+	for i := range brandModels {
+		brandModels[i].Aliases = append(brandModels[i].Aliases, modelAliases[brandModels[i].ID]...)
+	}
+
+	return models.NewCatalog(brands, brandModels), nil
+}
+
+func loadAliases(t *tx, query string) (map[int64][]string, error) {
+	rows, err := t.query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := map[int64][]string{}
+	for rows.Next() {
+		var id int64
+		var alias string
+		if err := rows.Scan(&id, &alias); err != nil {
+			return nil, err
+		}
+		aliases[id] = append(aliases[id], alias)
+	}
+	return aliases, rows.Err()
+}
+
+func (r *catalogRepository) Catalog(ctx context.Context) (*models.Catalog, error) {
+	var catalog *models.Catalog
+	err := r.inTx(ctx, func(t *tx) error {
+		var err error
+		catalog, err = r.loadCatalog(t)
+		return err
+	})
+	return catalog, err
+}
+
+func (r *catalogRepository) CreateBrand(ctx context.Context, brand *models.Brand) error {
+	return r.inTx(ctx, func(t *tx) error {
+		catalog, err := r.loadCatalog(t)
+		if err != nil {
+			return err
+		}
+		if err := catalog.CheckBrand(brand); err != nil {
+			return err
+		}
+
+		err = t.queryRow("INSERT INTO brands (name) VALUES ($1) RETURNING id", brand.Name).Scan(&brand.ID)
+		if r.dialect.IsUniqueViolation(err) {
+			// Added concurrently under the same name.
+			return &models.CatalogConflictError{Name: brand.Name, Existing: brand.Name}
+		}
+		if err != nil {
+			return err
+		}
+		return insertAliases(t, "brand_aliases", "brand_id", brand.ID, brand.Aliases)
+	})
+}
+
+func (r *catalogRepository) CreateModel(ctx context.Context, model *models.BrandModel) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var brandID int64
+		err := t.queryRow("SELECT id FROM brands WHERE id = $1"+r.dialect.ForUpdate(), model.BrandID).Scan(&brandID)
+		if err != nil {
+			return notFound(err)
+		}
+
+		catalog, err := r.loadCatalog(t)
+		if err != nil {
+			return err
+		}
+		if err := catalog.CheckModel(model); err != nil {
+			return err
+		}
+
+		err = t.queryRow("INSERT INTO vehicle_models (brand_id, name) VALUES ($1, $2) RETURNING id", model.BrandID, model.Name).Scan(&model.ID)
+		if err != nil {
+			return err
+		}
+		return insertAliases(t, "vehicle_model_aliases", "model_id", model.ID, model.Aliases)
+	})
+}
+
+func insertAliases(t *tx, table, column string, id int64, aliases []string) error {
+	for _, alias := range aliases {
+		if _, err := t.exec("INSERT INTO "+table+" ("+column+", alias) VALUES ($1, $2)", id, alias); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/Stand/models"
+)
+
+const clientColumns = "id, name, email, phone"
+
+type clientRepository struct {
+	*Store
+}
+
+func scanClient(row scanner, client *models.Client) error {
+	return row.Scan(&client.ID, &client.Name, &client.Email, &client.Phone)
+}
+
+func (r *clientRepository) Create(ctx context.Context, c *models.Client) error {
+	query := `INSERT INTO clients (name, email, phone)
+	VALUES ($1, $2, $3) RETURNING id`
+
+	return r.queryRow(ctx, query, c.Name, c.Email, c.Phone).Scan(&c.ID)
+}
+
+func (r *clientRepository) GetAll(ctx context.Context) ([]models.Client, error) {
+	rows, err := r.query(ctx, "SELECT "+clientColumns+" FROM clients")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients []models.Client
+
+	for rows.Next() {
+		var client models.Client
+		if err := scanClient(rows, &client); err != nil {
+			return clients, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+func (r *clientRepository) GetByID(ctx context.Context, id int64) (*models.Client, error) {
+	var client models.Client
+	err := scanClient(r.queryRow(ctx, "SELECT "+clientColumns+" FROM clients WHERE id=$1", id), &client)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return &client, nil
+}
+
+func (r *clientRepository) Update(ctx context.Context, c *models.Client) error {
+	query := `
+	UPDATE clients
+	SET name=$1,email=$2,phone=$3
+	WHERE id=$4`
+
+	return affected(r.exec(ctx, query, c.Name, c.Email, c.Phone, c.ID))
+}
+
+func (r *clientRepository) Delete(ctx context.Context, id int64) error {
+	return r.deleteByID(ctx, "clients", id)
+}
@@ -0,0 +1,189 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+const consignmentColumns = "id, vehicle_id, owner_id, minimum_price, commission_type, commission_value, " +
+	"status, created_at, closed_at, sale_id"
+
+type consignmentRepository struct {
+	*Store
+}
+
+// consignmentFields returns the scan destinations matching consignmentColumns.
+func consignmentFields(consignment *models.Consignment) []interface{} {
+	return []interface{}{
+		&consignment.ID, &consignment.VehicleID, &consignment.OwnerID, &consignment.MinimumPrice,
+		&consignment.CommissionType, &consignment.CommissionValue,
+		&consignment.Status, &consignment.CreatedAt, nullTime{&consignment.ClosedAt}, nullInt64{&consignment.SaleID},
+	}
+}
+
+func (r *consignmentRepository) Create(ctx context.Context, consignment *models.Consignment) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var status models.VehicleStatus
+		var purchasePrice float64
+		err := t.queryRow("SELECT status, purchase_price FROM vehicles WHERE id = $1"+r.dialect.ForUpdate(), consignment.VehicleID).
+			Scan(&status, &purchasePrice)
+		if err != nil {
+			return notFound(err)
+		}
+		if status == models.StatusSold {
+			return &models.VehicleAlreadySoldError{VehicleID: consignment.VehicleID}
+		}
+		if purchasePrice != 0 {
+			return &models.ConsignedPurchasePriceError{VehicleID: consignment.VehicleID}
+		}
+
+		existing, err := activeConsignment(t, consignment.VehicleID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return &models.VehicleConsignedError{VehicleID: consignment.VehicleID, ConsignmentID: existing.ID}
+		}
+
+		var ownerID int64
+		err = t.queryRow("SELECT id FROM clients WHERE id = $1", consignment.OwnerID).Scan(&ownerID)
+		if err != nil {
+			return notFound(err)
+		}
+
+		consignment.Status = models.ConsignmentActive
+		consignment.CreatedAt = time.Now().UTC()
+		consignment.ClosedAt = nil
+		consignment.SaleID = nil
+
+		query := `
+		INSERT INTO consignments (vehicle_id, owner_id, minimum_price, commission_type, commission_value, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+		err = t.queryRow(query, consignment.VehicleID, consignment.OwnerID, consignment.MinimumPrice,
+			consignment.CommissionType, consignment.CommissionValue, consignment.Status, consignment.CreatedAt).Scan(&consignment.ID)
+		if err != nil {
+			return err
+		}
+
+		slog.Debug("Consignment created", "consignment_id", consignment.ID, "vehicle_id", consignment.VehicleID, "owner_id", consignment.OwnerID)
+		return nil
+	})
+}
+
+func (r *consignmentRepository) GetAll(ctx context.Context) ([]models.Consignment, error) {
+	rows, err := r.query(ctx, "SELECT "+consignmentColumns+" FROM consignments ORDER BY created_at DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var consignments []models.Consignment
+	for rows.Next() {
+		var consignment models.Consignment
+		if err := rows.Scan(consignmentFields(&consignment)...); err != nil {
+			return nil, err
+		}
+		consignments = append(consignments, consignment)
+	}
+
+	return consignments, rows.Err()
+}
+
+func (r *consignmentRepository) GetByID(ctx context.Context, id int64) (*models.Consignment, error) {
+	var consignment models.Consignment
+	err := r.queryRow(ctx, "SELECT "+consignmentColumns+" FROM consignments WHERE id = $1", id).
+		Scan(consignmentFields(&consignment)...)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &consignment, nil
+}
+
+func (r *consignmentRepository) Withdraw(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(t *tx) error {
+		// Lock the vehicle first, the same order in which sales lock them.
+		var vehicleID int64
+		err := t.queryRow("SELECT vehicle_id FROM consignments WHERE id = $1", id).Scan(&vehicleID)
+		if err != nil {
+			return notFound(err)
+		}
+		var status models.VehicleStatus
+		err = t.queryRow("SELECT status FROM vehicles WHERE id = $1"+t.dialect.ForUpdate(), vehicleID).Scan(&status)
+		if err != nil {
+			return notFound(err)
+		}
+
+		var consignment models.Consignment
+		err = t.queryRow("SELECT "+consignmentColumns+" FROM consignments WHERE id = $1", id).
+			Scan(consignmentFields(&consignment)...)
+		if err != nil {
+			return notFound(err)
+		}
+		if consignment.Status != models.ConsignmentActive {
+			return &models.ConsignmentNotActiveError{ConsignmentID: id, Status: consignment.Status}
+		}
+
+		_, err = t.exec("UPDATE consignments SET status = $1, closed_at = $2 WHERE id = $3",
+			models.ConsignmentWithdrawn, time.Now().UTC(), id)
+		return err
+	})
+}
+
+func (r *consignmentRepository) Settlement(ctx context.Context, id int64) (*models.ConsignmentSettlement, error) {
+	query := "SELECT " + prefixed("co", consignmentColumns) + `, co.commission, co.amount_owed,
+		s.sale_date, s.price, c.id, c.name, c.email, c.phone, ` + prefixed("v", vehicleColumns) + `
+	FROM consignments co
+	JOIN clients c ON c.id = co.owner_id
+	JOIN vehicles v ON v.id = co.vehicle_id
+	LEFT JOIN sales s ON s.id = co.sale_id
+	WHERE co.id = $1`
+
+	var settlement models.ConsignmentSettlement
+	var commission, amountOwed, price sql.NullFloat64
+	var saleDate sql.NullTime
+	fields := append(consignmentFields(&settlement.Consignment), &commission, &amountOwed, &saleDate, &price,
+		&settlement.Owner.ID, &settlement.Owner.Name, &settlement.Owner.Email, &settlement.Owner.Phone)
+	if err := r.queryRow(ctx, query, id).Scan(append(fields, vehicleFields(&settlement.Vehicle)...)...); err != nil {
+		return nil, notFound(err)
+	}
+
+	if settlement.Consignment.Status != models.ConsignmentSold {
+		return nil, &models.ConsignmentNotActiveError{ConsignmentID: id, Status: settlement.Consignment.Status}
+	}
+	settlement.SaleDate = saleDate.Time
+	settlement.SalePrice = price.Float64
+	settlement.Commission = commission.Float64
+	settlement.AmountOwed = amountOwed.Float64
+	return &settlement, nil
+}
+
+// activeConsignment returns the active consignment of a vehicle locked by
+// the caller, or nil if there is none.
+func activeConsignment(t *tx, vehicleID int64) (*models.Consignment, error) {
+	var consignment models.Consignment
+	err := t.queryRow("SELECT "+consignmentColumns+" FROM consignments WHERE vehicle_id = $1 AND status = $2",
+		vehicleID, models.ConsignmentActive).Scan(consignmentFields(&consignment)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &consignment, nil
+}
+
+// sellConsignment closes a consignment as sold by sale, recording the
+// commission and what is owed to the owner.
+func sellConsignment(t *tx, consignment *models.Consignment, sale *models.Sale) error {
+	closedAt := time.Now().UTC()
+	_, err := t.exec(`UPDATE consignments SET status = $1, closed_at = $2, sale_id = $3, commission = $4, amount_owed = $5
+		WHERE id = $6`, models.ConsignmentSold, closedAt, sale.ID,
+		consignment.Commission(sale.Price), consignment.AmountOwed(sale.Price), consignment.ID)
+	return err
+}
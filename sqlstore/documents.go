@@ -0,0 +1,146 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+const documentColumns = "id, vehicle_id, type, file_name, content_type, size, issued_on, expires_on, flagged_at, storage_key, created_at"
+
+type documentRepository struct {
+	*Store
+}
+
+// documentFields returns the scan destinations matching documentColumns.
+func documentFields(document *models.VehicleDocument) []interface{} {
+	return []interface{}{
+		&document.ID, &document.VehicleID, &document.Type, &document.FileName, &document.ContentType, &document.Size,
+		nullTime{&document.IssuedOn}, nullTime{&document.ExpiresOn}, nullTime{&document.FlaggedAt},
+		&document.StorageKey, &document.CreatedAt,
+	}
+}
+
+func (r *documentRepository) Create(ctx context.Context, document *models.VehicleDocument) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var exists int64
+		err := t.queryRow("SELECT id FROM vehicles WHERE id = $1", document.VehicleID).Scan(&exists)
+		if err != nil {
+			return notFound(err)
+		}
+
+		document.CreatedAt = time.Now().UTC()
+		document.FlaggedAt = nil
+
+		query := `
+		INSERT INTO vehicle_documents (vehicle_id, type, file_name, content_type, size, issued_on, expires_on, storage_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+		return t.queryRow(query, document.VehicleID, document.Type, document.FileName, document.ContentType, document.Size,
+			document.IssuedOn, document.ExpiresOn, document.StorageKey, document.CreatedAt).Scan(&document.ID)
+	})
+}
+
+func (r *documentRepository) ListByVehicle(ctx context.Context, vehicleID int64) ([]models.VehicleDocument, error) {
+	var exists int64
+	err := r.queryRow(ctx, "SELECT id FROM vehicles WHERE id = $1", vehicleID).Scan(&exists)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	// Documents without expiry come last on every database.
+	rows, err := r.query(ctx, "SELECT "+documentColumns+" FROM vehicle_documents WHERE vehicle_id = $1"+
+		" ORDER BY type, expires_on IS NULL, expires_on DESC, id DESC", vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []models.VehicleDocument{}
+	for rows.Next() {
+		var document models.VehicleDocument
+		if err := rows.Scan(documentFields(&document)...); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+func (r *documentRepository) GetByID(ctx context.Context, vehicleID, id int64) (*models.VehicleDocument, error) {
+	var document models.VehicleDocument
+	err := r.queryRow(ctx, "SELECT "+documentColumns+" FROM vehicle_documents WHERE id = $1 AND vehicle_id = $2", id, vehicleID).
+		Scan(documentFields(&document)...)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &document, nil
+}
+
+func (r *documentRepository) Delete(ctx context.Context, vehicleID, id int64) error {
+	return affected(r.exec(ctx, "DELETE FROM vehicle_documents WHERE id = $1 AND vehicle_id = $2", id, vehicleID))
+}
+
+func (r *documentRepository) Expiring(ctx context.Context, by time.Time) ([]models.ExpiringDocument, error) {
+	var documents []models.ExpiringDocument
+	err := r.inTx(ctx, func(t *tx) error {
+		var err error
+		documents, err = expiringDocuments(t, by, false)
+		return err
+	})
+	return documents, err
+}
+
+func (r *documentRepository) FlagExpiring(ctx context.Context, by, now time.Time) ([]models.ExpiringDocument, error) {
+	var flagged []models.ExpiringDocument
+	err := r.inTx(ctx, func(t *tx) error {
+		documents, err := expiringDocuments(t, by, true)
+		if err != nil {
+			return err
+		}
+
+		flaggedAt := now.UTC()
+		for i := range documents {
+			if _, err := t.exec("UPDATE vehicle_documents SET flagged_at = $1 WHERE id = $2", flaggedAt, documents[i].ID); err != nil {
+				return err
+			}
+			documents[i].FlaggedAt = &flaggedAt
+		}
+		flagged = documents
+		return nil
+	})
+	return flagged, err
+}
+
+// expiringDocuments selects the documents of vehicles in stock that expire
+// by the given time and have not been superseded, optionally only those not
+// flagged yet.
+func expiringDocuments(t *tx, by time.Time, unflagged bool) ([]models.ExpiringDocument, error) {
+	c := &conditions{}
+	c.add("d.expires_on <= " + c.arg(by.UTC()))
+	c.in("v.status", values(models.InStockStatuses))
+	if unflagged {
+		c.add("d.flagged_at IS NULL")
+	}
+	// Mirrors VehicleDocument.Supersedes.
+	c.add(`NOT EXISTS (SELECT 1 FROM vehicle_documents o
+		WHERE o.vehicle_id = d.vehicle_id AND o.type = d.type AND o.type <> ` + c.arg(models.DocumentOther) + `
+		AND o.expires_on IS NOT NULL AND (o.expires_on > d.expires_on OR (o.expires_on = d.expires_on AND o.id > d.id)))`)
+
+	rows, err := t.query("SELECT "+prefixed("d", documentColumns)+", "+prefixed("v", vehicleColumns)+
+		" FROM vehicle_documents d JOIN vehicles v ON v.id = d.vehicle_id"+c.where()+" ORDER BY d.expires_on, d.id", c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []models.ExpiringDocument{}
+	for rows.Next() {
+		var document models.ExpiringDocument
+		if err := rows.Scan(append(documentFields(&document.VehicleDocument), vehicleFields(&document.Vehicle)...)...); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
@@ -0,0 +1,104 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+const expenseColumns = "id, vehicle_id, category, description, amount, incurred_on, created_at"
+
+type expenseRepository struct {
+	*Store
+}
+
+func scanExpense(row scanner, expense *models.VehicleExpense) error {
+	return row.Scan(&expense.ID, &expense.VehicleID, &expense.Category, &expense.Description,
+		&expense.Amount, &expense.IncurredOn, &expense.CreatedAt)
+}
+
+func (r *expenseRepository) Create(ctx context.Context, expense *models.VehicleExpense) error {
+	expense.CreatedAt = time.Now()
+	if expense.IncurredOn.IsZero() {
+		expense.IncurredOn = expense.CreatedAt
+	}
+
+	query := `
+	INSERT INTO vehicle_expenses (vehicle_id, category, description, amount, incurred_on, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err := r.queryRow(ctx, query, expense.VehicleID, expense.Category, expense.Description,
+		expense.Amount, expense.IncurredOn, expense.CreatedAt).Scan(&expense.ID)
+	if r.dialect.IsForeignKeyViolation(err) {
+		return models.ErrNotFound
+	}
+	return err
+}
+
+func (r *expenseRepository) ListByVehicle(ctx context.Context, vehicleID int64) ([]models.VehicleExpense, error) {
+	var exists int64
+	err := r.queryRow(ctx, "SELECT id FROM vehicles WHERE id = $1", vehicleID).Scan(&exists)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return r.expenses(ctx, vehicleID)
+}
+
+func (r *expenseRepository) Delete(ctx context.Context, vehicleID, id int64) error {
+	return affected(r.exec(ctx, "DELETE FROM vehicle_expenses WHERE id = $1 AND vehicle_id = $2", id, vehicleID))
+}
+
+// expenses returns a vehicle's ledger, oldest first.
+func (s *Store) expenses(ctx context.Context, vehicleID int64) ([]models.VehicleExpense, error) {
+	rows, err := s.query(ctx, "SELECT "+expenseColumns+" FROM vehicle_expenses WHERE vehicle_id = $1 ORDER BY incurred_on, id", vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expenses := []models.VehicleExpense{}
+	for rows.Next() {
+		var expense models.VehicleExpense
+		if err := scanExpense(rows, &expense); err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, expense)
+	}
+	return expenses, rows.Err()
+}
+
+func (r *vehicleRepository) Profitability(ctx context.Context, vehicleID int64) (*models.Profitability, error) {
+	vehicle, err := r.GetByID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses, err := r.expenses(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var salePrice *float64
+	var price float64
+	err = r.queryRow(ctx, "SELECT price FROM sales WHERE vehicle_id = $1", vehicleID).Scan(&price)
+	if err == nil {
+		salePrice = &price
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var consignment models.Consignment
+	err = r.queryRow(ctx, "SELECT "+consignmentColumns+" FROM consignments WHERE vehicle_id = $1 AND status IN ($2, $3)",
+		vehicleID, models.ConsignmentActive, models.ConsignmentSold).Scan(consignmentFields(&consignment)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ComputeProfitability(vehicle, expenses, salePrice, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return models.ComputeProfitability(vehicle, expenses, salePrice, &consignment), nil
+}
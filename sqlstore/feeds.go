@@ -0,0 +1,30 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/Stand/models"
+)
+
+type feedRepository struct {
+	*Store
+}
+
+func (r *feedRepository) Record(ctx context.Context, export *models.FeedExport) error {
+	query := `
+	INSERT INTO feed_exports (exported_at, since, listings, removed)
+	VALUES ($1, $2, $3, $4) RETURNING id`
+
+	return r.queryRow(ctx, query, export.ExportedAt.UTC(), export.Since, export.Listings, export.Removed).Scan(&export.ID)
+}
+
+func (r *feedRepository) Last(ctx context.Context) (*models.FeedExport, error) {
+	query := "SELECT id, exported_at, since, listings, removed FROM feed_exports ORDER BY exported_at DESC, id DESC LIMIT 1"
+
+	var export models.FeedExport
+	err := r.queryRow(ctx, query).Scan(&export.ID, &export.ExportedAt, nullTime{&export.Since}, &export.Listings, &export.Removed)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &export, nil
+}
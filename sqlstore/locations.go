@@ -0,0 +1,108 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Stand/models"
+)
+
+const locationQuery = "SELECT id, name, address FROM locations"
+
+type locationRepository struct {
+	*Store
+}
+
+func (r *locationRepository) Create(ctx context.Context, location *models.Location) error {
+	err := r.queryRow(ctx, "INSERT INTO locations (name, address) VALUES ($1, $2) RETURNING id",
+		location.Name, location.Address).Scan(&location.ID)
+	return r.locationWriteError(err, location)
+}
+
+func (r *locationRepository) GetAll(ctx context.Context) ([]models.Location, error) {
+	return scanLocations(r.query(ctx, locationQuery+" ORDER BY name, id"))
+}
+
+func scanLocations(rows *sql.Rows, err error) ([]models.Location, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []models.Location{}
+	for rows.Next() {
+		var location models.Location
+		if err := rows.Scan(&location.ID, &location.Name, &location.Address); err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	return locations, rows.Err()
+}
+
+func (r *locationRepository) GetByID(ctx context.Context, id int64) (*models.Location, error) {
+	var location models.Location
+	err := r.queryRow(ctx, locationQuery+" WHERE id = $1", id).
+		Scan(&location.ID, &location.Name, &location.Address)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &location, nil
+}
+
+func (r *locationRepository) Update(ctx context.Context, location *models.Location) error {
+	err := affected(r.exec(ctx, "UPDATE locations SET name = $1, address = $2 WHERE id = $3",
+		location.Name, location.Address, location.ID))
+	return r.locationWriteError(err, location)
+}
+
+func (r *locationRepository) Delete(ctx context.Context, id int64) error {
+	return r.deleteByID(ctx, "locations", id)
+}
+
+func (r *locationRepository) Stock(ctx context.Context) ([]models.LocationStock, error) {
+	var stock []models.LocationStock
+	err := r.inTx(ctx, func(t *tx) error {
+		locations, err := scanLocations(t.query(locationQuery + " ORDER BY name, id"))
+		if err != nil {
+			return err
+		}
+
+		c := &conditions{}
+		c.in("status", values(models.InStockStatuses))
+		rows, err := t.query("SELECT location_id, status, COUNT(*) FROM vehicles"+c.where()+" GROUP BY location_id, status", c.args...)
+		if err != nil {
+			return err
+		}
+		var counts []models.StockCount
+		for rows.Next() {
+			var count models.StockCount
+			if err := rows.Scan(nullInt64{&count.LocationID}, &count.Status, &count.Count); err != nil {
+				rows.Close()
+				return err
+			}
+			counts = append(counts, count)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		inTransit, err := listTransfers(t, " WHERE status = $1", models.TransferInTransit)
+		if err != nil {
+			return err
+		}
+
+		stock = models.CountLocationStock(locations, counts, inTransit)
+		return nil
+	})
+	return stock, err
+}
+
+// locationWriteError maps a unique violation on the name.
+func (s *Store) locationWriteError(err error, location *models.Location) error {
+	if err != nil && s.dialect.IsUniqueViolation(err) {
+		return &models.DuplicateLocationError{Name: location.Name}
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+const mediaColumns = "id, vehicle_id, file_name, content_type, size, width, height, position, cover, storage_key, created_at"
+
+type mediaRepository struct {
+	*Store
+}
+
+func scanMedia(row scanner, media *models.VehicleMedia) error {
+	return row.Scan(&media.ID, &media.VehicleID, &media.FileName, &media.ContentType, &media.Size,
+		&media.Width, &media.Height, &media.Position, &media.Cover, &media.StorageKey, &media.CreatedAt)
+}
+
+// lockGallery locks the vehicle so that concurrent changes to its gallery
+// are serialised, and marks it as changed for the incremental feed.
+func (s *Store) lockGallery(t *tx, vehicleID int64) error {
+	var id int64
+	err := t.queryRow("SELECT id FROM vehicles WHERE id = $1"+s.dialect.ForUpdate(), vehicleID).Scan(&id)
+	if err != nil {
+		return notFound(err)
+	}
+	_, err = t.exec("UPDATE vehicles SET updated_at = $1 WHERE id = $2", updateTime(), vehicleID)
+	return err
+}
+
+func (r *mediaRepository) Create(ctx context.Context, media *models.VehicleMedia) error {
+	return r.inTx(ctx, func(t *tx) error {
+		if err := r.lockGallery(t, media.VehicleID); err != nil {
+			return err
+		}
+
+		var count, covers int
+		err := t.queryRow("SELECT COUNT(*), COUNT(CASE WHEN cover THEN 1 END) FROM vehicle_media WHERE vehicle_id = $1",
+			media.VehicleID).Scan(&count, &covers)
+		if err != nil {
+			return err
+		}
+
+		media.Position = count + 1
+		media.Cover = covers == 0
+		media.CreatedAt = time.Now()
+
+		query := `
+		INSERT INTO vehicle_media (vehicle_id, file_name, content_type, size, width, height, position, cover, storage_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+		return t.queryRow(query, media.VehicleID, media.FileName, media.ContentType, media.Size, media.Width, media.Height,
+			media.Position, media.Cover, media.StorageKey, media.CreatedAt).Scan(&media.ID)
+	})
+}
+
+func (r *mediaRepository) ListByVehicle(ctx context.Context, vehicleID int64) ([]models.VehicleMedia, error) {
+	var exists int64
+	err := r.queryRow(ctx, "SELECT id FROM vehicles WHERE id = $1", vehicleID).Scan(&exists)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	rows, err := r.query(ctx, "SELECT "+mediaColumns+" FROM vehicle_media WHERE vehicle_id = $1 ORDER BY position, id", vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gallery := []models.VehicleMedia{}
+	for rows.Next() {
+		var media models.VehicleMedia
+		if err := scanMedia(rows, &media); err != nil {
+			return nil, err
+		}
+		gallery = append(gallery, media)
+	}
+	return gallery, rows.Err()
+}
+
+func (r *mediaRepository) GetByID(ctx context.Context, vehicleID, id int64) (*models.VehicleMedia, error) {
+	var media models.VehicleMedia
+	err := scanMedia(r.queryRow(ctx, "SELECT "+mediaColumns+" FROM vehicle_media WHERE id = $1 AND vehicle_id = $2", id, vehicleID), &media)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &media, nil
+}
+
+func (r *mediaRepository) Delete(ctx context.Context, vehicleID, id int64) error {
+	return r.inTx(ctx, func(t *tx) error {
+		if err := r.lockGallery(t, vehicleID); err != nil {
+			return err
+		}
+
+		var position int
+		var cover bool
+		err := t.queryRow("SELECT position, cover FROM vehicle_media WHERE id = $1 AND vehicle_id = $2", id, vehicleID).Scan(&position, &cover)
+		if err != nil {
+			return notFound(err)
+		}
+
+		if _, err := t.exec("DELETE FROM vehicle_media WHERE id = $1", id); err != nil {
+			return err
+		}
+		if _, err := t.exec("UPDATE vehicle_media SET position = position - 1 WHERE vehicle_id = $1 AND position > $2", vehicleID, position); err != nil {
+			return err
+		}
+		if !cover {
+			return nil
+		}
+
+		_, err = t.exec(`UPDATE vehicle_media SET cover = $1
+		WHERE id = (SELECT id FROM vehicle_media WHERE vehicle_id = $2 ORDER BY position, id LIMIT 1)`, true, vehicleID)
+		return err
+	})
+}
+
+func (r *mediaRepository) Reorder(ctx context.Context, vehicleID int64, ids []int64) error {
+	return r.inTx(ctx, func(t *tx) error {
+		if err := r.lockGallery(t, vehicleID); err != nil {
+			return err
+		}
+
+		rows, err := t.query("SELECT "+mediaColumns+" FROM vehicle_media WHERE vehicle_id = $1", vehicleID)
+		if err != nil {
+			return err
+		}
+		var current []models.VehicleMedia
+		for rows.Next() {
+			var media models.VehicleMedia
+			if err := scanMedia(rows, &media); err != nil {
+				rows.Close()
+				return err
+			}
+			current = append(current, media)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if err := models.CheckMediaOrder(current, ids); err != nil {
+			return err
+		}
+
+		for i, id := range ids {
+			if _, err := t.exec("UPDATE vehicle_media SET position = $1 WHERE id = $2", i+1, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *mediaRepository) SetCover(ctx context.Context, vehicleID, id int64) error {
+	return r.inTx(ctx, func(t *tx) error {
+		if err := r.lockGallery(t, vehicleID); err != nil {
+			return err
+		}
+
+		var exists int64
+		err := t.queryRow("SELECT id FROM vehicle_media WHERE id = $1 AND vehicle_id = $2", id, vehicleID).Scan(&exists)
+		if err != nil {
+			return notFound(err)
+		}
+
+		if _, err := t.exec("UPDATE vehicle_media SET cover = $1 WHERE vehicle_id = $2 AND cover", false, vehicleID); err != nil {
+			return err
+		}
+		_, err = t.exec("UPDATE vehicle_media SET cover = $1 WHERE id = $2", true, id)
+		return err
+	})
+}
@@ -0,0 +1,272 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+const reservationColumns = "r.id, r.client_id, r.vehicle_id, r.deposit, r.expires_at, r.status, r.created_at, r.closed_at, s.id"
+
+// reservationQuery joins the sale a reservation was converted into.
+const reservationQuery = "SELECT " + reservationColumns + " FROM reservations r LEFT JOIN sales s ON s.id = r.sale_id"
+
+type reservationRepository struct {
+	*Store
+}
+
+func scanReservation(row scanner, reservation *models.Reservation) error {
+	var closedAt sql.NullTime
+	var saleID sql.NullInt64
+	err := row.Scan(&reservation.ID, &reservation.ClientID, &reservation.VehicleID, &reservation.Deposit,
+		&reservation.ExpiresAt, &reservation.Status, &reservation.CreatedAt, &closedAt, &saleID)
+	if err != nil {
+		return err
+	}
+	if closedAt.Valid {
+		reservation.ClosedAt = &closedAt.Time
+	}
+	if saleID.Valid {
+		reservation.SaleID = &saleID.Int64
+	}
+	return nil
+}
+
+func (r *reservationRepository) Create(ctx context.Context, reservation *models.Reservation) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var status models.VehicleStatus
+		err := t.queryRow("SELECT status FROM vehicles WHERE id = $1"+r.dialect.ForUpdate(), reservation.VehicleID).Scan(&status)
+		if err != nil {
+			return notFound(err)
+		}
+
+		if err := models.CheckTransition(status, models.StatusReserved); err != nil {
+			return err
+		}
+
+		var clientID int64
+		err = t.queryRow("SELECT id FROM clients WHERE id = $1", reservation.ClientID).Scan(&clientID)
+		if err != nil {
+			return notFound(err)
+		}
+
+		reservation.Status = models.ReservationActive
+		reservation.CreatedAt = time.Now().UTC()
+		reservation.ExpiresAt = reservation.ExpiresAt.UTC()
+
+		query := `
+		INSERT INTO reservations (client_id, vehicle_id, deposit, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+		err = t.queryRow(query, reservation.ClientID, reservation.VehicleID, reservation.Deposit,
+			reservation.Status, reservation.ExpiresAt, reservation.CreatedAt).Scan(&reservation.ID)
+		if err != nil {
+			return err
+		}
+
+		slog.Debug("Reservation created", "reservation_id", reservation.ID, "vehicle_id", reservation.VehicleID, "expires_at", reservation.ExpiresAt)
+
+		return transition(t, status, &models.VehicleStatusChange{
+			VehicleID: reservation.VehicleID,
+			To:        models.StatusReserved,
+			Reason:    fmt.Sprintf("reservation %d", reservation.ID),
+			Actor:     models.SystemActor,
+		})
+	})
+}
+
+func (r *reservationRepository) GetAll(ctx context.Context) ([]models.Reservation, error) {
+	rows, err := r.query(ctx, reservationQuery+" ORDER BY r.created_at DESC, r.id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []models.Reservation
+	for rows.Next() {
+		var reservation models.Reservation
+		if err := scanReservation(rows, &reservation); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, rows.Err()
+}
+
+func (r *reservationRepository) GetByID(ctx context.Context, id int64) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := scanReservation(r.queryRow(ctx, reservationQuery+" WHERE r.id = $1", id), &reservation)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &reservation, nil
+}
+
+func (r *reservationRepository) Cancel(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(t *tx) error {
+		reservation, err := lockReservation(t, id)
+		if err != nil {
+			return err
+		}
+		if reservation.Status != models.ReservationActive {
+			return &models.ReservationNotActiveError{ReservationID: id, Status: reservation.Status}
+		}
+
+		return releaseReservation(t, reservation, models.ReservationCancelled, fmt.Sprintf("reservation %d cancelled", id))
+	})
+}
+
+func (r *reservationRepository) Convert(ctx context.Context, id int64, sale *models.Sale) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var reservation models.Reservation
+		err := scanReservation(t.queryRow(reservationQuery+" WHERE r.id = $1", id), &reservation)
+		if err != nil {
+			return notFound(err)
+		}
+
+		sale.ClientID = reservation.ClientID
+		sale.VehicleID = reservation.VehicleID
+		sale.ReservationID = &reservation.ID
+
+		// createSale locks the vehicle before the reservation and checks the
+		// reservation is still the one holding it.
+		return r.createSale(t, sale)
+	})
+}
+
+func (r *reservationRepository) ExpireDue(ctx context.Context, now time.Time) ([]models.Reservation, error) {
+	rows, err := r.query(ctx, "SELECT id FROM reservations WHERE status = $1 AND expires_at <= $2",
+		models.ReservationActive, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Each reservation is released in its own transaction so one failure
+	// does not hold back the others.
+	var expired []models.Reservation
+	for _, id := range ids {
+		var reservation *models.Reservation
+		err := r.inTx(ctx, func(t *tx) error {
+			locked, err := lockReservation(t, id)
+			if err != nil {
+				return err
+			}
+			// Converted or cancelled since it was selected.
+			if locked.Status != models.ReservationActive {
+				return nil
+			}
+			reservation = locked
+			return releaseReservation(t, locked, models.ReservationExpired, fmt.Sprintf("reservation %d expired", id))
+		})
+		if err != nil {
+			return expired, err
+		}
+		if reservation != nil {
+			expired = append(expired, *reservation)
+		}
+	}
+
+	return expired, nil
+}
+
+// lockReservation reads a reservation after locking its vehicle, the same
+// order in which sales lock them.
+func lockReservation(t *tx, id int64) (*models.Reservation, error) {
+	var vehicleID int64
+	err := t.queryRow("SELECT vehicle_id FROM reservations WHERE id = $1", id).Scan(&vehicleID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	var status models.VehicleStatus
+	err = t.queryRow("SELECT status FROM vehicles WHERE id = $1"+t.dialect.ForUpdate(), vehicleID).Scan(&status)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	var reservation models.Reservation
+	err = scanReservation(t.queryRow(reservationQuery+" WHERE r.id = $1", id), &reservation)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &reservation, nil
+}
+
+// activeReservation returns the active reservation on a vehicle locked by
+// the caller, or nil if there is none.
+func activeReservation(t *tx, vehicleID int64) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := scanReservation(t.queryRow(reservationQuery+" WHERE r.vehicle_id = $1 AND r.status = $2",
+		vehicleID, models.ReservationActive), &reservation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// releaseReservation closes an active reservation on a vehicle locked by the
+// caller and makes the vehicle available again.
+func releaseReservation(t *tx, reservation *models.Reservation, status models.ReservationStatus, reason string) error {
+	if err := closeReservation(t, reservation, status, nil); err != nil {
+		return err
+	}
+
+	var vehicleStatus models.VehicleStatus
+	err := t.queryRow("SELECT status FROM vehicles WHERE id = $1", reservation.VehicleID).Scan(&vehicleStatus)
+	if err != nil {
+		return err
+	}
+	if vehicleStatus != models.StatusReserved {
+		return nil
+	}
+
+	return transition(t, vehicleStatus, &models.VehicleStatusChange{
+		VehicleID: reservation.VehicleID,
+		To:        models.StatusAvailable,
+		Reason:    reason,
+		Actor:     models.SystemActor,
+	})
+}
+
+func closeReservation(t *tx, reservation *models.Reservation, status models.ReservationStatus, saleID *int64) error {
+	closedAt := time.Now().UTC()
+	_, err := t.exec("UPDATE reservations SET status = $1, closed_at = $2, sale_id = $3 WHERE id = $4",
+		status, closedAt, saleID, reservation.ID)
+	if err != nil {
+		return err
+	}
+
+	reservation.Status = status
+	reservation.ClosedAt = &closedAt
+	reservation.SaleID = saleID
+	return nil
+}
+
+func reservationStatus(t *tx, id int64) (models.ReservationStatus, error) {
+	var status models.ReservationStatus
+	err := t.queryRow("SELECT status FROM reservations WHERE id = $1", id).Scan(&status)
+	return status, notFound(err)
+}
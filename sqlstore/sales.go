@@ -0,0 +1,259 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+var saleDetailsQuery = `
+	SELECT 
+		s.id, s.price, s.sale_date, s.deposit_credited, s.trade_in_credit, s.amount_due, r.id, co.id,
+		COALESCE((SELECT SUM(e.amount) FROM vehicle_expenses e WHERE e.vehicle_id = v.id), 0), co.amount_owed,
+		c.id, c.name, c.email, c.phone,
+		` + prefixed("v", vehicleColumns) + `
+	FROM sales s
+	JOIN clients c ON s.client_id = c.id
+	JOIN vehicles v ON s.vehicle_id = v.id
+	LEFT JOIN reservations r ON r.sale_id = s.id
+	LEFT JOIN consignments co ON co.sale_id = s.id`
+
+type saleRepository struct {
+	*Store
+}
+
+func scanSaleWithDetails(row scanner, sale *models.SaleWithDetails) error {
+	var reservationID sql.NullInt64
+	var expenses float64
+	var amountOwed *float64
+	fields := []interface{}{
+		&sale.ID, &sale.Price, &sale.SaleDate, &sale.DepositCredited, &sale.TradeInCredit, &sale.AmountDue,
+		&reservationID, nullInt64{&sale.ConsignmentID}, &expenses, nullFloat64{&amountOwed},
+		&sale.Client.ID, &sale.Client.Name, &sale.Client.Email, &sale.Client.Phone,
+	}
+	if err := row.Scan(append(fields, vehicleFields(&sale.Vehicle)...)...); err != nil {
+		return err
+	}
+	if reservationID.Valid {
+		sale.ReservationID = &reservationID.Int64
+	}
+	sale.Cost = models.VehicleCost(&sale.Vehicle, expenses, amountOwed)
+	sale.GrossMargin = sale.Price - sale.Cost
+	return nil
+}
+
+// Create runs in a single transaction: the vehicle row is locked first so
+// concurrent sales of the same vehicle are serialised, and the UNIQUE
+// constraint on sales.vehicle_id is the final guard against double sales.
+func (r *saleRepository) Create(ctx context.Context, s *models.Sale) error {
+	return r.inTx(ctx, func(t *tx) error {
+		return r.createSale(t, s)
+	})
+}
+
+// createSale records a sale within the caller's transaction, converting the
+// reservation that holds the vehicle if it belongs to the buyer.
+func (s *Store) createSale(t *tx, sale *models.Sale) error {
+	// Lock the vehicle and check it can still be sold
+	var status models.VehicleStatus
+	err := t.queryRow("SELECT status FROM vehicles WHERE id = $1"+s.dialect.ForUpdate(), sale.VehicleID).Scan(&status)
+	if err != nil {
+		return notFound(err)
+	}
+
+	if status == models.StatusSold {
+		return &models.VehicleAlreadySoldError{VehicleID: sale.VehicleID}
+	}
+
+	reservation, err := activeReservation(t, sale.VehicleID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if reservation != nil && !reservation.Holds(now) {
+		// Expired but not yet released by the worker.
+		err := releaseReservation(t, reservation, models.ReservationExpired, fmt.Sprintf("reservation %d expired", reservation.ID))
+		if err != nil {
+			return err
+		}
+		status = models.StatusAvailable
+		reservation = nil
+	}
+
+	if sale.ReservationID != nil && (reservation == nil || reservation.ID != *sale.ReservationID) {
+		closed, err := reservationStatus(t, *sale.ReservationID)
+		if err != nil {
+			return err
+		}
+		return &models.ReservationNotActiveError{ReservationID: *sale.ReservationID, Status: closed}
+	}
+
+	if reservation != nil {
+		if reservation.ClientID != sale.ClientID {
+			return &models.VehicleReservedError{VehicleID: sale.VehicleID, ReservationID: reservation.ID, ExpiresAt: reservation.ExpiresAt}
+		}
+		sale.ReservationID = &reservation.ID
+		sale.DepositCredited = reservation.Deposit
+	}
+
+	if err := models.CheckTransition(status, models.StatusSold); err != nil {
+		return err
+	}
+
+	consignment, err := activeConsignment(t, sale.VehicleID)
+	if err != nil {
+		return err
+	}
+	sale.ConsignmentID = nil
+	if consignment != nil {
+		if err := consignment.CheckPrice(sale.Price); err != nil {
+			return err
+		}
+		sale.ConsignmentID = &consignment.ID
+	}
+
+	// Check if client exists
+	var clientID int64
+	err = t.queryRow("SELECT id FROM clients WHERE id = $1", sale.ClientID).Scan(&clientID)
+	if err != nil {
+		return notFound(err)
+	}
+
+	// Create the sale
+	sale.SaleDate = now
+	sale.TradeInCredit = 0
+	for _, tradeIn := range sale.TradeIns {
+		sale.TradeInCredit += tradeIn.Valuation
+	}
+	sale.AmountDue = sale.Price - sale.DepositCredited - sale.TradeInCredit
+	query := `INSERT INTO sales (client_id, vehicle_id, price, sale_date, deposit_credited, trade_in_credit, amount_due)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	err = t.queryRow(query, sale.ClientID, sale.VehicleID, sale.Price, sale.SaleDate,
+		sale.DepositCredited, sale.TradeInCredit, sale.AmountDue).Scan(&sale.ID)
+	if err != nil {
+		if s.dialect.IsUniqueViolation(err) {
+			return &models.VehicleAlreadySoldError{VehicleID: sale.VehicleID}
+		}
+		return err
+	}
+
+	if reservation != nil {
+		if err := closeReservation(t, reservation, models.ReservationConverted, &sale.ID); err != nil {
+			return err
+		}
+	}
+	if consignment != nil {
+		if err := sellConsignment(t, consignment, sale); err != nil {
+			return err
+		}
+	}
+
+	// Take the trade-ins into the inventory
+	catalog, err := s.loadCatalog(t)
+	if err != nil {
+		return err
+	}
+	for i := range sale.TradeIns {
+		tradeIn := &sale.TradeIns[i]
+		vehicle := &tradeIn.Vehicle
+		clientID := sale.ClientID
+		vehicle.Status = models.StatusIncoming
+		vehicle.PurchasePrice = tradeIn.Valuation
+		vehicle.PreviousOwnerID = &clientID
+		vehicle.AcquisitionDate = &now
+
+		if err := s.insertVehicle(t, catalog, vehicle, fmt.Sprintf("trade-in for sale %d", sale.ID)); err != nil {
+			return &models.BatchError{Index: i, Err: err}
+		}
+
+		_, err := t.exec("INSERT INTO sale_trade_ins (sale_id, vehicle_id, valuation) VALUES ($1, $2, $3)",
+			sale.ID, vehicle.ID, tradeIn.Valuation)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update vehicle status to sold
+	return transition(t, status, &models.VehicleStatusChange{
+		VehicleID: sale.VehicleID,
+		To:        models.StatusSold,
+		Reason:    fmt.Sprintf("sale %d", sale.ID),
+		Actor:     models.SystemActor,
+	})
+}
+
+func (r *saleRepository) GetAll(ctx context.Context) ([]models.SaleWithDetails, error) {
+	rows, err := r.query(ctx, saleDetailsQuery+"\n\tORDER BY s.sale_date DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []models.SaleWithDetails
+
+	for rows.Next() {
+		var sale models.SaleWithDetails
+		if err := scanSaleWithDetails(rows, &sale); err != nil {
+			return nil, err
+		}
+		sales = append(sales, sale)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tradeIns, err := r.tradeIns(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range sales {
+		sales[i].TradeIns = tradeIns[sales[i].ID]
+	}
+
+	return sales, nil
+}
+
+func (r *saleRepository) GetByID(ctx context.Context, id int64) (*models.SaleWithDetails, error) {
+	var sale models.SaleWithDetails
+	err := scanSaleWithDetails(r.queryRow(ctx, saleDetailsQuery+"\n\tWHERE s.id = $1", id), &sale)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	tradeIns, err := r.tradeIns(ctx, " WHERE t.sale_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	sale.TradeIns = tradeIns[sale.ID]
+
+	return &sale, nil
+}
+
+// tradeIns loads the trade-ins selected by where, grouped by sale ID.
+func (r *saleRepository) tradeIns(ctx context.Context, where string, args ...interface{}) (map[int64][]models.TradeIn, error) {
+	query := "SELECT t.sale_id, t.valuation, " + prefixed("v", vehicleColumns) +
+		" FROM sale_trade_ins t JOIN vehicles v ON v.id = t.vehicle_id" + where + " ORDER BY t.id"
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bySale := map[int64][]models.TradeIn{}
+	for rows.Next() {
+		var saleID int64
+		var tradeIn models.TradeIn
+		fields := append([]interface{}{&saleID, &tradeIn.Valuation}, vehicleFields(&tradeIn.Vehicle)...)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		bySale[saleID] = append(bySale[saleID], tradeIn)
+	}
+	return bySale, rows.Err()
+}
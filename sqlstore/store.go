@@ -0,0 +1,198 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Stand/db"
+	"github.com/Stand/models"
+)
+
+// Store implements the model repositories on top of database/sql for any
+// supported db.Dialect.
+type Store struct {
+	db           *sql.DB
+	dialect      db.Dialect
+	catalogCache *catalogCache
+}
+
+func New(conn *sql.DB, dialect db.Dialect) *Store {
+	return &Store{db: conn, dialect: dialect, catalogCache: &catalogCache{}}
+}
+
+// Repositories returns the model repositories backed by this store.
+func (s *Store) Repositories() models.Repositories {
+	return models.Repositories{
+		Vehicles:     &vehicleRepository{s},
+		Clients:      &clientRepository{s},
+		Sales:        &saleRepository{s},
+		Reservations: &reservationRepository{s},
+		Expenses:     &expenseRepository{s},
+		Media:        &mediaRepository{s},
+		Documents:    &documentRepository{s},
+		Feeds:        &feedRepository{s},
+		Catalog:      &catalogRepository{s},
+		Consignments: &consignmentRepository{s},
+		Locations:    &locationRepository{s},
+		Transfers:    &transferRepository{s},
+		TestDrives:   &testDriveRepository{s},
+	}
+}
+
+func (s *Store) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRowContext(ctx, s.dialect.Rebind(query), args...)
+}
+
+func (s *Store) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, s.dialect.Rebind(query), args...)
+}
+
+func (s *Store) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.dialect.Rebind(query), args...)
+}
+
+// tx wraps a transaction with the store's placeholder rebinding and the
+// context the transaction was started with.
+type tx struct {
+	*sql.Tx
+	ctx     context.Context
+	dialect db.Dialect
+}
+
+func (t *tx) queryRow(query string, args ...interface{}) *sql.Row {
+	return t.QueryRowContext(t.ctx, t.dialect.Rebind(query), args...)
+}
+
+func (t *tx) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return t.QueryContext(t.ctx, t.dialect.Rebind(query), args...)
+}
+
+func (t *tx) exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.ExecContext(t.ctx, t.dialect.Rebind(query), args...)
+}
+
+// inTx runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise. The transaction is rolled back if ctx is cancelled.
+func (s *Store) inTx(ctx context.Context, fn func(t *tx) error) error {
+	sqlTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&tx{Tx: sqlTx, ctx: ctx, dialect: s.dialect}); err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return sqlTx.Commit()
+}
+
+// notFound maps sql.ErrNoRows to models.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrNotFound
+	}
+	return err
+}
+
+// affected returns models.ErrNotFound when an UPDATE or DELETE matched no rows.
+func affected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// deleteByID deletes a row, reporting models.ErrInUse when other rows still reference it.
+func (s *Store) deleteByID(ctx context.Context, table string, id int64) error {
+	err := affected(s.exec(ctx, "DELETE FROM "+table+" WHERE id = $1", id))
+	if err != nil && s.dialect.IsForeignKeyViolation(err) {
+		return models.ErrInUse
+	}
+	return err
+}
+
+// nullString scans a nullable text column into a string, mapping NULL to "".
+type nullString struct {
+	dst *string
+}
+
+func (n nullString) Scan(value interface{}) error {
+	var ns sql.NullString
+	if err := ns.Scan(value); err != nil {
+		return err
+	}
+	*n.dst = ns.String
+	return nil
+}
+
+// nullTime scans a nullable timestamp into a *time.Time, mapping NULL to nil.
+type nullTime struct {
+	dst **time.Time
+}
+
+func (n nullTime) Scan(value interface{}) error {
+	var nt sql.NullTime
+	if err := nt.Scan(value); err != nil {
+		return err
+	}
+	*n.dst = nil
+	if nt.Valid {
+		*n.dst = &nt.Time
+	}
+	return nil
+}
+
+// nullInt64 scans a nullable integer column into a *int64, mapping NULL to nil.
+type nullInt64 struct {
+	dst **int64
+}
+
+func (n nullInt64) Scan(value interface{}) error {
+	var ni sql.NullInt64
+	if err := ni.Scan(value); err != nil {
+		return err
+	}
+	*n.dst = nil
+	if ni.Valid {
+		*n.dst = &ni.Int64
+	}
+	return nil
+}
+
+// nullFloat64 scans a nullable numeric column into a *float64, mapping NULL
+// to nil.
+type nullFloat64 struct {
+	dst **float64
+}
+
+func (n nullFloat64) Scan(value interface{}) error {
+	var nf sql.NullFloat64
+	if err := nf.Scan(value); err != nil {
+		return err
+	}
+	*n.dst = nil
+	if nf.Valid {
+		*n.dst = &nf.Float64
+	}
+	return nil
+}
+
+// emptyToNull stores empty optional text as NULL so it does not collide with
+// other empty values in unique indexes.
+func emptyToNull(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
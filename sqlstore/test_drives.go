@@ -0,0 +1,182 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+const testDriveColumns = "id, client_id, vehicle_id, salesperson, location_id, starts_at, ends_at, notes, " +
+	"status, created_at, cancelled_at"
+
+var testDriveDetailsQuery = "SELECT " + prefixed("d", testDriveColumns) + `,
+		c.id, c.name, c.email, c.phone, l.id, l.name, l.address, ` + prefixed("v", vehicleColumns) + `
+	FROM test_drives d
+	JOIN clients c ON c.id = d.client_id
+	JOIN vehicles v ON v.id = d.vehicle_id
+	LEFT JOIN locations l ON l.id = d.location_id`
+
+type testDriveRepository struct {
+	*Store
+}
+
+// testDriveFields returns the scan destinations matching testDriveColumns.
+func testDriveFields(drive *models.TestDrive) []interface{} {
+	return []interface{}{
+		&drive.ID, &drive.ClientID, &drive.VehicleID, &drive.Salesperson, nullInt64{&drive.LocationID},
+		&drive.StartsAt, &drive.EndsAt, &drive.Notes, &drive.Status, &drive.CreatedAt, nullTime{&drive.CancelledAt},
+	}
+}
+
+func scanTestDriveWithDetails(row scanner, drive *models.TestDriveWithDetails) error {
+	var locationID *int64
+	var location models.Location
+	fields := append(testDriveFields(&drive.TestDrive),
+		&drive.Client.ID, &drive.Client.Name, &drive.Client.Email, &drive.Client.Phone,
+		nullInt64{&locationID}, nullString{&location.Name}, nullString{&location.Address})
+	if err := row.Scan(append(fields, vehicleFields(&drive.Vehicle)...)...); err != nil {
+		return err
+	}
+	drive.Location = nil
+	if locationID != nil {
+		location.ID = *locationID
+		drive.Location = &location
+	}
+	return nil
+}
+
+func (r *testDriveRepository) Book(ctx context.Context, drive *models.TestDrive) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var status models.VehicleStatus
+		var locationID *int64
+		err := t.queryRow("SELECT status, location_id FROM vehicles WHERE id = $1"+r.dialect.ForUpdate(), drive.VehicleID).
+			Scan(&status, nullInt64{&locationID})
+		if err != nil {
+			return notFound(err)
+		}
+
+		var clientID int64
+		err = t.queryRow("SELECT id FROM clients WHERE id = $1", drive.ClientID).Scan(&clientID)
+		if err != nil {
+			return notFound(err)
+		}
+
+		if err := models.CheckBookable(drive.VehicleID, status); err != nil {
+			return err
+		}
+
+		// A vehicle being moved may not be at its location when the drive
+		// starts; the vehicle lock keeps a transfer from being requested
+		// meanwhile.
+		var transferID int64
+		err = t.queryRow("SELECT id FROM vehicle_transfers WHERE vehicle_id = $1 AND status IN ($2, $3)",
+			drive.VehicleID, models.TransferRequested, models.TransferInTransit).Scan(&transferID)
+		if err == nil {
+			return &models.VehicleInTransferError{VehicleID: drive.VehicleID, TransferID: transferID}
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// The vehicle lock serialises bookings of the vehicle and this one
+		// those of the salesperson, whatever the vehicle.
+		if lock := r.dialect.LockInTx(); lock != "" {
+			if _, err := t.exec(lock, "test_drives.salesperson:"+models.Fold(drive.Salesperson)); err != nil {
+				return err
+			}
+		}
+
+		var other models.TestDrive
+		err = t.queryRow("SELECT "+testDriveColumns+" FROM test_drives WHERE status = $1 AND starts_at < $2 AND ends_at > $3"+
+			" AND (vehicle_id = $4 OR "+r.dialect.Fold("salesperson")+" = $5) ORDER BY starts_at, id LIMIT 1",
+			models.TestDriveScheduled, drive.EndsAt, drive.StartsAt, drive.VehicleID, models.Fold(drive.Salesperson)).
+			Scan(testDriveFields(&other)...)
+		if err == nil {
+			return models.NewTestDriveConflictError(drive, &other)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		drive.LocationID = locationID
+		drive.Status = models.TestDriveScheduled
+		drive.CreatedAt = time.Now().UTC()
+		drive.CancelledAt = nil
+
+		query := `
+		INSERT INTO test_drives (client_id, vehicle_id, salesperson, location_id, starts_at, ends_at, notes, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+		err = t.queryRow(query, drive.ClientID, drive.VehicleID, drive.Salesperson, drive.LocationID,
+			drive.StartsAt, drive.EndsAt, drive.Notes, drive.Status, drive.CreatedAt).Scan(&drive.ID)
+		if err != nil {
+			return err
+		}
+
+		slog.Debug("Test drive booked", "test_drive_id", drive.ID, "vehicle_id", drive.VehicleID)
+		return nil
+	})
+}
+
+func (r *testDriveRepository) Find(ctx context.Context, filter models.TestDriveFilter) ([]models.TestDriveWithDetails, error) {
+	c := &conditions{}
+	if filter.VehicleID != nil {
+		c.add("d.vehicle_id = " + c.arg(*filter.VehicleID))
+	}
+	if filter.LocationID != nil {
+		c.add("d.location_id = " + c.arg(*filter.LocationID))
+	}
+	if filter.Salesperson != "" {
+		c.add(r.dialect.Fold("d.salesperson") + " = " + c.arg(models.Fold(filter.Salesperson)))
+	}
+	if filter.EndsAfter != nil {
+		c.add("d.ends_at > " + c.arg(filter.EndsAfter.UTC()))
+	}
+	if filter.Scheduled {
+		c.add("d.status = " + c.arg(models.TestDriveScheduled))
+	}
+
+	rows, err := r.query(ctx, testDriveDetailsQuery+c.where()+" ORDER BY d.starts_at, d.id", c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drives := []models.TestDriveWithDetails{}
+	for rows.Next() {
+		var drive models.TestDriveWithDetails
+		if err := scanTestDriveWithDetails(rows, &drive); err != nil {
+			return nil, err
+		}
+		drives = append(drives, drive)
+	}
+	return drives, rows.Err()
+}
+
+func (r *testDriveRepository) GetByID(ctx context.Context, id int64) (*models.TestDriveWithDetails, error) {
+	var drive models.TestDriveWithDetails
+	if err := scanTestDriveWithDetails(r.queryRow(ctx, testDriveDetailsQuery+" WHERE d.id = $1", id), &drive); err != nil {
+		return nil, notFound(err)
+	}
+	return &drive, nil
+}
+
+func (r *testDriveRepository) Cancel(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var status models.TestDriveStatus
+		err := t.queryRow("SELECT status FROM test_drives WHERE id = $1"+t.dialect.ForUpdate(), id).Scan(&status)
+		if err != nil {
+			return notFound(err)
+		}
+		if status != models.TestDriveScheduled {
+			return &models.TestDriveNotScheduledError{TestDriveID: id, Status: status}
+		}
+
+		_, err = t.exec("UPDATE test_drives SET status = $1, cancelled_at = $2 WHERE id = $3",
+			models.TestDriveCancelled, time.Now().UTC(), id)
+		return err
+	})
+}
@@ -0,0 +1,194 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+const transferQuery = "SELECT id, vehicle_id, from_location_id, to_location_id, driver, status, " +
+	"requested_at, dispatched_at, received_at, cancelled_at FROM vehicle_transfers"
+
+type transferRepository struct {
+	*Store
+}
+
+func scanTransfer(row scanner, transfer *models.VehicleTransfer) error {
+	return row.Scan(&transfer.ID, &transfer.VehicleID, nullInt64{&transfer.FromLocationID}, &transfer.ToLocationID,
+		&transfer.Driver, &transfer.Status, &transfer.RequestedAt,
+		nullTime{&transfer.DispatchedAt}, nullTime{&transfer.ReceivedAt}, nullTime{&transfer.CancelledAt})
+}
+
+// listTransfers returns the transfers selected by where, newest first.
+func listTransfers(t *tx, where string, args ...interface{}) ([]models.VehicleTransfer, error) {
+	rows, err := t.query(transferQuery+where+" ORDER BY requested_at DESC, id DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []models.VehicleTransfer{}
+	for rows.Next() {
+		var transfer models.VehicleTransfer
+		if err := scanTransfer(rows, &transfer); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, rows.Err()
+}
+
+func (r *transferRepository) Request(ctx context.Context, transfer *models.VehicleTransfer) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var status models.VehicleStatus
+		var locationID *int64
+		err := t.queryRow("SELECT status, location_id FROM vehicles WHERE id = $1"+r.dialect.ForUpdate(), transfer.VehicleID).
+			Scan(&status, nullInt64{&locationID})
+		if err != nil {
+			return notFound(err)
+		}
+
+		var open models.VehicleTransfer
+		err = scanTransfer(t.queryRow(transferQuery+" WHERE vehicle_id = $1 AND status IN ($2, $3)",
+			transfer.VehicleID, models.TransferRequested, models.TransferInTransit), &open)
+		if err == nil {
+			return &models.VehicleInTransferError{VehicleID: transfer.VehicleID, TransferID: open.ID}
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		var destination int64
+		err = t.queryRow("SELECT id FROM locations WHERE id = $1", transfer.ToLocationID).Scan(&destination)
+		if err != nil {
+			return notFound(err)
+		}
+
+		if err := transfer.Check(status, locationID); err != nil {
+			return err
+		}
+
+		transfer.FromLocationID = locationID
+		transfer.Status = models.TransferRequested
+		transfer.RequestedAt = time.Now().UTC()
+		transfer.DispatchedAt, transfer.ReceivedAt, transfer.CancelledAt = nil, nil, nil
+
+		query := `
+		INSERT INTO vehicle_transfers (vehicle_id, from_location_id, to_location_id, driver, status, requested_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+		err = t.queryRow(query, transfer.VehicleID, transfer.FromLocationID, transfer.ToLocationID,
+			transfer.Driver, transfer.Status, transfer.RequestedAt).Scan(&transfer.ID)
+		if err != nil {
+			return err
+		}
+
+		slog.Debug("Transfer requested", "transfer_id", transfer.ID, "vehicle_id", transfer.VehicleID, "to_location_id", transfer.ToLocationID)
+		return nil
+	})
+}
+
+func (r *transferRepository) GetAll(ctx context.Context, vehicleID *int64) ([]models.VehicleTransfer, error) {
+	var transfers []models.VehicleTransfer
+	err := r.inTx(ctx, func(t *tx) error {
+		var err error
+		if vehicleID != nil {
+			transfers, err = listTransfers(t, " WHERE vehicle_id = $1", *vehicleID)
+		} else {
+			transfers, err = listTransfers(t, "")
+		}
+		return err
+	})
+	return transfers, err
+}
+
+func (r *transferRepository) GetByID(ctx context.Context, id int64) (*models.VehicleTransfer, error) {
+	var transfer models.VehicleTransfer
+	if err := scanTransfer(r.queryRow(ctx, transferQuery+" WHERE id = $1", id), &transfer); err != nil {
+		return nil, notFound(err)
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) Dispatch(ctx context.Context, id int64, driver string) error {
+	return r.inTx(ctx, func(t *tx) error {
+		transfer, err := lockTransfer(t, id)
+		if err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferRequested {
+			return &models.TransferStatusError{TransferID: id, Status: transfer.Status}
+		}
+
+		if driver == "" {
+			driver = transfer.Driver
+		}
+		if driver == "" {
+			return models.ValidationErrors{{Field: "driver", Message: "is required"}}
+		}
+		_, err = t.exec("UPDATE vehicle_transfers SET status = $1, driver = $2, dispatched_at = $3 WHERE id = $4",
+			models.TransferInTransit, driver, time.Now().UTC(), id)
+		return err
+	})
+}
+
+func (r *transferRepository) Receive(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(t *tx) error {
+		transfer, err := lockTransfer(t, id)
+		if err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferInTransit {
+			return &models.TransferStatusError{TransferID: id, Status: transfer.Status}
+		}
+
+		_, err = t.exec("UPDATE vehicle_transfers SET status = $1, received_at = $2 WHERE id = $3",
+			models.TransferReceived, time.Now().UTC(), id)
+		if err != nil {
+			return err
+		}
+
+		_, err = t.exec("UPDATE vehicles SET location_id = $1, updated_at = $2 WHERE id = $3",
+			transfer.ToLocationID, updateTime(), transfer.VehicleID)
+		return err
+	})
+}
+
+func (r *transferRepository) Cancel(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(t *tx) error {
+		transfer, err := lockTransfer(t, id)
+		if err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferRequested {
+			return &models.TransferStatusError{TransferID: id, Status: transfer.Status}
+		}
+
+		_, err = t.exec("UPDATE vehicle_transfers SET status = $1, cancelled_at = $2 WHERE id = $3",
+			models.TransferCancelled, time.Now().UTC(), id)
+		return err
+	})
+}
+
+// lockTransfer reads a transfer after locking its vehicle, the same order in
+// which transfers are requested.
+func lockTransfer(t *tx, id int64) (*models.VehicleTransfer, error) {
+	var vehicleID int64
+	err := t.queryRow("SELECT vehicle_id FROM vehicle_transfers WHERE id = $1", id).Scan(&vehicleID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	err = t.queryRow("SELECT id FROM vehicles WHERE id = $1"+t.dialect.ForUpdate(), vehicleID).Scan(&vehicleID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	var transfer models.VehicleTransfer
+	if err := scanTransfer(t.queryRow(transferQuery+" WHERE id = $1", id), &transfer); err != nil {
+		return nil, notFound(err)
+	}
+	return &transfer, nil
+}
@@ -0,0 +1,113 @@
+package sqlstore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Stand/models"
+)
+
+func (r *vehicleRepository) Facets(ctx context.Context, filter models.VehicleFilter) (*models.VehicleFacets, error) {
+	facets := &models.VehicleFacets{}
+
+	c := r.vehicleConditions(filter)
+	err := r.queryRow(ctx, "SELECT COUNT(*) FROM vehicles"+c.where(), c.args...).Scan(&facets.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each facet drops the filter on its own attribute.
+	withoutTypes, withoutBrands, withoutModels := filter, filter, filter
+	withoutTypes.Types = nil
+	withoutBrands.Brands = nil
+	withoutModels.Models = nil
+	withoutYears, withoutStatuses, withoutPrices := filter, filter, filter
+	withoutYears.YearMin, withoutYears.YearMax = nil, nil
+	withoutStatuses.Statuses = nil
+	withoutPrices.PriceMin, withoutPrices.PriceMax = nil, nil
+
+	if facets.Types, err = r.valueFacet(ctx, "type", withoutTypes); err != nil {
+		return nil, err
+	}
+	if facets.Brands, err = r.valueFacet(ctx, "brand", withoutBrands); err != nil {
+		return nil, err
+	}
+	if facets.Models, err = r.valueFacet(ctx, "model", withoutModels); err != nil {
+		return nil, err
+	}
+	if facets.Statuses, err = r.valueFacet(ctx, "status", withoutStatuses); err != nil {
+		return nil, err
+	}
+
+	yearBucket := "(year / " + strconv.Itoa(models.YearBucketSize) + ") * " + strconv.Itoa(models.YearBucketSize)
+	if facets.Years, err = r.bucketFacet(ctx, yearBucket, withoutYears, models.YearBucketFacet); err != nil {
+		return nil, err
+	}
+	if facets.PriceBands, err = r.bucketFacet(ctx, priceBandExpr(), withoutPrices, models.PriceBandFacet); err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// valueFacet counts vehicles per folded value of column, labelled with one
+// of the original spellings.
+func (r *vehicleRepository) valueFacet(ctx context.Context, column string, filter models.VehicleFilter) ([]models.FacetCount, error) {
+	c := r.vehicleConditions(filter)
+	query := "SELECT MIN(" + column + "), COUNT(*) FROM vehicles" + c.where() + " GROUP BY " + r.dialect.Fold(column)
+
+	rows, err := r.query(ctx, query, c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []models.FacetCount{}
+	for rows.Next() {
+		var facet models.FacetCount
+		if err := rows.Scan(&facet.Value, &facet.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, facet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	models.SortFacetCounts(counts)
+	return counts, nil
+}
+
+// bucketFacet counts vehicles per integer bucket computed by expr, in bucket order.
+func (r *vehicleRepository) bucketFacet(ctx context.Context, expr string, filter models.VehicleFilter, describe func(bucket, count int) models.FacetCount) ([]models.FacetCount, error) {
+	c := r.vehicleConditions(filter)
+	query := "SELECT " + expr + " AS bucket, COUNT(*) FROM vehicles" + c.where() + " GROUP BY bucket ORDER BY bucket"
+
+	rows, err := r.query(ctx, query, c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []models.FacetCount{}
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, describe(bucket, count))
+	}
+	return counts, rows.Err()
+}
+
+// priceBandExpr computes models.PriceBand in SQL.
+func priceBandExpr() string {
+	var expr strings.Builder
+	expr.WriteString("CASE")
+	for i, limit := range models.PriceBandLimits {
+		expr.WriteString(" WHEN asking_price < " + strconv.FormatFloat(limit, 'f', -1, 64) + " THEN " + strconv.Itoa(i))
+	}
+	expr.WriteString(" ELSE " + strconv.Itoa(len(models.PriceBandLimits)) + " END")
+	return expr.String()
+}
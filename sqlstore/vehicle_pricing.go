@@ -0,0 +1,106 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+func (r *vehicleRepository) Stock(ctx context.Context) ([]models.StockVehicle, error) {
+	c := conditions{}
+	c.in("v.status", values(models.InStockStatuses))
+
+	// The first status history entry marks when the vehicle was created.
+	query := "SELECT " + prefixed("v", vehicleColumns) + `, h.changed_at,
+		COALESCE((SELECT SUM(e.amount) FROM vehicle_expenses e WHERE e.vehicle_id = v.id), 0)
+	FROM vehicles v
+	LEFT JOIN vehicle_status_history h
+		ON h.id = (SELECT MIN(f.id) FROM vehicle_status_history f WHERE f.vehicle_id = v.id)` +
+		c.where() + " ORDER BY v.id"
+
+	rows, err := r.query(ctx, query, c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stock := []models.StockVehicle{}
+	for rows.Next() {
+		var item models.StockVehicle
+		var created *time.Time
+		dest := append(vehicleFields(&item.Vehicle), nullTime{&created}, &item.Expenses)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case item.Vehicle.AcquisitionDate != nil:
+			item.Since = *item.Vehicle.AcquisitionDate
+		case created != nil:
+			item.Since = *created
+		default:
+			item.Since = time.Now()
+		}
+		stock = append(stock, item)
+	}
+
+	return stock, rows.Err()
+}
+
+func (r *vehicleRepository) PriceHistory(ctx context.Context, vehicleID int64) ([]models.PriceChange, error) {
+	var exists int64
+	err := r.queryRow(ctx, "SELECT id FROM vehicles WHERE id = $1", vehicleID).Scan(&exists)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	query := `
+	SELECT id, vehicle_id, old_price, new_price, rule, reason, changed_at
+	FROM vehicle_price_history
+	WHERE vehicle_id = $1
+	ORDER BY changed_at, id`
+
+	rows, err := r.query(ctx, query, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.PriceChange{}
+	for rows.Next() {
+		var change models.PriceChange
+		err := rows.Scan(&change.ID, &change.VehicleID, &change.OldPrice, &change.NewPrice, &change.Rule, &change.Reason, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+
+	return history, rows.Err()
+}
+
+func (r *vehicleRepository) Reprice(ctx context.Context, change *models.PriceChange) error {
+	return r.inTx(ctx, func(t *tx) error {
+		result, err := t.exec("UPDATE vehicles SET asking_price = $1, updated_at = $2 WHERE id = $3 AND asking_price = $4 AND status = $5",
+			change.NewPrice, updateTime(), change.VehicleID, change.OldPrice, models.StatusAvailable)
+		if err := affected(result, err); errors.Is(err, models.ErrNotFound) {
+			return models.ErrVehicleChanged
+		} else if err != nil {
+			return err
+		}
+
+		return insertPriceChange(t, change)
+	})
+}
+
+func insertPriceChange(t *tx, change *models.PriceChange) error {
+	change.ChangedAt = time.Now()
+
+	query := `
+	INSERT INTO vehicle_price_history (vehicle_id, old_price, new_price, rule, reason, changed_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	return t.queryRow(query, change.VehicleID, change.OldPrice, change.NewPrice, change.Rule, change.Reason, change.ChangedAt).Scan(&change.ID)
+}
@@ -0,0 +1,220 @@
+package sqlstore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Stand/models"
+)
+
+// conditions accumulates the clauses of a WHERE and their arguments,
+// numbering the $N placeholders as they are added.
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+// arg adds a query argument and returns its placeholder.
+func (c *conditions) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return "$" + strconv.Itoa(len(c.args))
+}
+
+func (c *conditions) add(clause string) {
+	c.clauses = append(c.clauses, clause)
+}
+
+// in adds "expr IN (...)" for the values, or nothing if there are none.
+func (c *conditions) in(expr string, values []interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = c.arg(value)
+	}
+	c.add(expr + " IN (" + strings.Join(placeholders, ", ") + ")")
+}
+
+// between adds the bounds of an inclusive range; nil leaves that end open.
+func (c *conditions) between(column string, min, max *int) {
+	if min != nil {
+		c.add(column + " >= " + c.arg(*min))
+	}
+	if max != nil {
+		c.add(column + " <= " + c.arg(*max))
+	}
+}
+
+func (c *conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// vehicleConditions translates a filter into conditions on the vehicles table.
+func (s *Store) vehicleConditions(filter models.VehicleFilter) *conditions {
+	c := &conditions{}
+	fold := s.dialect.Fold
+
+	c.in(fold("type"), foldedValues(filter.Types))
+	c.in(fold("brand"), foldedValues(filter.Brands))
+	c.in(fold("model"), foldedValues(filter.Models))
+	c.in(fold("colour"), foldedValues(filter.Colours))
+	c.in("status", values(filter.Statuses))
+	c.in("location_id", values(filter.LocationIDs))
+	c.in("transmission", values(filter.Transmissions))
+	c.in("licence_category", values(filter.LicenceCategories))
+	c.in("fuel_type", values(filter.FuelTypes))
+	c.in("emission_standard", values(filter.EmissionStandards))
+
+	if filter.VIN != "" {
+		c.add("vin = " + c.arg(filter.VIN))
+	}
+	if filter.LicensePlate != "" {
+		c.add("license_plate = " + c.arg(filter.LicensePlate))
+	}
+
+	if filter.YearMin != nil {
+		c.add("year >= " + c.arg(*filter.YearMin))
+	}
+	if filter.YearMax != nil {
+		c.add("year <= " + c.arg(*filter.YearMax))
+	}
+	if filter.PriceMin != nil {
+		c.add("asking_price >= " + c.arg(*filter.PriceMin))
+	}
+	if filter.PriceMax != nil {
+		c.add("asking_price <= " + c.arg(*filter.PriceMax))
+	}
+	if filter.MileageMin != nil {
+		c.add("mileage >= " + c.arg(*filter.MileageMin))
+	}
+	if filter.MileageMax != nil {
+		c.add("mileage <= " + c.arg(*filter.MileageMax))
+	}
+	c.between("seats", filter.SeatsMin, filter.SeatsMax)
+	c.between("engine_cc", filter.EngineCCMin, filter.EngineCCMax)
+	c.between("payload_kg", filter.PayloadMin, filter.PayloadMax)
+	c.between("axles", filter.AxlesMin, filter.AxlesMax)
+	c.between("power_kw", filter.PowerKWMin, filter.PowerKWMax)
+	c.between("power_hp", filter.PowerHPMin, filter.PowerHPMax)
+	c.between("co2", filter.CO2Min, filter.CO2Max)
+	if filter.BatteryMin != nil {
+		c.add("battery_kwh >= " + c.arg(*filter.BatteryMin))
+	}
+	if filter.BatteryMax != nil {
+		c.add("battery_kwh <= " + c.arg(*filter.BatteryMax))
+	}
+	if filter.UpdatedSince != nil {
+		c.add("updated_at > " + c.arg(filter.UpdatedSince.UTC()))
+	}
+
+	for _, word := range filter.QueryWords() {
+		pattern := c.arg("%" + escapeLike(word) + "%")
+		var matches []string
+		for _, column := range []string{"brand", "model", "motor"} {
+			matches = append(matches, fold(column)+" LIKE "+pattern+` ESCAPE '\'`)
+		}
+		c.add("(" + strings.Join(matches, " OR ") + ")")
+	}
+
+	return c
+}
+
+// after adds the keyset condition selecting the rows that sort after the
+// cursor: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... OR (... AND id > cursor id),
+// with < instead of > for descending keys.
+func (c *conditions) after(cursor *models.VehicleCursor, keys []models.SortKey) {
+	var alternatives []string
+	var equal []string
+
+	for i, key := range keys {
+		op := " > "
+		if key.Desc {
+			op = " < "
+		}
+		value := c.arg(cursor.Values[i])
+		alternatives = append(alternatives, "("+strings.Join(append(equal, key.Field+op+value), " AND ")+")")
+		equal = append(equal, key.Field+" = "+value)
+	}
+	alternatives = append(alternatives, "("+strings.Join(append(equal, "id > "+c.arg(cursor.ID)), " AND ")+")")
+
+	c.add("(" + strings.Join(alternatives, " OR ") + ")")
+}
+
+func (r *vehicleRepository) Find(ctx context.Context, query models.VehicleQuery) (*models.VehiclePage, error) {
+	c := r.vehicleConditions(query.Filter)
+
+	page := &models.VehiclePage{Items: []models.Vehicle{}, Limit: query.Limit, Offset: query.Offset}
+	err := r.queryRow(ctx, "SELECT COUNT(*) FROM vehicles"+c.where(), c.args...).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.After != nil {
+		c.after(query.After, query.Sort)
+	}
+
+	order := make([]string, 0, len(query.Sort)+1)
+	for _, key := range query.Sort {
+		if key.Desc {
+			order = append(order, key.Field+" DESC")
+		} else {
+			order = append(order, key.Field)
+		}
+	}
+	order = append(order, "id")
+
+	sqlQuery := "SELECT " + vehicleColumns + " FROM vehicles" + c.where() + " ORDER BY " + strings.Join(order, ", ")
+	if query.Limit > 0 {
+		// One extra row tells whether there is a next page.
+		sqlQuery += " LIMIT " + c.arg(query.Limit+1) + " OFFSET " + c.arg(query.Offset)
+	}
+
+	rows, err := r.query(ctx, sqlQuery, c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vehicle models.Vehicle
+		if err := scanVehicle(rows, &vehicle); err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, vehicle)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if query.Limit > 0 && len(page.Items) > query.Limit {
+		page.Items = page.Items[:query.Limit]
+		page.NextCursor = models.NewVehicleCursor(&page.Items[query.Limit-1], query.Sort).Encode()
+	}
+	return page, nil
+}
+
+// escapeLike escapes the LIKE wildcards in a search term.
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+}
+
+func foldedValues(texts []string) []interface{} {
+	folded := make([]interface{}, len(texts))
+	for i, text := range texts {
+		folded[i] = models.Fold(text)
+	}
+	return folded
+}
+
+func values[T any](items []T) []interface{} {
+	converted := make([]interface{}, len(items))
+	for i, item := range items {
+		converted[i] = item
+	}
+	return converted
+}
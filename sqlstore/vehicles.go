@@ -0,0 +1,338 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+const vehicleColumns = "id, type, brand, model, year, motor, status, " +
+	"vin, license_plate, mileage, colour, transmission, doors, seats, asking_price, " +
+	"purchase_price, supplier, acquisition_date, previous_owner_id, updated_at, brand_id, model_id, " +
+	"engine_cc, licence_category, payload_kg, axles, location_id, " +
+	"fuel_type, power_kw, power_hp, co2, emission_standard, battery_kwh"
+
+type vehicleRepository struct {
+	*Store
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// vehicleFields returns the scan destinations matching vehicleColumns.
+func vehicleFields(vehicle *models.Vehicle) []interface{} {
+	return []interface{}{
+		&vehicle.ID, &vehicle.Type, &vehicle.Brand, &vehicle.Model, &vehicle.Year, &vehicle.Motor, &vehicle.Status,
+		nullString{&vehicle.VIN}, nullString{&vehicle.LicensePlate}, &vehicle.Mileage, &vehicle.Colour,
+		&vehicle.Transmission, &vehicle.Doors, &vehicle.Seats, &vehicle.AskingPrice,
+		&vehicle.PurchasePrice, &vehicle.Supplier, nullTime{&vehicle.AcquisitionDate}, nullInt64{&vehicle.PreviousOwnerID},
+		&vehicle.UpdatedAt, nullInt64{&vehicle.BrandID}, nullInt64{&vehicle.ModelID},
+		&vehicle.EngineCC, &vehicle.LicenceCategory, &vehicle.PayloadKg, &vehicle.Axles, nullInt64{&vehicle.LocationID},
+		&vehicle.FuelType, &vehicle.PowerKW, &vehicle.PowerHP, &vehicle.CO2, &vehicle.EmissionStandard, &vehicle.BatteryKWh,
+	}
+}
+
+func scanVehicle(row scanner, vehicle *models.Vehicle) error {
+	return row.Scan(vehicleFields(vehicle)...)
+}
+
+// prefixed qualifies each column of a comma-separated list with a table alias.
+func prefixed(alias, columns string) string {
+	parts := strings.Split(columns, ", ")
+	for i, column := range parts {
+		parts[i] = alias + "." + column
+	}
+	return strings.Join(parts, ", ")
+}
+
+// vehicleWriteError maps a unique violation on the VIN or plate indexes and
+// a foreign key violation on the previous owner.
+func (s *Store) vehicleWriteError(err error) error {
+	if s.dialect.IsForeignKeyViolation(err) {
+		return models.ValidationErrors{{Field: "PreviousOwnerID", Message: "client not found"}}
+	}
+	if !s.dialect.IsUniqueViolation(err) {
+		return err
+	}
+	if strings.Contains(err.Error(), "license_plate") {
+		return &models.DuplicateVehicleError{Field: "LicensePlate"}
+	}
+	return &models.DuplicateVehicleError{Field: "VIN"}
+}
+
+func (r *vehicleRepository) Create(ctx context.Context, v *models.Vehicle) error {
+	return r.inTx(ctx, func(t *tx) error {
+		catalog, err := r.loadCatalog(t)
+		if err != nil {
+			return err
+		}
+		return r.insertVehicle(t, catalog, v, "vehicle created")
+	})
+}
+
+func (r *vehicleRepository) CreateBatch(ctx context.Context, vehicles []models.Vehicle) error {
+	return r.inTx(ctx, func(t *tx) error {
+		catalog, err := r.loadCatalog(t)
+		if err != nil {
+			return err
+		}
+		for i := range vehicles {
+			if err := r.insertVehicle(t, catalog, &vehicles[i], "vehicle imported"); err != nil {
+				return &models.BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// insertVehicle resolves a vehicle against the catalogue, stores it and
+// records its initial status with reason.
+func (s *Store) insertVehicle(t *tx, catalog *models.Catalog, v *models.Vehicle, reason string) error {
+	if err := catalog.Resolve(v); err != nil {
+		return err
+	}
+	if v.LocationID != nil {
+		var locationID int64
+		err := t.queryRow("SELECT id FROM locations WHERE id = $1", *v.LocationID).Scan(&locationID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ValidationErrors{{Field: "LocationID", Message: "location not found"}}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+	INSERT INTO vehicles(type, brand, model, year, motor, status,
+		vin, license_plate, mileage, colour, transmission, doors, seats, asking_price,
+		purchase_price, supplier, acquisition_date, previous_owner_id, updated_at, brand_id, model_id,
+		engine_cc, licence_category, payload_kg, axles, location_id,
+		fuel_type, power_kw, power_hp, co2, emission_standard, battery_kwh)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21,
+		$22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32) RETURNING id`
+
+	v.UpdatedAt = updateTime()
+	err := t.queryRow(query, v.Type, v.Brand, v.Model, v.Year, v.Motor, v.Status,
+		emptyToNull(v.VIN), emptyToNull(v.LicensePlate), v.Mileage, v.Colour, v.Transmission,
+		v.Doors, v.Seats, v.AskingPrice, v.PurchasePrice, v.Supplier, v.AcquisitionDate, v.PreviousOwnerID, v.UpdatedAt, v.BrandID, v.ModelID,
+		v.EngineCC, v.LicenceCategory, v.PayloadKg, v.Axles, v.LocationID,
+		v.FuelType, v.PowerKW, v.PowerHP, v.CO2, v.EmissionStandard, v.BatteryKWh).Scan(&v.ID)
+	if err != nil {
+		return s.vehicleWriteError(err)
+	}
+
+	return insertStatusChange(t, &models.VehicleStatusChange{
+		VehicleID: int64(v.ID),
+		To:        v.Status,
+		Reason:    reason,
+		Actor:     models.SystemActor,
+	})
+}
+
+func (r *vehicleRepository) GetAll(ctx context.Context) ([]models.Vehicle, error) {
+	page, err := r.Find(ctx, models.VehicleQuery{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+func (r *vehicleRepository) GetByID(ctx context.Context, id int64) (*models.Vehicle, error) {
+	query := "SELECT " + vehicleColumns + " FROM vehicles WHERE id=$1"
+
+	var vehicle models.Vehicle
+	err := scanVehicle(r.queryRow(ctx, query, id), &vehicle)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return &vehicle, nil
+}
+
+func (r *vehicleRepository) FindByIdentity(ctx context.Context, vins, plates []string) ([]models.Vehicle, error) {
+	c := &conditions{}
+	c.in("vin", values(vins))
+	c.in("license_plate", values(plates))
+	vehicles := []models.Vehicle{}
+	if len(c.clauses) == 0 {
+		return vehicles, nil
+	}
+
+	query := "SELECT " + vehicleColumns + " FROM vehicles WHERE " + strings.Join(c.clauses, " OR ") + " ORDER BY id"
+	rows, err := r.query(ctx, query, c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vehicle models.Vehicle
+		if err := scanVehicle(rows, &vehicle); err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, vehicle)
+	}
+	return vehicles, rows.Err()
+}
+
+func (r *vehicleRepository) Update(ctx context.Context, vehicle *models.Vehicle) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var oldPrice float64
+		err := t.queryRow("SELECT asking_price, location_id FROM vehicles WHERE id = $1"+r.dialect.ForUpdate(), vehicle.ID).
+			Scan(&oldPrice, nullInt64{&vehicle.LocationID})
+		if err != nil {
+			return notFound(err)
+		}
+
+		if vehicle.PurchasePrice != 0 {
+			var consignmentID int64
+			err := t.queryRow("SELECT id FROM consignments WHERE vehicle_id = $1 AND status IN ($2, $3)",
+				vehicle.ID, models.ConsignmentActive, models.ConsignmentSold).Scan(&consignmentID)
+			if err == nil {
+				return &models.ConsignedPurchasePriceError{VehicleID: int64(vehicle.ID)}
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+
+		catalog, err := r.loadCatalog(t)
+		if err != nil {
+			return err
+		}
+		if err := catalog.Resolve(vehicle); err != nil {
+			return err
+		}
+
+		query := `UPDATE vehicles 
+		SET type=$1, brand=$2, model=$3, year=$4, motor=$5,
+			vin=$6, license_plate=$7, mileage=$8, colour=$9, transmission=$10, doors=$11, seats=$12, asking_price=$13,
+			purchase_price=$14, supplier=$15, acquisition_date=$16, previous_owner_id=$17, updated_at=$18,
+			brand_id=$19, model_id=$20, engine_cc=$21, licence_category=$22, payload_kg=$23, axles=$24,
+			fuel_type=$25, power_kw=$26, power_hp=$27, co2=$28, emission_standard=$29, battery_kwh=$30
+		WHERE id=$31
+		`
+		vehicle.UpdatedAt = updateTime()
+		_, err = t.exec(query, vehicle.Type, vehicle.Brand, vehicle.Model, vehicle.Year, vehicle.Motor,
+			emptyToNull(vehicle.VIN), emptyToNull(vehicle.LicensePlate), vehicle.Mileage, vehicle.Colour,
+			vehicle.Transmission, vehicle.Doors, vehicle.Seats, vehicle.AskingPrice,
+			vehicle.PurchasePrice, vehicle.Supplier, vehicle.AcquisitionDate, vehicle.PreviousOwnerID, vehicle.UpdatedAt,
+			vehicle.BrandID, vehicle.ModelID, vehicle.EngineCC, vehicle.LicenceCategory, vehicle.PayloadKg, vehicle.Axles,
+			vehicle.FuelType, vehicle.PowerKW, vehicle.PowerHP, vehicle.CO2, vehicle.EmissionStandard, vehicle.BatteryKWh,
+			vehicle.ID)
+		if err != nil {
+			return r.vehicleWriteError(err)
+		}
+
+		if vehicle.AskingPrice == oldPrice {
+			return nil
+		}
+		return insertPriceChange(t, &models.PriceChange{
+			VehicleID: int64(vehicle.ID),
+			OldPrice:  oldPrice,
+			NewPrice:  vehicle.AskingPrice,
+			Reason:    models.ManualPriceReason,
+		})
+	})
+}
+
+func (r *vehicleRepository) Delete(ctx context.Context, id int64) error {
+	return r.deleteByID(ctx, "vehicles", id)
+}
+
+func (r *vehicleRepository) Transition(ctx context.Context, change *models.VehicleStatusChange) error {
+	return r.inTx(ctx, func(t *tx) error {
+		var from models.VehicleStatus
+		err := t.queryRow("SELECT status FROM vehicles WHERE id = $1"+r.dialect.ForUpdate(), change.VehicleID).Scan(&from)
+		if err != nil {
+			return notFound(err)
+		}
+
+		// A reserved vehicle is released by cancelling its reservation.
+		if from == models.StatusReserved {
+			reservation, err := activeReservation(t, change.VehicleID)
+			if err != nil {
+				return err
+			}
+			if reservation != nil {
+				return &models.VehicleReservedError{VehicleID: change.VehicleID, ReservationID: reservation.ID, ExpiresAt: reservation.ExpiresAt}
+			}
+		}
+
+		return transition(t, from, change)
+	})
+}
+
+func (r *vehicleRepository) StatusHistory(ctx context.Context, vehicleID int64) ([]models.VehicleStatusChange, error) {
+	var exists int64
+	err := r.queryRow(ctx, "SELECT id FROM vehicles WHERE id = $1", vehicleID).Scan(&exists)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	query := `
+	SELECT id, vehicle_id, from_status, to_status, reason, actor, changed_at
+	FROM vehicle_status_history
+	WHERE vehicle_id = $1
+	ORDER BY changed_at, id`
+
+	rows, err := r.query(ctx, query, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.VehicleStatusChange
+	for rows.Next() {
+		var change models.VehicleStatusChange
+		var from sql.NullString
+		err := rows.Scan(&change.ID, &change.VehicleID, &from, &change.To, &change.Reason, &change.Actor, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		if from.Valid {
+			status := models.VehicleStatus(from.String)
+			change.From = &status
+		}
+		history = append(history, change)
+	}
+
+	return history, rows.Err()
+}
+
+// transition checks and applies a status change for a vehicle already locked
+// by the caller's transaction.
+func transition(t *tx, from models.VehicleStatus, change *models.VehicleStatusChange) error {
+	if err := models.CheckTransition(from, change.To); err != nil {
+		return err
+	}
+
+	_, err := t.exec("UPDATE vehicles SET status = $1, updated_at = $2 WHERE id = $3", change.To, updateTime(), change.VehicleID)
+	if err != nil {
+		return err
+	}
+
+	change.From = &from
+	return insertStatusChange(t, change)
+}
+
+// updateTime is the value stored in updated_at. It is kept in UTC because
+// SQLite compares timestamps as text.
+func updateTime() time.Time {
+	return time.Now().UTC()
+}
+
+func insertStatusChange(t *tx, change *models.VehicleStatusChange) error {
+	change.ChangedAt = time.Now()
+
+	query := `
+	INSERT INTO vehicle_status_history (vehicle_id, from_status, to_status, reason, actor, changed_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	return t.queryRow(query, change.VehicleID, change.From, change.To, change.Reason, change.Actor, change.ChangedAt).Scan(&change.ID)
+}
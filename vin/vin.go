@@ -0,0 +1,155 @@
+package vin
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Stand/models"
+)
+
+//go:embed table.json
+var bundledTable []byte
+
+// Table maps world manufacturer identifiers (the first three VIN characters)
+// to brands and, per WMI, plant codes (VIN position 11) to plant names.
+type Table struct {
+	Manufacturers map[string]string            `json:"manufacturers"`
+	Plants        map[string]map[string]string `json:"plants"`
+}
+
+// Decoder decodes VINs offline using a lookup Table.
+type Decoder struct {
+	table Table
+}
+
+// Decoding is what could be read from a VIN, plus any disagreement with the
+// brand and year submitted for the vehicle.
+type Decoding struct {
+	VIN string `json:"vin"`
+	WMI string `json:"wmi"`
+	// CheckDigitValid tells whether position 9 holds the ISO 3779 check
+	// digit, which is only mandatory for North-American VINs.
+	CheckDigitValid bool       `json:"check_digit_valid"`
+	SuggestedBrand  string     `json:"suggested_brand,omitempty"`
+	YearCode        string     `json:"year_code"`
+	CandidateYears  []int      `json:"candidate_years,omitempty"`
+	SuggestedYear   int        `json:"suggested_year,omitempty"`
+	PlantCode       string     `json:"plant_code"`
+	Plant           string     `json:"plant,omitempty"`
+	Mismatches      []Mismatch `json:"mismatches,omitempty"`
+}
+
+type Mismatch struct {
+	Field     string `json:"field"`
+	Submitted string `json:"submitted"`
+	Decoded   string `json:"decoded"`
+}
+
+// modelYearCodes is the 30-character cycle of VIN position 10, starting at 1980.
+const modelYearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// New returns a decoder for the bundled table, extended and overridden by
+// the entries of the JSON file at path if path is not empty.
+func New(path string) (*Decoder, error) {
+	var table Table
+	if err := json.Unmarshal(bundledTable, &table); err != nil {
+		return nil, fmt.Errorf("invalid bundled VIN table: %w", err)
+	}
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read VIN table: %w", err)
+		}
+
+		var extra Table
+		if err := json.Unmarshal(content, &extra); err != nil {
+			return nil, fmt.Errorf("invalid VIN table %s: %w", path, err)
+		}
+
+		for wmi, brand := range extra.Manufacturers {
+			table.Manufacturers[strings.ToUpper(wmi)] = brand
+		}
+		for wmi, plants := range extra.Plants {
+			wmi = strings.ToUpper(wmi)
+			if table.Plants[wmi] == nil {
+				table.Plants[wmi] = map[string]string{}
+			}
+			for code, plant := range plants {
+				table.Plants[wmi][strings.ToUpper(code)] = plant
+			}
+		}
+	}
+
+	return &Decoder{table: table}, nil
+}
+
+// Decode reads the manufacturer, model year and plant from a VIN. Only the
+// length and alphabet of the VIN are checked; whether it carries a valid
+// check digit is reported in the decoding.
+func (d *Decoder) Decode(vin string) (*Decoding, error) {
+	vin = models.NormalizeVIN(vin)
+	if err := models.CheckVINFormat(vin); err != nil {
+		return nil, err
+	}
+
+	decoding := &Decoding{
+		VIN:             vin,
+		WMI:             vin[0:3],
+		CheckDigitValid: models.VINCheckDigitValid(vin),
+		YearCode:        vin[9:10],
+		PlantCode:       vin[10:11],
+	}
+
+	decoding.SuggestedBrand = d.table.Manufacturers[decoding.WMI]
+	decoding.Plant = d.table.Plants[decoding.WMI][decoding.PlantCode]
+	decoding.CandidateYears = candidateYears(vin[9], time.Now().Year()+1)
+	if len(decoding.CandidateYears) > 0 {
+		decoding.SuggestedYear = decoding.CandidateYears[0]
+	}
+
+	return decoding, nil
+}
+
+// Compare flags where the brand and year submitted for a vehicle disagree
+// with the decoded values.
+func (d *Decoding) Compare(brand string, year int) {
+	d.Mismatches = nil
+
+	if brand != "" && d.SuggestedBrand != "" && !strings.EqualFold(brand, d.SuggestedBrand) {
+		d.Mismatches = append(d.Mismatches, Mismatch{Field: "Brand", Submitted: brand, Decoded: d.SuggestedBrand})
+	}
+
+	if year > 0 && len(d.CandidateYears) > 0 {
+		for _, candidate := range d.CandidateYears {
+			if candidate == year {
+				return
+			}
+		}
+		d.Mismatches = append(d.Mismatches, Mismatch{
+			Field:     "Year",
+			Submitted: fmt.Sprint(year),
+			Decoded:   fmt.Sprint(d.SuggestedYear),
+		})
+	}
+}
+
+// candidateYears returns the model years a position-10 code can stand for,
+// most recent first, ignoring years after maxYear. The code repeats every
+// 30 years, so there are usually two candidates.
+func candidateYears(code byte, maxYear int) []int {
+	index := strings.IndexByte(modelYearCodes, code)
+	if index < 0 {
+		return nil
+	}
+
+	var years []int
+	for year := 1980 + index; year <= maxYear; year += 30 {
+		years = append([]int{year}, years...)
+	}
+	return years
+}